@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/checkers"
+	"github.com/spf13/cobra"
+)
+
+var checkerCmd = &cobra.Command{
+	Use:   "checker",
+	Short: "File-checker commands",
+}
+
+var checkerCacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the file-checker result cache",
+	Long: `The file-checker hook caches checker results in the session directory,
+keyed by the file's content, the state of the rest of its git repository,
+the checker's tools and their config files. A file that is checked again
+while nothing in the project changed is answered from the cache.`,
+}
+
+var checkerCacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the cache's hit rate and size for the current session",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stats, err := sessionCheckerCache().Stats()
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if jsonOutput {
+			return json.NewEncoder(out).Encode(stats)
+		}
+		fmt.Fprintf(out, "Lookups:  %d (%d hits, %d misses)\n", stats.Hits+stats.Misses, stats.Hits, stats.Misses)
+		fmt.Fprintf(out, "Hit rate: %.0f%%\n", 100*stats.HitRate())
+		fmt.Fprintf(out, "Entries:  %d (%.1f KB)\n", stats.Entries, float64(stats.Size)/1024)
+		return nil
+	},
+}
+
+var checkerCacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove the cached results and stats for the current session",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := sessionCheckerCache().Clear(); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Checker cache cleared")
+		return nil
+	},
+}
+
+func init() {
+	checkerCacheCmd.AddCommand(checkerCacheStatsCmd)
+	checkerCacheCmd.AddCommand(checkerCacheClearCmd)
+	checkerCmd.AddCommand(checkerCacheCmd)
+	rootCmd.AddCommand(checkerCmd)
+}
+
+// sessionCheckerCache returns the checker cache of the session in
+// ICC_SESSION_ID, the one the hooks use.
+func sessionCheckerCache() *checkers.Cache {
+	sessionID := os.Getenv(config.EnvPrefix + "_SESSION_ID")
+	if sessionID == "" {
+		sessionID = "default"
+	}
+	return checkers.NewCache(checkers.CacheDir(config.SessionDir(sessionID)))
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/checkers"
+)
+
+func TestCheckerCacheStatsClear(t *testing.T) {
+	t.Cleanup(func() { jsonOutput = false })
+	t.Setenv(config.EnvPrefix+"_HOME", t.TempDir())
+	t.Setenv(config.EnvPrefix+"_SESSION_ID", "s1")
+
+	dir := checkers.CacheDir(config.SessionDir("s1"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "stats.json"), []byte(`{"hits":3,"misses":1}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "0123abcd.json"), []byte(`{"fixed":false}`), 0o644)
+
+	out, err := executeCommand("checker", "cache", "stats")
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if !strings.Contains(out, "Hit rate: 75%") || !strings.Contains(out, "Entries:  1") {
+		t.Errorf("stats output = %q", out)
+	}
+
+	out, err = executeCommand("checker", "cache", "stats", "--json")
+	if err != nil {
+		t.Fatalf("stats --json: %v", err)
+	}
+	var stats checkers.CacheStats
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out, err)
+	}
+	if stats.Hits != 3 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if _, err := executeCommand("checker", "cache", "clear"); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("cache dir still exists after clear: %v", err)
+	}
+}
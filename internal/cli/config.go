@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configSetGlobal bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and edit configuration",
+	Long: `Configuration is layered, lowest precedence first: built-in defaults,
+the global config file (~/.icc/config.yaml), the nearest project config file
+(.icc.yaml in the current directory or an ancestor), and ICC_* environment
+variables.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		values, err := configValues(cfg)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if jsonOutput {
+			sources := cfg.Sources
+			if sources == nil {
+				sources = []string{}
+			}
+			warnings := cfg.Warnings
+			if warnings == nil {
+				warnings = []string{}
+			}
+			return json.NewEncoder(out).Encode(map[string]any{"config": values, "sources": sources, "warnings": warnings})
+		}
+
+		fmt.Fprintln(out, "# Sources: defaults")
+		for _, path := range cfg.Sources {
+			fmt.Fprintf(out, "#   %s\n", path)
+		}
+		for _, w := range cfg.Warnings {
+			fmt.Fprintf(out, "# Warning: %s\n", w)
+		}
+		data, err := marshalYAML(redactConfig(cfg))
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print one effective setting, e.g. search.fusion",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		values, err := configValues(cfg)
+		if err != nil {
+			return err
+		}
+
+		var v any = values
+		for _, part := range strings.Split(args[0], ".") {
+			m, ok := v.(map[string]any)
+			if !ok {
+				return fmt.Errorf("unknown config key %q", args[0])
+			}
+			if v, ok = m[part]; !ok {
+				return fmt.Errorf("unknown config key %q", args[0])
+			}
+		}
+
+		out := cmd.OutOrStdout()
+		if jsonOutput {
+			return json.NewEncoder(out).Encode(v)
+		}
+		switch v.(type) {
+		case map[string]any, []any:
+			data, err := marshalYAML(v)
+			if err != nil {
+				return err
+			}
+			_, err = out.Write(data)
+			return err
+		default:
+			fmt.Fprintln(out, v)
+			return nil
+		}
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Write a setting to the project or global config file",
+	Long: `Write a setting to the nearest project config file (created in the
+current directory if there is none), or to the global config file with
+--global. The value is parsed as YAML, so lists and maps can be given as
+e.g. "[40, 60, 80]". Comments in the file are preserved.
+
+Settings a project file can't change, such as the embedding API key and
+notification sinks, need --global. The global file is only readable by
+you, since it may hold secrets.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !configSetGlobal && !config.ProjectKey(args[0]) {
+			return fmt.Errorf("%s can only be set in the global config file; use --global", args[0])
+		}
+		path, err := configSetPath()
+		if err != nil {
+			return err
+		}
+		perm := os.FileMode(0o644)
+		if configSetGlobal {
+			perm = 0o600
+		}
+		if err := setConfigValue(path, args[0], args[1], perm); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Set %s in %s\n", args[0], path)
+		return nil
+	},
+}
+
+func init() {
+	configSetCmd.Flags().BoolVar(&configSetGlobal, "global", false, "write to the global config file")
+
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// redactConfig returns a copy of cfg with secrets redacted: the embedding
+// API key, and the headers and URLs of notify sinks, which often carry
+// webhook tokens.
+func redactConfig(cfg *config.Config) *config.Config {
+	c := *cfg
+	if c.Embedding.APIKey != "" {
+		c.Embedding.APIKey = config.Redacted
+	}
+	c.Notify.Sinks = slices.Clone(c.Notify.Sinks)
+	for i, sink := range c.Notify.Sinks {
+		if sink.Headers != nil {
+			headers := make(map[string]string, len(sink.Headers))
+			for name := range sink.Headers {
+				headers[name] = config.Redacted
+			}
+			c.Notify.Sinks[i].Headers = headers
+		}
+		c.Notify.Sinks[i].URL = config.RedactURL(sink.URL)
+	}
+	return &c
+}
+
+// configValues converts a config to generic YAML values, with durations as
+// strings and secrets redacted.
+func configValues(cfg *config.Config) (map[string]any, error) {
+	data, err := yaml.Marshal(redactConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// marshalYAML encodes v with two-space indentation.
+func marshalYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// configSetPath returns the config file that "config set" writes to.
+func configSetPath() (string, error) {
+	if configSetGlobal {
+		return config.GlobalConfigPath(), nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("get working directory: %w", err)
+	}
+	if path := config.FindProjectConfig(cwd); path != "" {
+		return path, nil
+	}
+	return filepath.Join(cwd, config.ProjectConfigName), nil
+}
+
+// setConfigValue sets a dotted key in the YAML file at path, creating the
+// file and intermediate mappings as needed. The file is only written if the
+// result is a valid config. An existing file's permissions are narrowed to
+// perm.
+func setConfigValue(path, key, value string, perm os.FileMode) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	var val yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &val); err != nil {
+		return fmt.Errorf("parse value: %w", err)
+	}
+	valNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if val.Kind == yaml.DocumentNode && len(val.Content) == 1 {
+		valNode = val.Content[0]
+	}
+
+	node := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+			// An empty section, e.g. "search:" with nothing below it
+			*node = yaml.Node{Kind: yaml.MappingNode}
+		}
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("cannot set %q: %s is not a mapping", key, strings.Join(parts[:i], "."))
+		}
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == part {
+				next = node.Content[j+1]
+				if i == len(parts)-1 {
+					node.Content[j+1] = valNode
+				}
+				break
+			}
+		}
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode}
+			if i == len(parts)-1 {
+				next = valNode
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, next)
+		}
+		node = next
+	}
+
+	out, err := marshalYAML(&doc)
+	if err != nil {
+		return err
+	}
+	if err := config.ValidateYAML(out); err != nil {
+		return fmt.Errorf("cannot set %s: %w", key, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	if err := os.WriteFile(path, out, perm); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", path, err)
+	}
+	if mode := info.Mode().Perm(); mode&^perm != 0 {
+		if err := os.Chmod(path, mode&perm); err != nil {
+			return fmt.Errorf("chmod %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+)
+
+// inConfigProject points ICC_HOME and the working directory at temp dirs.
+func inConfigProject(t *testing.T) string {
+	t.Helper()
+	t.Setenv(config.EnvPrefix+"_HOME", t.TempDir())
+	t.Setenv(config.EnvPrefix+"_PORT", "")
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		configSetGlobal = false
+		jsonOutput = false
+	})
+	return dir
+}
+
+func TestConfigSetGet(t *testing.T) {
+	dir := inConfigProject(t)
+	path := filepath.Join(dir, config.ProjectConfigName)
+	if err := os.WriteFile(path, []byte("# ranking tweaks\nsearch:\n  rrf_k: 30\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := executeCommand("config", "set", "search.fusion", "weighted"); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if _, err := executeCommand("config", "set", "context.thresholds", "[50, 70, 85]"); err != nil {
+		t.Fatalf("config set list: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# ranking tweaks") {
+		t.Errorf("comment not preserved:\n%s", data)
+	}
+
+	out, err := executeCommand("config", "get", "search.fusion")
+	if err != nil {
+		t.Fatalf("config get: %v", err)
+	}
+	if strings.TrimSpace(out) != "weighted" {
+		t.Errorf("search.fusion = %q, want weighted", out)
+	}
+	out, err = executeCommand("config", "get", "search.rrf_k")
+	if err != nil {
+		t.Fatalf("config get: %v", err)
+	}
+	if strings.TrimSpace(out) != "30" {
+		t.Errorf("search.rrf_k = %q, want 30", out)
+	}
+
+	if _, err := executeCommand("config", "get", "search.nope"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
+
+func TestConfigSetRejectsInvalid(t *testing.T) {
+	dir := inConfigProject(t)
+
+	for _, args := range [][]string{
+		{"search.fusion", "max"},
+		{"search.nope", "1"},
+		{"context.handoff", "soon"},
+	} {
+		if _, err := executeCommand(append([]string{"config", "set"}, args...)...); err == nil {
+			t.Errorf("config set %v: expected error", args)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, config.ProjectConfigName)); !os.IsNotExist(err) {
+		t.Error("invalid values should not create the config file")
+	}
+}
+
+func TestConfigSetGlobalOnly(t *testing.T) {
+	dir := inConfigProject(t)
+
+	// Secrets and other global settings are refused for the project file
+	for _, args := range [][]string{
+		{"embedding.api_key", "sk-secret"},
+		{"notify.sinks", "[{type: webhook, url: 'https://hooks.example/T0/secret'}]"},
+		{"port", "9999"},
+	} {
+		if _, err := executeCommand(append([]string{"config", "set"}, args...)...); err == nil || !strings.Contains(err.Error(), "--global") {
+			t.Errorf("config set %v: error = %v, want one about --global", args, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, config.ProjectConfigName)); !os.IsNotExist(err) {
+		t.Error("refused settings should not create the project config file")
+	}
+
+	// The global file is only readable by its owner, even if it wasn't before
+	path := config.GlobalConfigPath()
+	if err := os.WriteFile(path, []byte("log_level: info\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeCommand("config", "set", "--global", "embedding.api_key", "sk-secret"); err != nil {
+		t.Fatalf("config set --global: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("global config mode = %o, want 600", perm)
+	}
+}
+
+func TestConfigShowGlobal(t *testing.T) {
+	inConfigProject(t)
+
+	if _, err := executeCommand("config", "set", "--global", "embedding.api_key", "sk-secret"); err != nil {
+		t.Fatalf("config set --global: %v", err)
+	}
+
+	out, err := executeCommand("config", "show", "--json")
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if strings.Contains(out, "sk-secret") {
+		t.Error("api key should be redacted")
+	}
+	var got struct {
+		Config  map[string]any `json:"config"`
+		Sources []string       `json:"sources"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(got.Sources) != 1 || got.Sources[0] != config.GlobalConfigPath() {
+		t.Errorf("sources = %v, want global config file", got.Sources)
+	}
+	if got.Config["port"] != float64(config.DefaultPort) {
+		t.Errorf("port = %v, want %d", got.Config["port"], config.DefaultPort)
+	}
+}
+
+func TestConfigShowRedactsNotifySinks(t *testing.T) {
+	inConfigProject(t)
+	cfg := `notify:
+  sinks:
+    - type: webhook
+      url: https://hooks.slack.com/services/T000/B000/XXXXSECRET?token=abc
+      headers:
+        Authorization: Bearer tok-secret
+`
+	if err := os.WriteFile(config.GlobalConfigPath(), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"config", "show"}, {"config", "get", "notify.sinks"}} {
+		out, err := executeCommand(args...)
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		for _, secret := range []string{"tok-secret", "XXXXSECRET", "token=abc"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("%v output contains %q:\n%s", args, secret, out)
+			}
+		}
+		if !strings.Contains(out, "Authorization: '"+config.Redacted+"'") || !strings.Contains(out, "https://hooks.slack.com/"+config.Redacted) {
+			t.Errorf("%v output doesn't show the redacted header and URL:\n%s", args, out)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package cli
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
 	"github.com/itk-dev/itkdev-claude-code/internal/hooks"
 	"github.com/spf13/cobra"
 
@@ -8,18 +13,124 @@ import (
 	_ "github.com/itk-dev/itkdev-claude-code/internal/hooks/checkers"
 )
 
+var (
+	hookTestTool  string
+	hookTestInput string
+)
+
 var hookCmd = &cobra.Command{
 	Use:   "hook <name>",
 	Short: "Run a Claude Code hook by name",
 	Long: `Executes a specific hook. Called by Claude Code's hooks.json, not typically
 invoked directly. Available hooks: file-checker, tdd-enforcer, context-monitor,
-tool-redirect, spec-stop-guard, session-start, session-end, notify.`,
+tool-redirect, branch-guard, command-guard, secret-guard, spec-stop-guard,
+session-start, session-end, observation-capture, notify.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return hooks.Dispatch(args[0])
 	},
 }
 
+var hookTestCmd = &cobra.Command{
+	Use:   "test <name> [file]",
+	Short: "Dry-run a hook against sample inputs",
+	Long: `Evaluates a hook against sample hook inputs and prints what it would
+decide, without running any side effects. Inputs are JSON objects in Claude
+Code's hook input format (at least tool_name and tool_input), one after
+another as in a JSONL file, read from the file or stdin. Alternatively, give a
+single call with --tool and --input:
+
+  icc hook test tool-redirect --tool Bash --input '{"command": "grep -r foo"}'`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputs, err := hookTestInputs(cmd, args[1:])
+		if err != nil {
+			return err
+		}
+
+		type result struct {
+			ToolName  string          `json:"tool_name"`
+			ToolInput json.RawMessage `json:"tool_input,omitempty"`
+			Decision  *hooks.Decision `json:"decision"`
+		}
+		var results []result
+		for _, in := range inputs {
+			d, err := hooks.DryRun(args[0], in)
+			if err != nil {
+				return err
+			}
+			results = append(results, result{in.ToolName, in.ToolInput, d})
+		}
+
+		out := cmd.OutOrStdout()
+		if jsonOutput {
+			return json.NewEncoder(out).Encode(results)
+		}
+		for _, r := range results {
+			if len(r.ToolInput) == 0 || string(r.ToolInput) == "null" {
+				fmt.Fprintln(out, r.ToolName)
+			} else {
+				fmt.Fprintf(out, "%s %s\n", r.ToolName, r.ToolInput)
+			}
+			if r.Decision == nil {
+				fmt.Fprintln(out, "  → proceed (no rule matched)")
+				continue
+			}
+			fmt.Fprintf(out, "  → %s", r.Decision.Action)
+			if r.Decision.Rule != "" {
+				fmt.Fprintf(out, " [%s]", r.Decision.Rule)
+			}
+			if r.Decision.Reason != "" {
+				fmt.Fprintf(out, ": %s", r.Decision.Reason)
+			}
+			fmt.Fprintln(out)
+			if r.Decision.UpdatedInput != nil {
+				updated, _ := json.Marshal(r.Decision.UpdatedInput)
+				fmt.Fprintf(out, "    input: %s\n", updated)
+			}
+		}
+		return nil
+	},
+}
+
 func init() {
+	hookTestCmd.Flags().StringVar(&hookTestTool, "tool", "", "tool name of a single sample call")
+	hookTestCmd.Flags().StringVar(&hookTestInput, "input", "{}", "tool input of a single sample call, as JSON")
+
+	hookCmd.AddCommand(hookTestCmd)
 	rootCmd.AddCommand(hookCmd)
 }
+
+// hookTestInputs returns the sample inputs from --tool/--input, a file, or
+// stdin.
+func hookTestInputs(cmd *cobra.Command, args []string) ([]*hooks.Input, error) {
+	if hookTestTool != "" {
+		if !json.Valid([]byte(hookTestInput)) {
+			return nil, fmt.Errorf("--input is not valid JSON")
+		}
+		return []*hooks.Input{{
+			HookEventName: "PreToolUse",
+			ToolName:      hookTestTool,
+			ToolInput:     json.RawMessage(hookTestInput),
+		}}, nil
+	}
+
+	var data []byte
+	var err error
+	if len(args) == 1 && args[0] != "-" {
+		data, err = os.ReadFile(args[0])
+	} else {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read inputs: %w", err)
+	}
+	inputs, err := hooks.ParseInputs(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no inputs given")
+	}
+	return inputs, nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHookTestToolRedirect(t *testing.T) {
+	inConfigProject(t)
+	jsonOutput = false
+	t.Cleanup(func() {
+		hookTestTool = ""
+		hookTestInput = "{}"
+	})
+
+	out, err := executeCommand("hook", "test", "tool-redirect", "--tool", "WebSearch", "--input", `{"query": "go"}`)
+	if err != nil {
+		t.Fatalf("hook test: %v", err)
+	}
+	if !strings.Contains(out, "deny [mcp-web-search]") {
+		t.Errorf("expected built-in deny, got:\n%s", out)
+	}
+
+	out, err = executeCommand("hook", "test", "tool-redirect", "--tool", "Read", "--input", `{"file_path": "a.go"}`)
+	if err != nil {
+		t.Fatalf("hook test: %v", err)
+	}
+	if !strings.Contains(out, "proceed") {
+		t.Errorf("expected no match, got:\n%s", out)
+	}
+
+	if _, err := executeCommand("hook", "test", "session-end", "--tool", "Read"); err == nil {
+		t.Error("expected error for a hook without dry-run support")
+	}
+}
@@ -110,6 +110,7 @@ func buildInfo() InfoData {
 			{"spec-plan-validator", "Validate plan file structure"},
 			{"spec-verify-validator", "Validate verification results"},
 			{"task-tracker", "Track task creation and updates"},
+			{"observation-capture", "Record edits, commits, and test runs as observations"},
 		},
 		MCPTools: []InfoEntry{
 			{"search", "Semantic search across observations"},
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/db"
+	"github.com/itk-dev/itkdev-claude-code/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	memoryExportOutput  string
+	memoryExportProject string
+	memoryExportSince   string
+	memoryExportUntil   string
+)
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Export and import persistent memory",
+}
+
+var memoryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export sessions, observations, summaries and plans as JSONL",
+	Long: `Export memory from the local database as JSON Lines. Observations,
+summaries and plans can be limited to a project and an inclusive date range;
+the sessions they belong to are always included. Writes to stdout unless
+--output is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := db.ExportFilter{
+			Project:   memoryExportProject,
+			DateStart: memoryExportSince,
+			DateEnd:   memoryExportUntil,
+		}
+		if err := f.Validate(); err != nil {
+			return err
+		}
+
+		database, err := openMemoryDB()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		out := cmd.OutOrStdout()
+		if memoryExportOutput != "" && memoryExportOutput != "-" {
+			file, err := os.Create(memoryExportOutput)
+			if err != nil {
+				return fmt.Errorf("create %s: %w", memoryExportOutput, err)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		stats, err := database.Export(out, f)
+		if err != nil {
+			return fmt.Errorf("export memory: %w", err)
+		}
+
+		// Keep stdout clean for the export itself
+		fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d sessions, %d observations, %d summaries, %d plans\n",
+			stats.Sessions, stats.Observations, stats.Summaries, stats.Plans)
+		return nil
+	},
+}
+
+var memoryImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import a JSONL memory export",
+	Long: `Import a memory export into the local database. Reads stdin when no
+file (or "-") is given. Records get new IDs, and records that already exist
+are skipped, so re-importing the same file is safe.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var in io.Reader = cmd.InOrStdin()
+		if len(args) == 1 && args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("open %s: %w", args[0], err)
+			}
+			defer file.Close()
+			in = file
+		}
+
+		database, err := openMemoryDB()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		stats, err := database.Import(in)
+		if err != nil {
+			return fmt.Errorf("import memory: %w", err)
+		}
+		refreshConsoleIndex(stats)
+
+		if jsonOutput {
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(stats)
+		}
+		for _, row := range []struct {
+			name   string
+			counts db.ImportCounts
+		}{
+			{"sessions", stats.Sessions},
+			{"observations", stats.Observations},
+			{"summaries", stats.Summaries},
+			{"plans", stats.Plans},
+		} {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-13s %d imported, %d skipped\n", row.name, row.counts.Imported, row.counts.Skipped)
+		}
+		return nil
+	},
+}
+
+func init() {
+	memoryExportCmd.Flags().StringVarP(&memoryExportOutput, "output", "o", "", "write to file instead of stdout")
+	memoryExportCmd.Flags().StringVar(&memoryExportProject, "project", "", "only export this project")
+	memoryExportCmd.Flags().StringVar(&memoryExportSince, "since", "", "only export records created on or after this date (YYYY-MM-DD)")
+	memoryExportCmd.Flags().StringVar(&memoryExportUntil, "until", "", "only export records created on or before this date (YYYY-MM-DD)")
+
+	memoryCmd.AddCommand(memoryExportCmd)
+	memoryCmd.AddCommand(memoryImportCmd)
+	rootCmd.AddCommand(memoryCmd)
+}
+
+// openMemoryDB opens the local database directly, so export and import work
+// whether or not the console server is running.
+func openMemoryDB() (*db.DB, error) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	return db.Open(config.DBPath(), logger)
+}
+
+// refreshConsoleIndex asks a running console server to rebuild its search
+// index so imported observations are searchable right away. Best effort: a
+// stopped server indexes them on its next start.
+func refreshConsoleIndex(stats *db.ImportStats) {
+	if stats.Observations.Imported == 0 {
+		return
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	resp, err := session.DefaultConsoleClient(cfg.Port).Post("/api/search/reindex", nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/db"
+)
+
+func TestMemoryExportImport(t *testing.T) {
+	t.Cleanup(func() {
+		jsonOutput = false
+		memoryExportOutput = ""
+		memoryExportProject = ""
+	})
+	t.Setenv("ICC_PORT", "1") // no console server to refresh
+
+	// Seed a source database
+	t.Setenv("ICC_HOME", t.TempDir())
+	src, err := openMemoryDB()
+	if err != nil {
+		t.Fatalf("openMemoryDB: %v", err)
+	}
+	src.InsertSession(&db.Session{ID: "s1", Project: "alpha"})
+	src.InsertObservation(&db.Observation{SessionID: "s1", Title: "a", Text: "alpha note", Project: "alpha"})
+	src.InsertObservation(&db.Observation{SessionID: "s2", Title: "b", Text: "beta note", Project: "beta"})
+	src.Close()
+
+	file := filepath.Join(t.TempDir(), "memory.jsonl")
+	out, err := executeCommand("memory", "export", "--project", "alpha", "-o", file)
+	if err != nil {
+		t.Fatalf("export: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Exported 1 sessions, 1 observations") {
+		t.Errorf("export output = %q", out)
+	}
+
+	// Import into a fresh database
+	t.Setenv("ICC_HOME", t.TempDir())
+	out, err = executeCommand("memory", "import", file, "--json")
+	if err != nil {
+		t.Fatalf("import: %v\n%s", err, out)
+	}
+	var stats db.ImportStats
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out, err)
+	}
+	if stats.Sessions.Imported != 1 || stats.Observations.Imported != 1 {
+		t.Errorf("import stats = %+v", stats)
+	}
+
+	dst, err := openMemoryDB()
+	if err != nil {
+		t.Fatalf("openMemoryDB: %v", err)
+	}
+	defer dst.Close()
+	if results, _ := dst.SearchObservations("alpha", 10); len(results) != 1 {
+		t.Errorf("imported database has %d alpha observations, want 1", len(results))
+	}
+}
+
+func TestMemoryExportInvalidDate(t *testing.T) {
+	t.Cleanup(func() { memoryExportSince = "" })
+	t.Setenv("ICC_HOME", t.TempDir())
+	if _, err := executeCommand("memory", "export", "--since", "last week"); err == nil {
+		t.Error("expected error for invalid date")
+	}
+	if _, err := os.Stat(filepath.Join(os.Getenv("ICC_HOME"), "db")); err == nil {
+		t.Error("invalid export should not create a database")
+	}
+}
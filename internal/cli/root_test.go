@@ -38,7 +38,7 @@ func TestSubcommandsExist(t *testing.T) {
 	commands := []string{
 		"run", "serve", "install", "hook", "session",
 		"worktree", "check-context", "send-clear",
-		"register-plan", "greet", "statusline",
+		"register-plan", "greet", "statusline", "memory", "config",
 	}
 	for _, name := range commands {
 		t.Run(name, func(t *testing.T) {
@@ -8,9 +8,12 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/itk-dev/itkdev-claude-code/internal/config"
 	"github.com/itk-dev/itkdev-claude-code/internal/console"
@@ -29,7 +32,12 @@ var runCmd = &cobra.Command{
 	Long: `Starts the console server, generates a session ID, and launches
 Claude Code with the appropriate environment variables and hooks.
 Signals are forwarded to Claude Code. The console server runs as a
-background goroutine for the lifetime of the session.`,
+background goroutine for the lifetime of the session.
+
+When a session writes an Endless Mode clear signal (icc send-clear), Claude
+Code is stopped and relaunched in a new session linked to the previous one,
+starting from the continuation prompt. The console server keeps running
+across restarts.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.Load()
 		if err != nil {
@@ -81,7 +89,7 @@ background goroutine for the lifetime of the session.`,
 		logger.Debug("starting session", "id", sessionID)
 
 		// Start console server as goroutine
-		srv, err := console.New(cfg.Port, logger)
+		srv, err := console.New(cfg, logger)
 		if err != nil {
 			return fmt.Errorf("create console server: %w", err)
 		}
@@ -106,52 +114,61 @@ background goroutine for the lifetime of the session.`,
 		// Update config files with actual port so Claude Code sees the right URL
 		updatePortInConfigs(actualPort, logger)
 
-		// Write PID file for session tracking
-		session.WritePIDFile(sessionDir)
-		defer session.RemovePIDFile(sessionDir)
+		// Forward signals to whichever Claude Code process is current
+		claude := &claudeProcess{}
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		go claude.forwardSignals(sigCh)
 
-		// Register session with console
 		client := session.DefaultConsoleClient(actualPort)
-		if resp, err := client.Post("/api/sessions", map[string]string{
-			"id":      sessionID,
-			"project": detectProject(),
-		}); err == nil && resp != nil {
-			resp.Body.Close()
-		}
-
-		// Build environment for Claude Code
-		env := session.BuildEnv(sessionID, actualPort, issueFlag, reviewFlag)
-
-		// Launch Claude Code
-		claudeArgs := session.BuildClaudeArgs()
-		claudeArgs = append(claudeArgs, args...)
+		project := detectProject()
+		registerSession(client, sessionID, "", project)
+
+		baseArgs := append(session.BuildClaudeArgs(), args...)
+		claudeArgs := baseArgs
+
+		// Endless Mode loop: relaunch Claude Code in a linked child session
+		// for as long as each session ends with a clear signal.
+		var exitErr error
+		for {
+			session.WritePIDFile(sessionDir)
+
+			env := session.BuildEnv(sessionID, actualPort, issueFlag, reviewFlag)
+			exitErr, err = claude.run(claudePath, claudeArgs, env, sessionDir)
+			session.RemovePIDFile(sessionDir)
+			if err != nil {
+				endSession(client, sessionID)
+				srv.Stop()
+				return fmt.Errorf("start claude code: %w", err)
+			}
 
-		claudeCmd := exec.Command(claudePath, claudeArgs...)
-		claudeCmd.Env = env
-		claudeCmd.Stdin = os.Stdin
-		claudeCmd.Stdout = os.Stdout
-		claudeCmd.Stderr = os.Stderr
+			// End session in console
+			endSession(client, sessionID)
 
-		if err := claudeCmd.Start(); err != nil {
-			srv.Stop()
-			return fmt.Errorf("start claude code: %w", err)
-		}
+			if claude.terminated() {
+				break
+			}
+			clear := session.ConsumeClearSignal(sessionDir)
+			if clear == nil {
+				break
+			}
 
-		// Forward signals to Claude Code
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		go func() {
-			for sig := range sigCh {
-				claudeCmd.Process.Signal(sig)
+			parentID, parentDir := sessionID, sessionDir
+			sessionID = session.NewID()
+			sessionDir = config.SessionDir(sessionID)
+			if err := session.EnsureSessionDir(sessionDir); err != nil {
+				srv.Stop()
+				return fmt.Errorf("create session dir: %w", err)
 			}
-		}()
+			if err := session.CarryOverContinuation(parentDir, sessionDir); err != nil {
+				logger.Warn("could not carry over continuation file", "error", err)
+			}
+			registerSession(client, sessionID, parentID, project)
 
-		// Wait for Claude Code to exit
-		exitErr := claudeCmd.Wait()
+			claudeArgs = append(slices.Clone(baseArgs), session.BuildContinuationPrompt(clear.PlanPath))
 
-		// End session in console
-		if resp, err := client.Post(fmt.Sprintf("/api/sessions/%s/end", sessionID), nil); err == nil && resp != nil {
-			resp.Body.Close()
+			fmt.Fprintf(os.Stderr, "Endless Mode: continuing in session %s\n", sessionID)
+			logger.Debug("relaunching claude code", "id", sessionID, "parent", parentID, "plan", clear.PlanPath)
 		}
 
 		// Stop console server
@@ -171,6 +188,99 @@ background goroutine for the lifetime of the session.`,
 	},
 }
 
+// clearSignalPollInterval is how often a running session is checked for an
+// Endless Mode clear signal.
+const clearSignalPollInterval = time.Second
+
+// claudeProcess tracks the currently running Claude Code process so signals
+// can be forwarded across Endless Mode restarts.
+type claudeProcess struct {
+	mu       sync.Mutex
+	proc     *os.Process
+	stopLoop bool
+}
+
+// run launches Claude Code and waits for it to exit. While it runs, the
+// session directory is watched for a clear signal; once send-clear marks it
+// ready, Claude Code is asked to exit so the caller can relaunch it. A signal
+// that is never marked ready is picked up when Claude Code exits by itself. startErr is set when
+// the process could not be started at all.
+func (c *claudeProcess) run(path string, args, env []string, sessionDir string) (exitErr, startErr error) {
+	cmd := exec.Command(path, args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.proc = cmd.Process
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	cleared := session.WatchClearSignal(sessionDir, clearSignalPollInterval, done)
+	go func() {
+		select {
+		case <-cleared:
+			cmd.Process.Signal(syscall.SIGTERM)
+		case <-done:
+		}
+	}()
+
+	exitErr = cmd.Wait()
+	close(done)
+
+	c.mu.Lock()
+	c.proc = nil
+	c.mu.Unlock()
+
+	return exitErr, nil
+}
+
+// forwardSignals relays signals to the current Claude Code process. A SIGTERM
+// also stops the Endless Mode loop so icc exits with Claude Code.
+func (c *claudeProcess) forwardSignals(sigCh <-chan os.Signal) {
+	for sig := range sigCh {
+		c.mu.Lock()
+		if sig == syscall.SIGTERM {
+			c.stopLoop = true
+		}
+		if c.proc != nil {
+			c.proc.Signal(sig)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// terminated reports whether icc itself was asked to terminate.
+func (c *claudeProcess) terminated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopLoop
+}
+
+// registerSession records a session with the console. parentID links an
+// Endless Mode continuation to the session it replaces.
+func registerSession(client *session.ConsoleClient, id, parentID, project string) {
+	if resp, err := client.Post("/api/sessions", map[string]string{
+		"id":        id,
+		"parent_id": parentID,
+		"project":   project,
+	}); err == nil && resp != nil {
+		resp.Body.Close()
+	}
+}
+
+// endSession marks a session as ended in the console.
+func endSession(client *session.ConsoleClient, id string) {
+	if resp, err := client.Post(fmt.Sprintf("/api/sessions/%s/end", id), nil); err == nil && resp != nil {
+		resp.Body.Close()
+	}
+}
+
 // detectProject tries to determine the project name from the current directory.
 func detectProject() string {
 	cwd, err := os.Getwd()
@@ -24,7 +24,8 @@ Steps:
 1. Waits for memory capture (10s)
 2. Writes clear signal to session directory
 3. Waits for session end hooks (5s)
-4. Outputs continuation prompt`,
+4. Outputs continuation prompt
+5. Marks the signal ready, so icc run restarts Claude Code`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sessionID := os.Getenv(config.EnvPrefix + "_SESSION_ID")
@@ -61,15 +62,23 @@ Steps:
 		prompt := session.BuildContinuationPrompt(planPath)
 
 		if jsonOutput {
-			return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]string{
+			if err := json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]string{
 				"status":     "clear_sent",
 				"session_id": sessionID,
 				"plan_path":  planPath,
 				"prompt":     prompt,
-			})
+			}); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintln(cmd.OutOrStdout(), prompt)
 		}
 
-		fmt.Fprintln(cmd.OutOrStdout(), prompt)
+		// Step 5: Let icc run stop Claude Code. If this doesn't happen, it
+		// still relaunches once Claude Code exits.
+		if err := session.MarkClearSignalReady(sessionDir); err != nil {
+			return fmt.Errorf("mark clear signal ready: %w", err)
+		}
 		return nil
 	},
 }
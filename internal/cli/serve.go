@@ -25,7 +25,7 @@ var serveCmd = &cobra.Command{
 			Level: cfg.LogLevel,
 		}))
 
-		srv, err := console.New(cfg.Port, logger)
+		srv, err := console.New(cfg, logger)
 		if err != nil {
 			return fmt.Errorf("creating console server: %w", err)
 		}
@@ -1,52 +1,796 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
-// Config holds runtime configuration resolved from environment variables and defaults.
+// Config holds runtime configuration. It is layered, lowest precedence first:
+// built-in defaults, the global config file (~/.icc/config.yaml), the project
+// config file (.icc.yaml in the project directory or an ancestor), and
+// environment variables.
 type Config struct {
-	Port     int
-	LogLevel slog.Level
+	Port         int             `yaml:"port"`
+	LogLevelName string          `yaml:"log_level"` // off, debug, info, warn or error
+	Context      ContextConfig   `yaml:"context"`
+	Retention    RetentionConfig `yaml:"retention"`
+	Search       SearchConfig    `yaml:"search"`
+	Embedding    EmbeddingConfig `yaml:"embedding"`
+	Checker      CheckerConfig   `yaml:"checker"`
+	Notify       NotifyConfig    `yaml:"notify"`
+	ToolRedirect ToolRedirect    `yaml:"tool_redirect"`
+	BranchPolicy BranchPolicy    `yaml:"branch_policy"`
+	CommandGuard CommandGuard    `yaml:"command_guard"`
+	SecretGuard  SecretGuard     `yaml:"secret_guard"`
+
+	// LogLevel is resolved from LogLevelName.
+	LogLevel slog.Level `yaml:"-"`
+	// Sources lists the config files that were applied, lowest precedence first.
+	Sources []string `yaml:"-"`
+	// Warnings lists settings of the project config file that were ignored.
+	Warnings []string `yaml:"-"`
+}
+
+// ContextConfig controls context-usage warnings and context injection.
+type ContextConfig struct {
+	// Thresholds are the usage percentages that trigger a warning, each
+	// shown once per session.
+	Thresholds []int `yaml:"thresholds"`
+	// Handoff is the usage percentage from which warnings block and demand
+	// an Endless Mode handoff.
+	Handoff int `yaml:"handoff"`
+	// BudgetTokens is the token budget for memory injected at session start.
+	BudgetTokens int `yaml:"budget_tokens"`
+}
+
+// RetentionConfig controls the background database cleanup.
+type RetentionConfig struct {
+	MaxAgeDays        int           `yaml:"max_age_days"`        // Delete observations older than this
+	StaleSessionHours int           `yaml:"stale_session_hours"` // End sessions older than this
+	Interval          time.Duration `yaml:"interval"`            // How often to run cleanup
+}
+
+// SearchConfig controls hybrid search ranking and index maintenance.
+type SearchConfig struct {
+	Fusion           string             `yaml:"fusion"` // rrf or weighted
+	Weights          SearchWeights      `yaml:"weights"`
+	RRFK             float64            `yaml:"rrf_k"`
+	RecencyHalfLife  time.Duration      `yaml:"recency_half_life"`
+	RecencyWeight    float64            `yaml:"recency_weight"`
+	TypeBoosts       map[string]float64 `yaml:"type_boosts"`
+	ReweightInterval time.Duration      `yaml:"reweight_interval"`
+}
+
+// SearchWeights blends FTS5 and vector search scores.
+type SearchWeights struct {
+	FTS    float64 `yaml:"fts"`
+	Vector float64 `yaml:"vector"`
+}
+
+// EmbeddingConfig selects the embedding provider used for semantic search.
+type EmbeddingConfig struct {
+	Provider string `yaml:"provider"` // "tfidf" (default) or "openai"
+	URL      string `yaml:"url"`      // Base URL of an OpenAI-compatible API, e.g. http://localhost:11434
+	Model    string `yaml:"model"`    // Embedding model name
+	APIKey   string `yaml:"api_key"`  // Bearer token, if the endpoint requires one
+}
+
+// CheckerConfig controls the file-checker hook.
+type CheckerConfig struct {
+	Timeout     time.Duration `yaml:"timeout"`      // Per-file limit for running the checkers
+	ToolTimeout time.Duration `yaml:"tool_timeout"` // Limit for each tool a checker runs
+	// ChangedOnly blocks only on problems in the lines the edit changed;
+	// other problems in the file are summarized as a count.
+	ChangedOnly bool            `yaml:"changed_only"`
+	Cache       bool            `yaml:"cache"`  // Reuse results while the file, tools and configs are unchanged
+	Custom      []CustomChecker `yaml:"custom"` // Run alongside the built-in checkers
+}
+
+// CustomChecker runs a project's own lint command on matching files.
+type CustomChecker struct {
+	Name string `yaml:"name"`
+	// Files are globs for the files to check. A glob without a slash
+	// matches file names; one with a slash matches paths relative to the
+	// project root. "**" matches any number of directories.
+	Files []string `yaml:"files"`
+	// Command is run in the project root, split into arguments like a
+	// shell would. {file}, {dir} and {root} are replaced by the file, its
+	// directory and the project root.
+	Command string `yaml:"command"`
+	Fix     bool   `yaml:"fix"`    // The command fixes the file in place, so it runs before linters
+	Format  string `yaml:"format"` // Output format: regex, sarif, checkstyle or json
+	// Pattern parses regex output, one match per line, with the named
+	// groups file, line, column, message, rule and severity. Only message
+	// is required.
+	Pattern string `yaml:"pattern"`
+	// Severity of problems whose output doesn't give one: error or warning.
+	Severity string `yaml:"severity"`
+}
+
+// CheckerFormats are the output formats a custom checker can parse.
+var CheckerFormats = []string{"regex", "sarif", "checkstyle", "json"}
+
+// NotifyConfig controls the notify hook.
+type NotifyConfig struct {
+	// Events lists the events that send notifications: stop, notification
+	// and handoff.
+	Events []string     `yaml:"events"`
+	Sinks  []NotifySink `yaml:"sinks"`
+}
+
+// NotifySink configures one notification backend.
+type NotifySink struct {
+	Type    string            `yaml:"type"`    // desktop, terminal, webhook or file
+	Events  []string          `yaml:"events"`  // Further restricts the events sent to this sink
+	URL     string            `yaml:"url"`     // webhook: endpoint to POST to
+	Headers map[string]string `yaml:"headers"` // webhook: extra request headers
+	Path    string            `yaml:"path"`    // file: JSONL file to append to; terminal: device, default /dev/tty
+}
+
+// Redacted replaces secrets in output.
+const Redacted = "********"
+
+// RedactURL keeps the scheme and host of a URL and redacts the rest. Slack,
+// ntfy and similar services put the secret in the path or query.
+func RedactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return Redacted
+	}
+	if u.User == nil && strings.Trim(u.Path, "/") == "" && u.RawQuery == "" && u.Fragment == "" {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host + "/" + Redacted
+}
+
+// ToolRedirect holds the rules of the tool-redirect hook. The first rule that
+// matches a tool call decides it; the built-in rules come last.
+type ToolRedirect struct {
+	Builtin bool           `yaml:"builtin"` // Apply the built-in rules after the configured ones
+	Rules   []RedirectRule `yaml:"rules"`
 }
 
-// Load reads configuration from environment variables, falling back to defaults.
+// RedirectRule matches tool calls by tool name and tool input.
+type RedirectRule struct {
+	Name    string              `yaml:"name"`
+	Tool    string              `yaml:"tool"`    // Regexp matched against the whole tool name; empty matches any tool
+	When    []RedirectPredicate `yaml:"when"`    // All must hold
+	Action  string              `yaml:"action"`  // deny, allow, ask or rewrite
+	Reason  string              `yaml:"reason"`  // Shown to Claude
+	Rewrite []RedirectRewrite   `yaml:"rewrite"` // Input edits for the rewrite action
+
+	// Project is set for rules from the project config file. Anyone who can
+	// commit to a repository can write those, so their allow rules ask
+	// instead.
+	Project bool `yaml:"-"`
+}
+
+// RedirectPredicate tests one value in the tool input, addressed by a JSON
+// path such as "command" or "$.edits[0].new_string". Every condition that is
+// set must hold.
+type RedirectPredicate struct {
+	Path     string  `yaml:"path"`
+	Equals   *string `yaml:"equals"`
+	Contains string  `yaml:"contains"`
+	Matches  string  `yaml:"matches"` // Regexp
+	Exists   *bool   `yaml:"exists"`
+}
+
+// RedirectRewrite edits one string value in the tool input. Without a
+// pattern the value is replaced by Replace; otherwise each match of the
+// pattern is, with $1-style expansion.
+type RedirectRewrite struct {
+	Path    string `yaml:"path"`
+	Pattern string `yaml:"pattern"`
+	Replace string `yaml:"replace"`
+}
+
+// BranchPolicy controls which git operations branch-guard blocks. The first
+// rule whose pattern matches a branch applies to it. Rules from the project
+// config apply on top of the global ones: a branch gets the first matching
+// rule of each, and the strictest action of the two.
+type BranchPolicy struct {
+	// DetectDefault protects the remote's default branch (origin/HEAD) as
+	// if it had a rule denying DefaultBranchOperations.
+	DetectDefault bool         `yaml:"detect_default"`
+	Protected     []BranchRule `yaml:"protected"`
+}
+
+// BranchRule sets the policy for branches matching a glob pattern.
+type BranchRule struct {
+	Pattern string   `yaml:"pattern"` // Glob, e.g. "release/*"
+	Deny    []string `yaml:"deny"`    // Operations that are blocked
+	Ask     []string `yaml:"ask"`     // Operations that need confirmation
+
+	// Project is set for rules from the project config file.
+	Project bool `yaml:"-"`
+}
+
+// CommandGuard tunes the command-guard hook's built-in rule catalogue.
+type CommandGuard struct {
+	Disabled []string          `yaml:"disabled"` // Rules to turn off
+	Actions  map[string]string `yaml:"actions"`  // Per-rule action override: deny or ask
+	// AllowedPaths are directories, besides the project root and the temp
+	// directory, that destructive commands may touch. "~" is expanded.
+	AllowedPaths []string `yaml:"allowed_paths"`
+}
+
+// CommandGuardRules are the rules of the command-guard catalogue.
+var CommandGuardRules = []string{
+	"rm-outside-project",
+	"find-delete-outside-project",
+	"pipe-to-shell",
+	"chmod-unsafe",
+	"docker-prune",
+	"write-sensitive-path",
+	"exfiltrate-secrets",
+	"disk-destroy",
+	"sudo",
+}
+
+// SecretGuard controls the secret-guard hook.
+type SecretGuard struct {
+	Disabled []string `yaml:"disabled"` // Rules to turn off
+	// Allowlist is the allowlist file, relative to the project root.
+	Allowlist string `yaml:"allowlist"`
+	// Entropy is the minimum Shannon entropy, in bits per character, of a
+	// high-entropy string.
+	Entropy float64 `yaml:"entropy"`
+}
+
+// SecretRules are the rules of the secret-guard scanner.
+var SecretRules = []string{
+	"aws-access-key-id",
+	"aws-secret-access-key",
+	"github-token",
+	"slack-token",
+	"slack-webhook",
+	"private-key",
+	"dotenv-assignment",
+	"high-entropy",
+}
+
+// BranchOperations are the git operations a branch rule can restrict.
+var BranchOperations = []string{"commit", "push", "force-push", "rebase", "delete", "tag", "reset-hard"}
+
+// DefaultBranchOperations are denied on main, master and the detected
+// default branch unless configured otherwise.
+var DefaultBranchOperations = []string{"commit", "push", "force-push", "delete"}
+
+// RedirectActions are the actions a tool-redirect rule can take.
+var RedirectActions = []string{"deny", "allow", "ask", "rewrite"}
+
+// NotifyEvents are the events the notify hook can send.
+var NotifyEvents = []string{"stop", "notification", "handoff"}
+
+// NotifySinkTypes are the supported notification backends.
+var NotifySinkTypes = []string{"desktop", "terminal", "webhook", "file"}
+
+// Defaults returns the built-in configuration.
+func Defaults() *Config {
+	return &Config{
+		Port:         DefaultPort,
+		LogLevelName: DefaultLogLevel,
+		LogLevel:     LevelOff,
+		Context: ContextConfig{
+			Thresholds:   []int{40, 60, 80, 90, 95},
+			Handoff:      90,
+			BudgetTokens: 4000,
+		},
+		Retention: RetentionConfig{
+			MaxAgeDays:        90,
+			StaleSessionHours: 24,
+			Interval:          6 * time.Hour,
+		},
+		Search: SearchConfig{
+			Fusion:           "rrf",
+			Weights:          SearchWeights{FTS: 0.4, Vector: 0.6},
+			RRFK:             60,
+			RecencyHalfLife:  30 * 24 * time.Hour,
+			RecencyWeight:    0.2,
+			TypeBoosts:       map[string]float64{},
+			ReweightInterval: 15 * time.Minute,
+		},
+		Embedding: EmbeddingConfig{
+			Provider: "tfidf",
+		},
+		Checker: CheckerConfig{
+			Timeout:     10 * time.Second,
+			ToolTimeout: 8 * time.Second,
+			ChangedOnly: true,
+			Cache:       true,
+		},
+		Notify: NotifyConfig{
+			Events: []string{"stop", "notification", "handoff"},
+			Sinks:  []NotifySink{{Type: "desktop"}},
+		},
+		ToolRedirect: ToolRedirect{
+			Builtin: true,
+		},
+		BranchPolicy: BranchPolicy{
+			DetectDefault: true,
+			Protected: []BranchRule{
+				{Pattern: "main", Deny: DefaultBranchOperations},
+				{Pattern: "master", Deny: DefaultBranchOperations},
+			},
+		},
+		SecretGuard: SecretGuard{
+			Allowlist: ".icc-secrets-allow",
+			Entropy:   4.0,
+		},
+	}
+}
+
+// Load resolves the configuration for the current working directory.
 func Load() (*Config, error) {
-	port := DefaultPort
-	if v := os.Getenv(EnvPrefix + "_PORT"); v != "" {
-		p, err := strconv.Atoi(v)
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	return LoadFrom(dir)
+}
+
+// LoadFrom resolves the configuration for a project directory: defaults, the
+// global config file, the nearest .icc.yaml at or above dir, then
+// environment variables. The result is validated.
+func LoadFrom(dir string) (*Config, error) {
+	cfg := Defaults()
+
+	files := []string{GlobalConfigPath()}
+	project := FindProjectConfig(dir)
+	if project != "" {
+		files = append(files, project)
+	}
+	for _, path := range files {
+		trusted := *cfg
+		// Decoding merges into maps, so keep the trusted one apart
+		trusted.CommandGuard.Actions = maps.Clone(cfg.CommandGuard.Actions)
+		applied, err := cfg.applyFile(path, path == project)
 		if err != nil {
-			return nil, fmt.Errorf("invalid %s_PORT: %w", EnvPrefix, err)
+			return nil, err
+		}
+		if applied {
+			cfg.Sources = append(cfg.Sources, path)
+		}
+		if applied && path == project {
+			cfg.restrictProject(&trusted)
 		}
-		port = p
 	}
 
-	level := parseLogLevel(os.Getenv(EnvPrefix + "_LOG_LEVEL"))
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
-	return &Config{
-		Port:     port,
-		LogLevel: level,
-	}, nil
+// projectKeys are the settings a project config file may change, as dotted
+// keys that cover everything below them. Anyone who can commit to a
+// repository can write its .icc.yaml, so it can't move the shared console
+// server, send observations or notifications elsewhere, expire other
+// projects' memories, or add checker commands that run on every edit.
+// Others are ignored with a warning.
+var projectKeys = []string{
+	"log_level",
+	"context",
+	"search",
+	"checker.timeout",
+	"checker.tool_timeout",
+	"checker.changed_only",
+	"checker.cache",
+	"notify.events",
+	"tool_redirect",
+	"branch_policy",
+	"command_guard",
+	"secret_guard",
+}
+
+// ProjectKey reports whether a project config file may set a dotted key.
+func ProjectKey(key string) bool {
+	return slices.ContainsFunc(projectKeys, func(k string) bool { return key == k || strings.HasPrefix(key, k+".") })
+}
+
+// filterProjectKeys removes the keys that aren't in projectKeys from a
+// mapping node, and returns them.
+func filterProjectKeys(node *yaml.Node, prefix string) []string {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var dropped []string
+	content := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		name := prefix + key.Value
+		switch {
+		case ProjectKey(name):
+		case slices.ContainsFunc(projectKeys, func(k string) bool { return strings.HasPrefix(k, name+".") }) && value.Kind == yaml.MappingNode:
+			dropped = append(dropped, filterProjectKeys(value, name+".")...)
+		default:
+			dropped = append(dropped, name)
+			continue
+		}
+		content = append(content, key, value)
+	}
+	node.Content = content
+	return dropped
+}
+
+// restrictProject limits what the project config file can change, besides
+// the keys it may set at all (see projectKeys). trusted is the config
+// before the file was applied. The guards can only be tightened: rules
+// can't be disabled, paths and secrets not allowed, command-guard actions
+// only raised to deny, the entropy threshold only lowered, and the built-in
+// tool-redirect rules not dropped. Tool-redirect rules the file added are
+// marked, so their allow rules ask instead. Its protected-branch rules and
+// default-branch detection add to the trusted ones rather than replace
+// them.
+func (c *Config) restrictProject(trusted *Config) {
+	ignore := func(key string, changed bool) {
+		if changed {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("ignored %s from the project config: it can only tighten the guards", key))
+		}
+	}
+
+	cg, tcg := &c.CommandGuard, trusted.CommandGuard
+	ignore("command_guard.disabled", !slices.Equal(cg.Disabled, tcg.Disabled))
+	ignore("command_guard.allowed_paths", !slices.Equal(cg.AllowedPaths, tcg.AllowedPaths))
+	cg.Disabled, cg.AllowedPaths = tcg.Disabled, tcg.AllowedPaths
+	actions := maps.Clone(tcg.Actions)
+	for rule, action := range cg.Actions {
+		if action == "deny" {
+			if actions == nil {
+				actions = map[string]string{}
+			}
+			actions[rule] = action
+		} else {
+			ignore("command_guard.actions."+rule, tcg.Actions[rule] != action)
+		}
+	}
+	cg.Actions = actions
+
+	sg, tsg := &c.SecretGuard, trusted.SecretGuard
+	ignore("secret_guard.disabled", !slices.Equal(sg.Disabled, tsg.Disabled))
+	ignore("secret_guard.allowlist", sg.Allowlist != tsg.Allowlist)
+	ignore("secret_guard.entropy", sg.Entropy > tsg.Entropy)
+	sg.Disabled, sg.Allowlist, sg.Entropy = tsg.Disabled, tsg.Allowlist, min(sg.Entropy, tsg.Entropy)
+
+	ignore("tool_redirect.builtin", !c.ToolRedirect.Builtin && trusted.ToolRedirect.Builtin)
+	c.ToolRedirect.Builtin = c.ToolRedirect.Builtin || trusted.ToolRedirect.Builtin
+	for i, rule := range c.ToolRedirect.Rules {
+		c.ToolRedirect.Rules[i].Project = !slices.ContainsFunc(trusted.ToolRedirect.Rules, func(t RedirectRule) bool {
+			return reflect.DeepEqual(t, rule)
+		})
+	}
+
+	bp := &c.BranchPolicy
+	for i, rule := range bp.Protected {
+		bp.Protected[i].Project = !slices.ContainsFunc(trusted.BranchPolicy.Protected, func(t BranchRule) bool {
+			return reflect.DeepEqual(t, rule)
+		})
+	}
+	for _, rule := range trusted.BranchPolicy.Protected {
+		if !slices.ContainsFunc(bp.Protected, func(r BranchRule) bool { return reflect.DeepEqual(r, rule) }) {
+			bp.Protected = append(bp.Protected, rule)
+		}
+	}
+	bp.DetectDefault = bp.DetectDefault || trusted.BranchPolicy.DetectDefault
+}
+
+// FindProjectConfig returns the path of the nearest project config file at
+// or above dir, or "" if there is none.
+func FindProjectConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, ProjectConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// applyFile overlays the YAML file at path. Keys that are absent keep their
+// current values; unknown keys are an error. Of a project file, only the
+// projectKeys are applied. Returns false if the file doesn't exist.
+func (c *Config) applyFile(path string, project bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read config %s: %w", path, err)
+	}
+	if project {
+		if data, err = c.filterProject(data); err != nil {
+			return false, fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+	if err := c.overlay(data); err != nil {
+		return false, fmt.Errorf("config %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// filterProject drops the keys a project file may not set from its YAML,
+// with a warning for each.
+func (c *Config) filterProject(data []byte) ([]byte, error) {
+	// Report unknown keys before dropping any
+	if err := (&Config{}).overlay(data); err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+	dropped := filterProjectKeys(doc.Content[0], "")
+	for _, key := range dropped {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("ignored %s from the project config: only the global config can set it", key))
+	}
+	if len(dropped) == 0 {
+		return data, nil
+	}
+	return yaml.Marshal(doc.Content[0])
+}
+
+// overlay decodes YAML onto c, rejecting unknown keys.
+func (c *Config) overlay(data []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// applyEnv overlays environment variables.
+func (c *Config) applyEnv() error {
+	if v := os.Getenv(EnvPrefix + "_PORT"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s_PORT: %w", EnvPrefix, err)
+		}
+		c.Port = p
+	}
+	if v := os.Getenv(EnvPrefix + "_LOG_LEVEL"); v != "" {
+		// Unknown levels turn logging off rather than failing
+		c.LogLevelName = strings.ToLower(v)
+		if _, ok := logLevels[c.LogLevelName]; !ok {
+			c.LogLevelName = DefaultLogLevel
+		}
+	}
+	if v := os.Getenv(EnvPrefix + "_EMBEDDING_PROVIDER"); v != "" {
+		c.Embedding.Provider = strings.ToLower(v)
+	}
+	if v := os.Getenv(EnvPrefix + "_EMBEDDING_URL"); v != "" {
+		c.Embedding.URL = v
+	}
+	if v := os.Getenv(EnvPrefix + "_EMBEDDING_MODEL"); v != "" {
+		c.Embedding.Model = v
+	}
+	if v := os.Getenv(EnvPrefix + "_EMBEDDING_API_KEY"); v != "" {
+		c.Embedding.APIKey = v
+	}
+	return nil
 }
 
 // LevelOff is a log level above LevelError that suppresses all log output.
 const LevelOff = slog.Level(16)
 
-func parseLogLevel(s string) slog.Level {
-	switch strings.ToLower(s) {
-	case "debug":
-		return slog.LevelDebug
-	case "info":
-		return slog.LevelInfo
-	case "warn", "warning":
-		return slog.LevelWarn
-	case "error":
-		return slog.LevelError
-	default:
-		return LevelOff
+var logLevels = map[string]slog.Level{
+	"off":     LevelOff,
+	"debug":   slog.LevelDebug,
+	"info":    slog.LevelInfo,
+	"warn":    slog.LevelWarn,
+	"warning": slog.LevelWarn,
+	"error":   slog.LevelError,
+}
+
+// Validate checks that all values are in range and resolves LogLevel.
+func (c *Config) Validate() error {
+	var errs []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Port > 0 && c.Port < 65536, "port must be between 1 and 65535, got %d", c.Port)
+	level, ok := logLevels[strings.ToLower(c.LogLevelName)]
+	check(ok, "log_level must be one of off, debug, info, warn, error, got %q", c.LogLevelName)
+
+	check(len(c.Context.Thresholds) > 0, "context.thresholds must not be empty")
+	for i, t := range c.Context.Thresholds {
+		check(t > 0 && t <= 100, "context.thresholds must be between 1 and 100, got %d", t)
+		check(i == 0 || t > c.Context.Thresholds[i-1], "context.thresholds must be ascending")
+	}
+	check(c.Context.Handoff > 0 && c.Context.Handoff <= 100, "context.handoff must be between 1 and 100, got %d", c.Context.Handoff)
+	check(c.Context.BudgetTokens > 0, "context.budget_tokens must be positive, got %d", c.Context.BudgetTokens)
+
+	check(c.Retention.MaxAgeDays > 0, "retention.max_age_days must be positive, got %d", c.Retention.MaxAgeDays)
+	check(c.Retention.StaleSessionHours > 0, "retention.stale_session_hours must be positive, got %d", c.Retention.StaleSessionHours)
+	check(c.Retention.Interval >= time.Minute, "retention.interval must be at least 1m, got %s", c.Retention.Interval)
+
+	check(c.Search.Fusion == "rrf" || c.Search.Fusion == "weighted", "search.fusion must be rrf or weighted, got %q", c.Search.Fusion)
+	check(c.Search.Weights.FTS >= 0 && c.Search.Weights.Vector >= 0, "search.weights must not be negative")
+	check(c.Search.Weights.FTS+c.Search.Weights.Vector > 0, "search.weights must not both be zero")
+	check(c.Search.RRFK > 0, "search.rrf_k must be positive, got %g", c.Search.RRFK)
+	check(c.Search.RecencyHalfLife >= 0, "search.recency_half_life must not be negative")
+	check(c.Search.RecencyWeight >= 0 && c.Search.RecencyWeight <= 1, "search.recency_weight must be between 0 and 1, got %g", c.Search.RecencyWeight)
+	for typ, boost := range c.Search.TypeBoosts {
+		check(boost > 0, "search.type_boosts.%s must be positive, got %g", typ, boost)
+	}
+	check(c.Search.ReweightInterval >= time.Second, "search.reweight_interval must be at least 1s, got %s", c.Search.ReweightInterval)
+
+	check(c.Embedding.Provider == "tfidf" || c.Embedding.Provider == "openai", "embedding.provider must be tfidf or openai, got %q", c.Embedding.Provider)
+	check(c.Embedding.Provider != "openai" || c.Embedding.Model != "", "embedding.model is required for the openai provider")
+
+	check(c.Checker.Timeout > 0, "checker.timeout must be positive, got %s", c.Checker.Timeout)
+	check(c.Checker.ToolTimeout > 0, "checker.tool_timeout must be positive, got %s", c.Checker.ToolTimeout)
+	names := map[string]bool{}
+	for i, cc := range c.Checker.Custom {
+		key := fmt.Sprintf("checker.custom[%d]", i)
+		errs = append(errs, cc.validate(key)...)
+		check(!names[cc.Name], "%s.name %q is used twice", key, cc.Name)
+		names[cc.Name] = true
+	}
+
+	for _, e := range c.Notify.Events {
+		check(slices.Contains(NotifyEvents, e), "notify.events: unknown event %q", e)
+	}
+	for i, sink := range c.Notify.Sinks {
+		check(slices.Contains(NotifySinkTypes, sink.Type), "notify.sinks[%d].type must be one of %s, got %q", i, strings.Join(NotifySinkTypes, ", "), sink.Type)
+		for _, e := range sink.Events {
+			check(slices.Contains(NotifyEvents, e), "notify.sinks[%d].events: unknown event %q", i, e)
+		}
+		check(sink.Type != "webhook" || sink.URL != "", "notify.sinks[%d].url is required for webhook", i)
+		check(sink.Type != "file" || sink.Path != "", "notify.sinks[%d].path is required for file", i)
+	}
+
+	for i, rule := range c.ToolRedirect.Rules {
+		errs = append(errs, rule.validate(fmt.Sprintf("tool_redirect.rules[%d]", i))...)
+	}
+
+	for i, rule := range c.BranchPolicy.Protected {
+		key := fmt.Sprintf("branch_policy.protected[%d]", i)
+		_, err := path.Match(rule.Pattern, "")
+		check(rule.Pattern != "" && err == nil, "%s.pattern must be a valid glob, got %q", key, rule.Pattern)
+		for _, op := range append(slices.Clone(rule.Deny), rule.Ask...) {
+			check(slices.Contains(BranchOperations, op), "%s: unknown operation %q (want one of %s)", key, op, strings.Join(BranchOperations, ", "))
+		}
+		for _, op := range rule.Ask {
+			check(!slices.Contains(rule.Deny, op), "%s: operation %q is in both deny and ask", key, op)
+		}
+	}
+
+	for _, name := range c.CommandGuard.Disabled {
+		check(slices.Contains(CommandGuardRules, name), "command_guard.disabled: unknown rule %q", name)
+	}
+	for name, action := range c.CommandGuard.Actions {
+		check(slices.Contains(CommandGuardRules, name), "command_guard.actions: unknown rule %q", name)
+		check(action == "deny" || action == "ask", "command_guard.actions.%s must be deny or ask, got %q", name, action)
+	}
+	for _, p := range c.CommandGuard.AllowedPaths {
+		check(filepath.IsAbs(p) || p == "~" || strings.HasPrefix(p, "~/"), "command_guard.allowed_paths must be absolute, got %q", p)
+	}
+
+	for _, name := range c.SecretGuard.Disabled {
+		check(slices.Contains(SecretRules, name), "secret_guard.disabled: unknown rule %q", name)
+	}
+	check(c.SecretGuard.Entropy > 0 && c.SecretGuard.Entropy <= 8, "secret_guard.entropy must be between 0 and 8, got %g", c.SecretGuard.Entropy)
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("invalid config: %s", strings.Join(errs, "; "))
+	}
+	c.LogLevel = level
+	return nil
+}
+
+// validate returns the problems with a redirect rule, prefixed with key.
+func (r RedirectRule) validate(key string) []string {
+	var errs []string
+	checkRegexp := func(field, expr string) {
+		if _, err := regexp.Compile(expr); err != nil {
+			errs = append(errs, fmt.Sprintf("%s.%s: %v", key, field, err))
+		}
+	}
+
+	if !slices.Contains(RedirectActions, r.Action) {
+		errs = append(errs, fmt.Sprintf("%s.action must be one of %s, got %q", key, strings.Join(RedirectActions, ", "), r.Action))
+	}
+	checkRegexp("tool", r.Tool)
+	for i, p := range r.When {
+		k := fmt.Sprintf("when[%d]", i)
+		if p.Path == "" {
+			errs = append(errs, fmt.Sprintf("%s.%s.path is required", key, k))
+		}
+		if p.Equals == nil && p.Contains == "" && p.Matches == "" && p.Exists == nil {
+			errs = append(errs, fmt.Sprintf("%s.%s needs equals, contains, matches or exists", key, k))
+		}
+		checkRegexp(k+".matches", p.Matches)
+	}
+	if r.Action == "rewrite" && len(r.Rewrite) == 0 {
+		errs = append(errs, fmt.Sprintf("%s.rewrite is required for the rewrite action", key))
+	}
+	for i, rw := range r.Rewrite {
+		if rw.Path == "" {
+			errs = append(errs, fmt.Sprintf("%s.rewrite[%d].path is required", key, i))
+		}
+		checkRegexp(fmt.Sprintf("rewrite[%d].pattern", i), rw.Pattern)
+	}
+	return errs
+}
+
+// validate returns the problems with a custom checker, prefixed with key.
+func (cc CustomChecker) validate(key string) []string {
+	var errs []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, key+"."+fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(cc.Name != "", "name is required")
+	check(len(cc.Files) > 0, "files is required")
+	for _, glob := range cc.Files {
+		_, err := path.Match(strings.ReplaceAll(glob, "**", "*"), "")
+		check(glob != "" && err == nil, "files must be valid globs, got %q", glob)
+	}
+	check(strings.TrimSpace(cc.Command) != "", "command is required")
+	check(slices.Contains(CheckerFormats, cc.Format), "format must be one of %s, got %q", strings.Join(CheckerFormats, ", "), cc.Format)
+	check(cc.Severity == "" || cc.Severity == "error" || cc.Severity == "warning", "severity must be error or warning, got %q", cc.Severity)
+	if cc.Format == "regex" {
+		re, err := regexp.Compile(cc.Pattern)
+		switch {
+		case cc.Pattern == "":
+			check(false, "pattern is required for the regex format")
+		case err != nil:
+			check(false, "pattern: %v", err)
+		default:
+			check(slices.Contains(re.SubexpNames(), "message"), "pattern needs a (?P<message>...) group")
+		}
+	}
+	return errs
+}
+
+// ValidateYAML checks a config file's contents: the keys must be known and
+// the values, applied on top of the defaults, must validate.
+func ValidateYAML(data []byte) error {
+	cfg := Defaults()
+	if err := cfg.overlay(data); err != nil {
+		return err
 	}
+	return cfg.Validate()
 }
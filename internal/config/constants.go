@@ -10,6 +10,10 @@ const (
 	// DefaultLogLevel is the default structured log level.
 	// "off" disables all log output; users must set ICC_LOG_LEVEL explicitly to see logs.
 	DefaultLogLevel = "off"
+
+	// ProjectConfigName is the per-project config file, looked up in the
+	// project directory and its ancestors.
+	ProjectConfigName = ConfigDirName + ".yaml"
 )
 
 // Version returns the build version string.
@@ -18,6 +18,11 @@ func HomeDir() string {
 	return filepath.Join(home, ConfigDirName)
 }
 
+// GlobalConfigPath returns the path to the user-wide config file.
+func GlobalConfigPath() string {
+	return filepath.Join(HomeDir(), "config.yaml")
+}
+
 // DBDir returns the directory for SQLite database files.
 func DBDir() string {
 	return filepath.Join(HomeDir(), "db")
@@ -37,7 +37,7 @@ func (b *Builder) Build(observations []*db.Observation, summaries []*db.Summary)
 		var summaryLines []string
 
 		for _, s := range summaries {
-			line := fmt.Sprintf("- [Session %s] %s", s.SessionID, s.Text)
+			line := formatSummary(s)
 			lineTokens := EstimateTokens(line)
 			if usedTokens+lineTokens > b.maxTokens {
 				break
@@ -95,3 +95,31 @@ func (b *Builder) Build(observations []*db.Observation, summaries []*db.Summary)
 
 	return strings.Join(parts, "\n\n")
 }
+
+// maxSectionItems limits how many items of a summary section are injected.
+const maxSectionItems = 5
+
+// formatSummary renders a summary as a list entry. Structured summaries show
+// their headline followed by one indented line per section; plain summaries
+// are rendered as-is.
+func formatSummary(s *db.Summary) string {
+	if len(s.Sections) == 0 {
+		return fmt.Sprintf("- [Session %s] %s", s.SessionID, s.Text)
+	}
+
+	headline, _, _ := strings.Cut(s.Text, "\n")
+	lines := []string{fmt.Sprintf("- [Session %s] %s", s.SessionID, headline)}
+	for _, sec := range s.Sections {
+		if len(sec.Items) == 0 {
+			continue
+		}
+		items := sec.Items
+		more := ""
+		if len(items) > maxSectionItems {
+			more = fmt.Sprintf(" (+%d more)", len(items)-maxSectionItems)
+			items = items[:maxSectionItems]
+		}
+		lines = append(lines, fmt.Sprintf("  - **%s**: %s%s", sec.Title, strings.Join(items, "; "), more))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -49,6 +49,31 @@ func TestBuildWithSummariesOnly(t *testing.T) {
 	}
 }
 
+func TestBuildWithStructuredSummary(t *testing.T) {
+	b := NewBuilder(4000)
+
+	summaries := []*db.Summary{
+		{
+			ID:        1,
+			SessionID: "s1",
+			Text:      "Edited 7 files, tasks 2/3\n\n## Files Touched\n- a.go",
+			Sections: []db.SummarySection{
+				{Title: "Files Touched", Items: []string{"a.go", "b.go", "c.go", "d.go", "e.go", "f.go", "g.go"}},
+				{Title: "Tasks", Items: []string{"2/3 completed"}},
+			},
+		},
+	}
+
+	result := b.Build(nil, summaries)
+
+	if !containsAll(result, "Edited 7 files, tasks 2/3", "**Files Touched**: a.go; b.go", "(+2 more)", "**Tasks**: 2/3 completed") {
+		t.Errorf("result missing structured summary content: %s", result)
+	}
+	if containsAll(result, "## Files Touched") {
+		t.Errorf("rendered text body should not be injected for structured summaries: %s", result)
+	}
+}
+
 func TestBuildWithBoth(t *testing.T) {
 	b := NewBuilder(4000)
 
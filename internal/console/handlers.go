@@ -41,9 +41,9 @@ func (s *Server) handleCreateObservation(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Broadcast to SSE subscribers
-	eventData, _ := json.Marshal(map[string]any{"id": id, "type": req.Type, "title": req.Title})
-	s.sse.Send(Event{Type: "observation", Data: string(eventData)})
+	s.indexObservation(id)
+
+	s.broadcastObservation(map[string]any{"action": "created", "id": id, "type": req.Type, "title": req.Title})
 
 	writeJSON(w, http.StatusCreated, map[string]int64{"id": id})
 }
@@ -241,7 +241,7 @@ func (s *Server) handleContextInject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	builder := ctxbuilder.NewBuilder(4000)
+	builder := ctxbuilder.NewBuilder(s.cfg.Context.BudgetTokens)
 	ctx := builder.Build(obs, summaries)
 
 	writeJSON(w, http.StatusOK, map[string]string{"context": ctx})
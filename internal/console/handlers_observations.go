@@ -0,0 +1,239 @@
+package console
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/itk-dev/itkdev-claude-code/internal/db"
+)
+
+// errEmptyText is returned when an edit would leave an observation without text.
+var errEmptyText = errors.New("text cannot be empty")
+
+// observationPatch holds the fields to change on an observation. Nil fields
+// are left unchanged.
+type observationPatch struct {
+	Type     *string `json:"type"`
+	Title    *string `json:"title"`
+	Text     *string `json:"text"`
+	Project  *string `json:"project"`
+	Metadata *string `json:"metadata"`
+}
+
+func (p observationPatch) apply(o *db.Observation) error {
+	if p.Text != nil && strings.TrimSpace(*p.Text) == "" {
+		return errEmptyText
+	}
+	for _, f := range []struct {
+		dst *string
+		src *string
+	}{
+		{&o.Type, p.Type},
+		{&o.Title, p.Title},
+		{&o.Text, p.Text},
+		{&o.Project, p.Project},
+		{&o.Metadata, p.Metadata},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	return nil
+}
+
+// updateObservation applies a patch, re-indexes the observation and
+// broadcasts the change. Returns nil if the observation doesn't exist.
+func (s *Server) updateObservation(id int64, patch observationPatch) (*db.Observation, error) {
+	obs, err := s.db.GetObservation(id)
+	if err != nil || obs == nil {
+		return nil, err
+	}
+	if err := patch.apply(obs); err != nil {
+		return nil, err
+	}
+	if err := s.db.UpdateObservation(obs); err != nil {
+		return nil, err
+	}
+
+	s.indexObservation(id)
+	s.broadcastObservation(map[string]any{"action": "updated", "id": id, "type": obs.Type, "title": obs.Title})
+	return obs, nil
+}
+
+// deleteObservation removes an observation and its embedding and broadcasts
+// the deletion. Returns false if the observation doesn't exist.
+func (s *Server) deleteObservation(id int64) (bool, error) {
+	deleted, err := s.db.DeleteObservation(id)
+	if err != nil || !deleted {
+		return false, err
+	}
+
+	s.unindexObservation(id)
+	s.broadcastObservation(map[string]any{"action": "deleted", "id": id})
+	return true, nil
+}
+
+// mergeObservations folds duplicates into a target observation. Unless the
+// patch sets the text, the target keeps its text and each source's text that
+// it doesn't already contain is appended. An empty target title is taken
+// from the first source that has one. Returns nil if any observation doesn't
+// exist.
+func (s *Server) mergeObservations(targetID int64, sourceIDs []int64, patch observationPatch) (*db.Observation, error) {
+	target, err := s.db.GetObservation(targetID)
+	if err != nil || target == nil {
+		return nil, err
+	}
+	sources, err := s.db.GetObservations(sourceIDs)
+	if err != nil || len(sources) != len(sourceIDs) {
+		return nil, err
+	}
+
+	for _, src := range sources {
+		if !strings.Contains(target.Text, src.Text) {
+			target.Text += "\n\n" + src.Text
+		}
+		if target.Title == "" {
+			target.Title = src.Title
+		}
+	}
+	if err := patch.apply(target); err != nil {
+		return nil, err
+	}
+
+	merged, err := s.db.MergeObservations(target, sourceIDs)
+	if err != nil || !merged {
+		return nil, err
+	}
+
+	for _, id := range sourceIDs {
+		s.unindexObservation(id)
+	}
+	s.indexObservation(targetID)
+	s.broadcastObservation(map[string]any{
+		"action":     "merged",
+		"id":         targetID,
+		"merged_ids": sourceIDs,
+		"type":       target.Type,
+		"title":      target.Title,
+	})
+	return target, nil
+}
+
+// broadcastObservation sends an observation event to SSE subscribers.
+func (s *Server) broadcastObservation(data map[string]any) {
+	eventData, _ := json.Marshal(data)
+	s.sse.Send(Event{Type: "observation", Data: string(eventData)})
+}
+
+func (s *Server) handleUpdateObservation(w http.ResponseWriter, r *http.Request) {
+	id := parseID(chi.URLParam(r, "id"))
+	if id <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+
+	var patch observationPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+
+	obs, err := s.updateObservation(id, patch)
+	if errors.Is(err, errEmptyText) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		s.logger.Error("update observation", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	if obs == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, obs)
+}
+
+func (s *Server) handleDeleteObservation(w http.ResponseWriter, r *http.Request) {
+	id := parseID(chi.URLParam(r, "id"))
+	if id <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+
+	deleted, err := s.deleteObservation(id)
+	if err != nil {
+		s.logger.Error("delete observation", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	if !deleted {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleMergeObservations(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TargetID  int64   `json:"target_id"`
+		SourceIDs []int64 `json:"source_ids"`
+		observationPatch
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+
+	sourceIDs, msg := validateMerge(req.TargetID, req.SourceIDs)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	obs, err := s.mergeObservations(req.TargetID, sourceIDs, req.observationPatch)
+	if errors.Is(err, errEmptyText) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		s.logger.Error("merge observations", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	if obs == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, obs)
+}
+
+// validateMerge checks merge IDs and returns the de-duplicated source IDs,
+// or an error message.
+func validateMerge(targetID int64, sourceIDs []int64) ([]int64, string) {
+	if targetID <= 0 {
+		return nil, "target_id is required"
+	}
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, id := range sourceIDs {
+		if id <= 0 {
+			return nil, "invalid source id"
+		}
+		if id == targetID {
+			return nil, "source_ids must not contain target_id"
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, "source_ids is required"
+	}
+	return ids, ""
+}
@@ -1,6 +1,7 @@
 package console
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/itk-dev/itkdev-claude-code/internal/search"
@@ -19,12 +20,30 @@ func (s *Server) handleHybridSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results, err := s.search.Search(search.SearchQuery{
+	fusion, err := search.ParseFusion(r.URL.Query().Get("fusion"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	mode, err := search.ParseMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	results, err := s.search.Search(r.Context(), search.SearchQuery{
 		Text:    query,
 		Type:    r.URL.Query().Get("type"),
 		Project: r.URL.Query().Get("project"),
 		Limit:   int(parseID(r.URL.Query().Get("limit"))),
+		Mode:    mode,
+		Fusion:  fusion,
+		Explain: r.URL.Query().Get("explain") == "true",
 	})
+	if errors.Is(err, search.ErrIndexing) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		s.logger.Error("hybrid search", "error", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
@@ -44,5 +63,28 @@ func (s *Server) handleReindex(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "reindex failed"})
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]string{"status": "reindexed"})
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reindexed", "model": s.search.Model()})
+}
+
+// indexObservation adds a newly written observation to the vector index.
+// External embedders embed it in the background, so the request doesn't wait
+// for the provider. Failures are logged or retried by the reweighter; the
+// observation is still searchable via FTS meanwhile.
+func (s *Server) indexObservation(id int64) {
+	if s.search == nil {
+		return
+	}
+	if err := s.search.QueueObservation(id); err != nil {
+		s.logger.Warn("index observation", "id", id, "error", err)
+	}
+}
+
+// unindexObservation removes a deleted observation from the vector index.
+func (s *Server) unindexObservation(id int64) {
+	if s.search == nil {
+		return
+	}
+	if err := s.search.RemoveObservation(id); err != nil {
+		s.logger.Warn("unindex observation", "id", id, "error", err)
+	}
 }
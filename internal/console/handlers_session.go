@@ -11,6 +11,7 @@ import (
 func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		ID       string `json:"id"`
+		ParentID string `json:"parent_id"`
 		Project  string `json:"project"`
 		Metadata string `json:"metadata"`
 	}
@@ -24,6 +25,7 @@ func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 
 	if err := s.db.InsertSession(&db.Session{
 		ID:       req.ID,
+		ParentID: req.ParentID,
 		Project:  req.Project,
 		Metadata: req.Metadata,
 	}); err != nil {
@@ -111,8 +113,9 @@ func (s *Server) handleCleanupSessions(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleCreateSummary(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		SessionID string `json:"session_id"`
-		Text      string `json:"text"`
+		SessionID string              `json:"session_id"`
+		Text      string              `json:"text"`
+		Sections  []db.SummarySection `json:"sections"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
@@ -122,6 +125,7 @@ func (s *Server) handleCreateSummary(w http.ResponseWriter, r *http.Request) {
 	id, err := s.db.InsertSummary(&db.Summary{
 		SessionID: req.SessionID,
 		Text:      req.Text,
+		Sections:  req.Sections,
 	})
 	if err != nil {
 		s.logger.Error("insert summary", "error", err)
@@ -0,0 +1,42 @@
+package console
+
+import (
+	"net/http"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/db"
+)
+
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	f := db.ExportFilter{
+		Project:   r.URL.Query().Get("project"),
+		DateStart: r.URL.Query().Get("since"),
+		DateEnd:   r.URL.Query().Get("until"),
+	}
+	if err := f.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="icc-memory.jsonl"`)
+	if _, err := s.db.Export(w, f); err != nil {
+		// Headers are already sent; the client sees a truncated export
+		s.logger.Error("export", "error", err)
+	}
+}
+
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.db.Import(r.Body)
+	if err != nil {
+		s.logger.Warn("import", "error", err)
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if s.search != nil {
+		if err := s.search.IndexMissing(); err != nil {
+			s.logger.Warn("index imported observations", "error", err)
+		}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -53,3 +53,37 @@ func TestReindexEmpty(t *testing.T) {
 		t.Fatalf("reindex status = %d, body = %s", rr.Code, rr.Body.String())
 	}
 }
+
+func TestHybridSearchExplain(t *testing.T) {
+	srv := testServer(t)
+	doRequest(t, srv, "POST", "/api/observations", map[string]string{
+		"session_id": "s1", "type": "bugfix", "title": "auth bug",
+		"text": "Fixed authentication login flow",
+	})
+
+	rr := doRequest(t, srv, "GET", "/api/observations/hybrid-search?q=authentication&explain=true&fusion=weighted", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("hybrid search status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+
+	var results []struct {
+		Score       float64        `json:"score"`
+		Explanation map[string]any `json:"explanation"`
+	}
+	json.NewDecoder(rr.Body).Decode(&results)
+	if len(results) == 0 {
+		t.Fatal("expected results from hybrid search")
+	}
+	e := results[0].Explanation
+	if e == nil {
+		t.Fatal("expected explanation with explain=true")
+	}
+	if e["fusion"] != "weighted" || e["fts_rank"] != float64(1) {
+		t.Errorf("explanation = %v, want weighted fusion with fts_rank 1", e)
+	}
+
+	rr = doRequest(t, srv, "GET", "/api/observations/hybrid-search?q=authentication&fusion=bogus", nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown fusion status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/itk-dev/itkdev-claude-code/internal/config"
 	"github.com/itk-dev/itkdev-claude-code/internal/db"
+	"github.com/itk-dev/itkdev-claude-code/internal/search"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -22,19 +23,23 @@ func (s *Server) newMCPServer() *server.MCPServer {
 	mcpSrv.AddTool(timelineTool(), s.handleMCPTimeline)
 	mcpSrv.AddTool(getObservationsTool(), s.handleMCPGetObservations)
 	mcpSrv.AddTool(saveMemoryTool(), s.handleMCPSaveMemory)
+	mcpSrv.AddTool(updateMemoryTool(), s.handleMCPUpdateMemory)
+	mcpSrv.AddTool(forgetMemoryTool(), s.handleMCPForgetMemory)
 
 	return mcpSrv
 }
 
 func searchTool() mcp.Tool {
 	return mcp.NewTool("search",
-		mcp.WithDescription("Search observations by text query with optional filters"),
+		mcp.WithDescription("Search observations by natural-language query with optional filters. Hybrid mode combines keyword and semantic matching."),
 		mcp.WithString("query", mcp.Required(), mcp.Description("Search query")),
 		mcp.WithNumber("limit", mcp.Description("Max results (default 20)")),
 		mcp.WithString("type", mcp.Description("Filter by type (bugfix, feature, refactor, discovery, decision, change)")),
 		mcp.WithString("project", mcp.Description("Filter by project name")),
 		mcp.WithString("dateStart", mcp.Description("Filter start date (YYYY-MM-DD)")),
 		mcp.WithString("dateEnd", mcp.Description("Filter end date (YYYY-MM-DD)")),
+		mcp.WithString("mode", mcp.Description("Search mode: hybrid (default), fts (keywords only) or semantic (embeddings only)")),
+		mcp.WithBoolean("explain", mcp.Description("Include a score breakdown for each result")),
 	)
 }
 
@@ -63,7 +68,25 @@ func saveMemoryTool() mcp.Tool {
 	)
 }
 
-func (s *Server) handleMCPSearch(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func updateMemoryTool() mcp.Tool {
+	return mcp.NewTool("update_memory",
+		mcp.WithDescription("Correct an existing observation. Only the given fields are changed."),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("Observation ID")),
+		mcp.WithString("text", mcp.Description("New observation text")),
+		mcp.WithString("title", mcp.Description("New short title")),
+		mcp.WithString("type", mcp.Description("New type (bugfix, feature, refactor, discovery, decision, change)")),
+		mcp.WithString("project", mcp.Description("New project name")),
+	)
+}
+
+func forgetMemoryTool() mcp.Tool {
+	return mcp.NewTool("forget_memory",
+		mcp.WithDescription("Delete an observation that is wrong or no longer relevant"),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("Observation ID")),
+	)
+}
+
+func (s *Server) handleMCPSearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args := req.GetArguments()
 
 	query, _ := args["query"].(string)
@@ -71,29 +94,66 @@ func (s *Server) handleMCPSearch(_ context.Context, req mcp.CallToolRequest) (*m
 		return mcpError("query parameter is required"), nil
 	}
 
-	filter := db.SearchFilter{
-		Query: query,
-		Limit: intArg(args, "limit", 20),
-	}
-	if v, ok := args["type"].(string); ok {
-		filter.Type = v
-	}
-	if v, ok := args["project"].(string); ok {
-		filter.Project = v
+	modeArg, _ := args["mode"].(string)
+	mode, err := search.ParseMode(modeArg)
+	if err != nil {
+		return mcpError(err.Error()), nil
 	}
-	if v, ok := args["dateStart"].(string); ok {
-		filter.DateStart = v
+
+	q := search.SearchQuery{
+		Text:  query,
+		Limit: intArg(args, "limit", 20),
+		Mode:  mode,
 	}
-	if v, ok := args["dateEnd"].(string); ok {
-		filter.DateEnd = v
+	q.Type, _ = args["type"].(string)
+	q.Project, _ = args["project"].(string)
+	q.DateStart, _ = args["dateStart"].(string)
+	q.DateEnd, _ = args["dateEnd"].(string)
+	q.Explain, _ = args["explain"].(bool)
+
+	if s.search != nil {
+		results, err := s.search.Search(ctx, q)
+		if err == nil {
+			return mcpJSON(results)
+		}
+		if mode == search.ModeFTS {
+			return mcpError(fmt.Sprintf("search failed: %v", err)), nil
+		}
+		s.logger.Warn("mcp search, falling back to FTS", "mode", mode, "error", err)
 	}
 
-	results, err := s.db.FilteredSearch(filter)
+	// Semantic search unavailable: keyword search only
+	results, err := s.db.FilteredSearch(db.SearchFilter{
+		Query:     db.EscapeFTSQuery(q.Text),
+		Type:      q.Type,
+		Project:   q.Project,
+		DateStart: q.DateStart,
+		DateEnd:   q.DateEnd,
+		Limit:     q.Limit,
+	})
 	if err != nil {
 		return mcpError(fmt.Sprintf("search failed: %v", err)), nil
 	}
+	return mcpJSON(ftsHybridResults(results))
+}
 
-	return mcpJSON(results)
+// ftsHybridResults converts FTS5 matches, best first, to hybrid results so
+// the search tool returns the same shape with or without semantic search.
+func ftsHybridResults(obs []*db.Observation) []search.HybridResult {
+	results := make([]search.HybridResult, len(obs))
+	for i, o := range obs {
+		results[i] = search.HybridResult{
+			ID:        o.ID,
+			Score:     1 - float64(i)/float64(len(obs)),
+			Title:     o.Title,
+			Text:      o.Text,
+			ObsType:   o.Type,
+			Project:   o.Project,
+			SessionID: o.SessionID,
+			CreatedAt: o.CreatedAt,
+		}
+	}
+	return results
 }
 
 func (s *Server) handleMCPTimeline(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -159,13 +219,61 @@ func (s *Server) handleMCPSaveMemory(_ context.Context, req mcp.CallToolRequest)
 		return mcpError(fmt.Sprintf("save_memory failed: %v", err)), nil
 	}
 
-	// Broadcast to SSE subscribers
-	eventData, _ := json.Marshal(map[string]any{"id": id, "type": "discovery", "title": title})
-	s.sse.Send(Event{Type: "observation", Data: string(eventData)})
+	s.indexObservation(id)
+
+	s.broadcastObservation(map[string]any{"action": "created", "id": id, "type": "discovery", "title": title})
 
 	return mcpJSON(map[string]int64{"id": id})
 }
 
+func (s *Server) handleMCPUpdateMemory(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	id := int64(intArg(args, "id", 0))
+	if id <= 0 {
+		return mcpError("id parameter is required"), nil
+	}
+
+	var patch observationPatch
+	for key, field := range map[string]**string{
+		"text":    &patch.Text,
+		"title":   &patch.Title,
+		"type":    &patch.Type,
+		"project": &patch.Project,
+	} {
+		if v, ok := args[key].(string); ok {
+			*field = &v
+		}
+	}
+
+	obs, err := s.updateObservation(id, patch)
+	if err != nil {
+		return mcpError(fmt.Sprintf("update_memory failed: %v", err)), nil
+	}
+	if obs == nil {
+		return mcpError(fmt.Sprintf("observation %d not found", id)), nil
+	}
+	return mcpJSON(obs)
+}
+
+func (s *Server) handleMCPForgetMemory(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	id := int64(intArg(args, "id", 0))
+	if id <= 0 {
+		return mcpError("id parameter is required"), nil
+	}
+
+	deleted, err := s.deleteObservation(id)
+	if err != nil {
+		return mcpError(fmt.Sprintf("forget_memory failed: %v", err)), nil
+	}
+	if !deleted {
+		return mcpError(fmt.Sprintf("observation %d not found", id)), nil
+	}
+	return mcpJSON(map[string]int64{"deleted": id})
+}
+
 func mcpError(msg string) *mcp.CallToolResult {
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -13,7 +13,7 @@ func TestMCPToolsRegistered(t *testing.T) {
 	srv := testServer(t)
 	mcpSrv := srv.newMCPServer()
 
-	tools := []string{"search", "timeline", "get_observations", "save_memory"}
+	tools := []string{"search", "timeline", "get_observations", "save_memory", "update_memory", "forget_memory"}
 	for _, name := range tools {
 		tool := mcpSrv.GetTool(name)
 		if tool == nil {
@@ -59,6 +59,63 @@ func TestMCPSearch(t *testing.T) {
 	}
 }
 
+func TestMCPSearchModes(t *testing.T) {
+	srv := testServer(t)
+	doRequest(t, srv, "POST", "/api/observations", map[string]string{
+		"session_id": "s1", "type": "bugfix",
+		"title": "token expiry", "text": "Fixed authentication token expiry in the login flow",
+	})
+	doRequest(t, srv, "POST", "/api/observations", map[string]string{
+		"session_id": "s1", "type": "feature",
+		"title": "rate limit", "text": "Added rate limiting to the REST API",
+	})
+
+	mcpSrv := srv.newMCPServer()
+	tool := mcpSrv.GetTool("search")
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := tool.Handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "search", Arguments: args},
+		})
+		if err != nil {
+			t.Fatalf("search handler: %v", err)
+		}
+		return result
+	}
+	decode := func(result *mcp.CallToolResult) []map[string]any {
+		t.Helper()
+		if result.IsError {
+			t.Fatalf("unexpected error result: %+v", result.Content)
+		}
+		tc, _ := mcp.AsTextContent(result.Content[0])
+		var results []map[string]any
+		if err := json.Unmarshal([]byte(tc.Text), &results); err != nil {
+			t.Fatalf("decode results: %v", err)
+		}
+		return results
+	}
+
+	// FTS5 syntax characters in natural-language queries are matched literally
+	for _, mode := range []string{"", "hybrid", "fts", "semantic"} {
+		results := decode(call(map[string]any{"query": "how did we fix the token-expiry (login) bug?", "mode": mode}))
+		if len(results) == 0 || results[0]["title"] != "token expiry" {
+			t.Errorf("mode %q: expected token expiry observation first, got %v", mode, results)
+		}
+	}
+
+	if result := call(map[string]any{"query": "token", "mode": "fuzzy"}); !result.IsError {
+		t.Error("expected error for unknown mode")
+	}
+
+	// Without the orchestrator every mode falls back to keyword search
+	srv.search = nil
+	results := decode(call(map[string]any{"query": "rate-limiting?", "mode": "semantic"}))
+	if len(results) != 1 || results[0]["title"] != "rate limit" {
+		t.Errorf("fallback: expected rate limit observation, got %v", results)
+	}
+}
+
 func TestMCPGetObservations(t *testing.T) {
 	srv := testServer(t)
 
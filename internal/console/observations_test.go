@@ -0,0 +1,212 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func createObservation(t *testing.T, srv *Server, title, text string) int64 {
+	t.Helper()
+	rr := doRequest(t, srv, "POST", "/api/observations", map[string]string{
+		"session_id": "s1", "type": "discovery", "title": title, "text": text,
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	var created map[string]any
+	json.NewDecoder(rr.Body).Decode(&created)
+	return int64(created["id"].(float64))
+}
+
+// searchIDs returns the IDs of hybrid search results for q.
+func searchIDs(t *testing.T, srv *Server, q string) []int64 {
+	t.Helper()
+	rr := doRequest(t, srv, "GET", "/api/observations/hybrid-search?q="+q, nil)
+	var results []struct {
+		ID int64 `json:"id"`
+	}
+	json.NewDecoder(rr.Body).Decode(&results)
+	var ids []int64
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestUpdateObservation(t *testing.T) {
+	srv := testServer(t)
+	id := createObservation(t, srv, "cache bug", "Redis cache returns stale sessions")
+
+	ch := srv.sse.Subscribe()
+	defer srv.sse.Unsubscribe(ch)
+
+	rr := doRequest(t, srv, "PATCH", fmt.Sprintf("/api/observations/%d", id), map[string]string{
+		"text": "Memcached eviction drops sessions",
+		"type": "bugfix",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("patch status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+
+	obs, _ := srv.db.GetObservation(id)
+	if obs.Title != "cache bug" || obs.Type != "bugfix" || obs.Text != "Memcached eviction drops sessions" {
+		t.Errorf("observation after patch = %+v", obs)
+	}
+
+	e := <-ch
+	var data map[string]any
+	json.Unmarshal([]byte(e.Data), &data)
+	if e.Type != "observation" || data["action"] != "updated" {
+		t.Errorf("event = %s %s, want observation updated", e.Type, e.Data)
+	}
+
+	// FTS and vector index follow the new text
+	rr = doRequest(t, srv, "GET", "/api/observations/search?q=redis", nil)
+	var fts []any
+	json.NewDecoder(rr.Body).Decode(&fts)
+	if len(fts) != 0 {
+		t.Errorf("FTS still matches old text: %v", fts)
+	}
+	if ids := searchIDs(t, srv, "memcached"); len(ids) != 1 || ids[0] != id {
+		t.Errorf("hybrid search for new text = %v, want [%d]", ids, id)
+	}
+
+	rr = doRequest(t, srv, "PATCH", fmt.Sprintf("/api/observations/%d", id), map[string]string{"text": " "})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("empty text status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+	rr = doRequest(t, srv, "PATCH", "/api/observations/999", map[string]string{"title": "x"})
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("missing observation status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestDeleteObservation(t *testing.T) {
+	srv := testServer(t)
+	id := createObservation(t, srv, "flaky test", "Integration test flaky on CI")
+
+	ch := srv.sse.Subscribe()
+	defer srv.sse.Unsubscribe(ch)
+
+	rr := doRequest(t, srv, "DELETE", fmt.Sprintf("/api/observations/%d", id), nil)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+
+	e := <-ch
+	var data map[string]any
+	json.Unmarshal([]byte(e.Data), &data)
+	if data["action"] != "deleted" || data["id"] != float64(id) {
+		t.Errorf("event data = %s, want deleted %d", e.Data, id)
+	}
+
+	if ids := searchIDs(t, srv, "flaky"); len(ids) != 0 {
+		t.Errorf("deleted observation still found: %v", ids)
+	}
+	rr = doRequest(t, srv, "DELETE", fmt.Sprintf("/api/observations/%d", id), nil)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestMergeObservations(t *testing.T) {
+	srv := testServer(t)
+	target := createObservation(t, srv, "", "Login fails when the token expires")
+	dup := createObservation(t, srv, "token expiry", "Login fails when the token expires")
+	extra := createObservation(t, srv, "refresh", "Refresh token is never rotated")
+
+	ch := srv.sse.Subscribe()
+	defer srv.sse.Unsubscribe(ch)
+
+	rr := doRequest(t, srv, "POST", "/api/observations/merge", map[string]any{
+		"target_id":  target,
+		"source_ids": []int64{dup, extra, dup},
+		"type":       "bugfix",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("merge status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+
+	obs, _ := srv.db.GetObservation(target)
+	wantText := "Login fails when the token expires\n\nRefresh token is never rotated"
+	if obs.Text != wantText || obs.Title != "token expiry" || obs.Type != "bugfix" {
+		t.Errorf("merged observation = %+v", obs)
+	}
+	for _, id := range []int64{dup, extra} {
+		if o, _ := srv.db.GetObservation(id); o != nil {
+			t.Errorf("source %d still exists", id)
+		}
+	}
+
+	e := <-ch
+	var data map[string]any
+	json.Unmarshal([]byte(e.Data), &data)
+	if data["action"] != "merged" || len(data["merged_ids"].([]any)) != 2 {
+		t.Errorf("event data = %s, want merged with 2 ids", e.Data)
+	}
+
+	if ids := searchIDs(t, srv, "rotated"); len(ids) != 1 || ids[0] != target {
+		t.Errorf("hybrid search after merge = %v, want [%d]", ids, target)
+	}
+
+	tests := []struct {
+		name string
+		body map[string]any
+		code int
+	}{
+		{"missing sources", map[string]any{"target_id": target}, http.StatusBadRequest},
+		{"target in sources", map[string]any{"target_id": target, "source_ids": []int64{target}}, http.StatusBadRequest},
+		{"missing source", map[string]any{"target_id": target, "source_ids": []int64{dup}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rr := doRequest(t, srv, "POST", "/api/observations/merge", tt.body)
+		if rr.Code != tt.code {
+			t.Errorf("%s: status = %d, want %d", tt.name, rr.Code, tt.code)
+		}
+	}
+}
+
+func TestMCPUpdateAndForgetMemory(t *testing.T) {
+	srv := testServer(t)
+	id := createObservation(t, srv, "deploy", "Deploys run from the main branch")
+	mcpSrv := srv.newMCPServer()
+
+	call := func(name string, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := mcpSrv.GetTool(name).Handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: name, Arguments: args},
+		})
+		if err != nil {
+			t.Fatalf("%s handler: %v", name, err)
+		}
+		return result
+	}
+
+	result := call("update_memory", map[string]any{"id": float64(id), "text": "Deploys run from release tags"})
+	if result.IsError {
+		t.Fatalf("update_memory failed: %+v", result.Content)
+	}
+	if obs, _ := srv.db.GetObservation(id); obs.Text != "Deploys run from release tags" || obs.Title != "deploy" {
+		t.Errorf("observation after update_memory = %+v", obs)
+	}
+
+	result = call("forget_memory", map[string]any{"id": float64(id)})
+	if result.IsError {
+		t.Fatalf("forget_memory failed: %+v", result.Content)
+	}
+	if obs, _ := srv.db.GetObservation(id); obs != nil {
+		t.Error("observation still exists after forget_memory")
+	}
+
+	if result := call("forget_memory", map[string]any{"id": float64(id)}); !result.IsError {
+		t.Error("expected error forgetting a missing observation")
+	}
+	if result := call("update_memory", map[string]any{"text": "x"}); !result.IsError {
+		t.Error("expected error without id")
+	}
+}
@@ -24,6 +24,7 @@ import (
 // Server is the console HTTP server.
 type Server struct {
 	port          int
+	cfg           *config.Config
 	logger        *slog.Logger
 	db            *db.DB
 	search        *search.Orchestrator
@@ -31,11 +32,12 @@ type Server struct {
 	router        chi.Router
 	sse           *Broadcaster
 	stopRetention func() // stops background retention scheduler
+	stopReweight  func() // stops background vector index re-weighting
 }
 
-// New creates a console server on the given port. It opens (or creates) the
-// SQLite database and registers all routes.
-func New(port int, logger *slog.Logger) (*Server, error) {
+// New creates a console server on the configured port. It opens (or creates)
+// the SQLite database and registers all routes.
+func New(cfg *config.Config, logger *slog.Logger) (*Server, error) {
 	database, err := db.Open(config.DBPath(), logger)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
@@ -47,7 +49,8 @@ func New(port int, logger *slog.Logger) (*Server, error) {
 	r.Use(middleware.RealIP)
 
 	s := &Server{
-		port:   port,
+		port:   cfg.Port,
+		cfg:    cfg,
 		logger: logger,
 		db:     database,
 		router: r,
@@ -55,20 +58,27 @@ func New(port int, logger *slog.Logger) (*Server, error) {
 	}
 
 	// Initialize hybrid search (optional — falls back to FTS-only)
-	if orch, err := search.NewOrchestrator(database); err == nil {
-		s.search = orch
-	} else {
+	embedder, err := search.NewEmbedder(cfg.Embedding)
+	if err == nil {
+		var orch *search.Orchestrator
+		if orch, err = search.NewOrchestratorWithEmbedder(database, embedder); err == nil {
+			orch.SetRanking(search.RankingFromConfig(cfg.Search))
+			s.search = orch
+			s.stopReweight = orch.StartReweighter(cfg.Search.ReweightInterval)
+		}
+	}
+	if err != nil {
 		logger.Warn("hybrid search unavailable, using FTS only", "error", err)
 	}
 
 	// Start background retention scheduler
 	ret := search.NewRetention(database)
-	s.stopRetention = ret.StartScheduler(search.DefaultRetentionConfig())
+	s.stopRetention = ret.StartScheduler(search.RetentionFromConfig(cfg.Retention))
 
 	s.registerRoutes()
 
 	s.http = &http.Server{
-		Addr:              fmt.Sprintf(":%d", port),
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
 		Handler:           r,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
@@ -76,14 +86,18 @@ func New(port int, logger *slog.Logger) (*Server, error) {
 	return s, nil
 }
 
-// NewWithDB creates a console server with an externally provided database.
-// Useful for testing.
+// NewWithDB creates a console server with an externally provided database
+// and the default configuration. Useful for testing.
 func NewWithDB(port int, logger *slog.Logger, database *db.DB) *Server {
 	r := chi.NewRouter()
 	r.Use(middleware.Recoverer)
 
+	cfg := config.Defaults()
+	cfg.Port = port
+
 	s := &Server{
 		port:   port,
+		cfg:    cfg,
 		logger: logger,
 		db:     database,
 		router: r,
@@ -113,7 +127,10 @@ func (s *Server) registerRoutes() {
 
 		r.Post("/observations", s.handleCreateObservation)
 		r.Get("/observations/recent", s.handleRecentObservations)
+		r.Post("/observations/merge", s.handleMergeObservations)
 		r.Get("/observations/{id}", s.handleGetObservation)
+		r.Patch("/observations/{id}", s.handleUpdateObservation)
+		r.Delete("/observations/{id}", s.handleDeleteObservation)
 		r.Get("/observations/filters", s.handleObservationFilters)
 		r.Get("/observations/search", s.handleSearchObservations)
 		r.Get("/observations/hybrid-search", s.handleHybridSearch)
@@ -137,6 +154,9 @@ func (s *Server) registerRoutes() {
 		r.Patch("/plans/{id}/status", s.handleUpdatePlanStatus)
 
 		r.Get("/context/inject", s.handleContextInject)
+
+		r.Get("/export", s.handleExport)
+		r.Post("/import", s.handleImport)
 	})
 
 	// Mount MCP server at /mcp
@@ -201,6 +221,9 @@ func (s *Server) Stop() error {
 	if s.stopRetention != nil {
 		s.stopRetention()
 	}
+	if s.stopReweight != nil {
+		s.stopReweight()
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 	s.logger.Debug("console server stopping")
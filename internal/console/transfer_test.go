@@ -0,0 +1,52 @@
+package console
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExportImportEndpoints(t *testing.T) {
+	src := testServer(t)
+	doRequest(t, src, "POST", "/api/sessions", map[string]string{"id": "s1", "project": "alpha"})
+	createObservation(t, src, "webhook", "Webhook retries use exponential backoff")
+
+	rr := doRequest(t, src, "GET", "/api/export", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("export status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	exported := rr.Body.String()
+
+	dst := testServer(t)
+	req := httptest.NewRequest("POST", "/api/import", strings.NewReader(exported))
+	rr = httptest.NewRecorder()
+	dst.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("import status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	var stats map[string]map[string]int
+	json.NewDecoder(rr.Body).Decode(&stats)
+	if stats["observations"]["imported"] != 1 || stats["sessions"]["imported"] != 1 {
+		t.Errorf("import stats = %v", stats)
+	}
+
+	// Imported observations are indexed for hybrid search
+	if ids := searchIDs(t, dst, "backoff"); len(ids) != 1 {
+		t.Errorf("hybrid search after import = %v, want one result", ids)
+	}
+
+	if rr := doRequest(t, src, "GET", "/api/export?since=yesterday", nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid date status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+	req = httptest.NewRequest("POST", "/api/import", strings.NewReader("not json"))
+	rr = httptest.NewRecorder()
+	dst.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid import status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
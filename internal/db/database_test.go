@@ -199,6 +199,35 @@ func TestFilteredSearch(t *testing.T) {
 	}
 }
 
+func TestEscapeFTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"auth token":                 `"auth" OR "token"`,
+		"what's the auth-token fix?": `"what" OR "s" OR "the" OR "auth" OR "token" OR "fix"`,
+		`title:"login" NOT (x*)`:     `"title" OR "login" OR "NOT" OR "x"`,
+		"  ?! ":                      "",
+	}
+	for in, want := range tests {
+		if got := EscapeFTSQuery(in); got != want {
+			t.Errorf("EscapeFTSQuery(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	db := testDB(t)
+	db.InsertObservation(&Observation{SessionID: "s1", Title: "auth bug", Text: "Fixed authentication token expiry"})
+
+	// Raw natural-language queries are FTS5 syntax errors
+	if _, err := db.FilteredSearch(SearchFilter{Query: "token-expiry?"}); err == nil {
+		t.Error("expected FTS5 syntax error for raw query")
+	}
+	results, err := db.FilteredSearch(SearchFilter{Query: EscapeFTSQuery("how did we fix token-expiry?")})
+	if err != nil {
+		t.Fatalf("FilteredSearch: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("got %d results, want 1", len(results))
+	}
+}
+
 func TestFilteredSearchDateRange(t *testing.T) {
 	db := testDB(t)
 
@@ -319,6 +348,30 @@ func TestListActiveSessions(t *testing.T) {
 	}
 }
 
+func TestChildSessions(t *testing.T) {
+	db := testDB(t)
+
+	db.InsertSession(&Session{ID: "parent", Project: "p", Metadata: "{}"})
+	db.InsertSession(&Session{ID: "child-1", ParentID: "parent", Project: "p", Metadata: "{}"})
+	db.InsertSession(&Session{ID: "other", Project: "p", Metadata: "{}"})
+
+	got, err := db.GetSession("child-1")
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if got.ParentID != "parent" {
+		t.Errorf("ParentID = %q, want %q", got.ParentID, "parent")
+	}
+
+	children, err := db.ListChildSessions("parent")
+	if err != nil {
+		t.Fatalf("ListChildSessions: %v", err)
+	}
+	if len(children) != 1 || children[0].ID != "child-1" {
+		t.Errorf("children = %v, want [child-1]", children)
+	}
+}
+
 func TestSessionNotFound(t *testing.T) {
 	db := testDB(t)
 
@@ -391,6 +444,31 @@ func TestSummaryCRUD(t *testing.T) {
 	}
 }
 
+func TestSummarySections(t *testing.T) {
+	db := testDB(t)
+
+	db.InsertSession(&Session{ID: "sess-1", Metadata: "{}"})
+	db.InsertSummary(&Summary{
+		SessionID: "sess-1",
+		Text:      "Edited 2 files",
+		Sections: []SummarySection{
+			{Title: "Files Touched", Items: []string{"a.go", "b.go"}},
+		},
+	})
+
+	summaries, err := db.RecentSummaries(10)
+	if err != nil {
+		t.Fatalf("RecentSummaries: %v", err)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(summaries))
+	}
+	got := summaries[0].Sections
+	if len(got) != 1 || got[0].Title != "Files Touched" || len(got[0].Items) != 2 {
+		t.Errorf("Sections = %+v", got)
+	}
+}
+
 func TestSummaryWithProject(t *testing.T) {
 	db := testDB(t)
 
@@ -586,3 +664,62 @@ func TestTimelineAround(t *testing.T) {
 		t.Error("TimelineAround returned no results")
 	}
 }
+
+func TestUpdateAndDeleteObservation(t *testing.T) {
+	db := testDB(t)
+	id, _ := db.InsertObservation(&Observation{SessionID: "s1", Title: "old", Text: "original wording"})
+
+	obs, _ := db.GetObservation(id)
+	obs.Title, obs.Text = "new", "corrected wording"
+	if err := db.UpdateObservation(obs); err != nil {
+		t.Fatalf("UpdateObservation: %v", err)
+	}
+
+	// The FTS index follows the update
+	if results, _ := db.SearchObservations("original", 10); len(results) != 0 {
+		t.Errorf("old text still matches: %d results", len(results))
+	}
+	if results, _ := db.SearchObservations("corrected", 10); len(results) != 1 {
+		t.Errorf("new text matches %d results, want 1", len(results))
+	}
+
+	deleted, err := db.DeleteObservation(id)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteObservation = %v, %v; want true", deleted, err)
+	}
+	if results, _ := db.SearchObservations("corrected", 10); len(results) != 0 {
+		t.Errorf("deleted observation still matches: %d results", len(results))
+	}
+	if deleted, _ := db.DeleteObservation(id); deleted {
+		t.Error("second delete should report false")
+	}
+}
+
+func TestMergeObservations(t *testing.T) {
+	db := testDB(t)
+	target, _ := db.InsertObservation(&Observation{SessionID: "s1", Title: "a", Text: "first"})
+	source, _ := db.InsertObservation(&Observation{SessionID: "s1", Title: "b", Text: "second"})
+
+	// A missing source aborts the whole merge
+	obs, _ := db.GetObservation(target)
+	obs.Text = "merged"
+	if merged, err := db.MergeObservations(obs, []int64{source, 999}); err != nil || merged {
+		t.Fatalf("MergeObservations with missing source = %v, %v; want false", merged, err)
+	}
+	if o, _ := db.GetObservation(source); o == nil {
+		t.Fatal("source deleted by aborted merge")
+	}
+	if o, _ := db.GetObservation(target); o.Text != "first" {
+		t.Errorf("target text = %q after aborted merge, want first", o.Text)
+	}
+
+	if merged, err := db.MergeObservations(obs, []int64{source}); err != nil || !merged {
+		t.Fatalf("MergeObservations = %v, %v; want true", merged, err)
+	}
+	if o, _ := db.GetObservation(source); o != nil {
+		t.Error("source still exists after merge")
+	}
+	if o, _ := db.GetObservation(target); o.Text != "merged" {
+		t.Errorf("target text = %q, want merged", o.Text)
+	}
+}
@@ -99,6 +99,13 @@ var migrations = []string{
 	`CREATE INDEX IF NOT EXISTS idx_summaries_session ON summaries(session_id)`,
 	`CREATE INDEX IF NOT EXISTS idx_plans_session ON plans(session_id)`,
 	`CREATE INDEX IF NOT EXISTS idx_plans_status ON plans(status)`,
+
+	// 18: parent session link for Endless Mode continuations
+	`ALTER TABLE sessions ADD COLUMN parent_id TEXT NOT NULL DEFAULT ''`,
+	`CREATE INDEX IF NOT EXISTS idx_sessions_parent ON sessions(parent_id)`,
+
+	// 20: structured sections for session summaries (JSON array)
+	`ALTER TABLE summaries ADD COLUMN sections TEXT NOT NULL DEFAULT '[]'`,
 }
 
 // migrate runs all pending migrations in order.
@@ -3,7 +3,9 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // Observation represents a single discovery, change, or decision.
@@ -31,6 +33,69 @@ func (db *DB) InsertObservation(o *Observation) (int64, error) {
 	return res.LastInsertId()
 }
 
+// UpdateObservation overwrites the type, title, text, project and metadata of
+// an existing observation. The FTS index is kept in sync by triggers.
+func (db *DB) UpdateObservation(o *Observation) error {
+	_, err := db.conn.Exec(
+		`UPDATE observations SET type = ?, title = ?, text = ?, project = ?, metadata = ?
+		 WHERE id = ?`,
+		o.Type, o.Title, o.Text, o.Project, o.Metadata, o.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update observation %d: %w", o.ID, err)
+	}
+	return nil
+}
+
+// DeleteObservation removes an observation. Returns false if it didn't exist.
+func (db *DB) DeleteObservation(id int64) (bool, error) {
+	res, err := db.conn.Exec(`DELETE FROM observations WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete observation %d: %w", id, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// MergeObservations folds the source observations into target in a single
+// transaction: target is updated to the given contents and the sources are
+// deleted. Returns false if target or any source doesn't exist, in which case
+// nothing is changed.
+func (db *DB) MergeObservations(target *Observation, sourceIDs []int64) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("begin merge: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE observations SET type = ?, title = ?, text = ?, project = ?, metadata = ?
+		 WHERE id = ?`,
+		target.Type, target.Title, target.Text, target.Project, target.Metadata, target.ID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("update merge target %d: %w", target.ID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	for _, id := range sourceIDs {
+		res, err := tx.Exec(`DELETE FROM observations WHERE id = ?`, id)
+		if err != nil {
+			return false, fmt.Errorf("delete merged observation %d: %w", id, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return false, nil
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit merge: %w", err)
+	}
+	return true, nil
+}
+
 // GetObservation retrieves an observation by ID.
 func (db *DB) GetObservation(id int64) (*Observation, error) {
 	o := &Observation{}
@@ -186,6 +251,20 @@ func (db *DB) ListBySessionID(sessionID string, limit int) ([]*Observation, erro
 	return results, rows.Err()
 }
 
+// EscapeFTSQuery turns free text into an FTS5 query that matches any of its
+// words. Each word is quoted, so operators, column filters and punctuation in
+// natural-language queries are treated as plain text. Returns "" when the
+// text has no words.
+func EscapeFTSQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for i, w := range words {
+		words[i] = `"` + w + `"`
+	}
+	return strings.Join(words, " OR ")
+}
+
 // SearchFilter defines parameters for filtered full-text search.
 type SearchFilter struct {
 	Query     string
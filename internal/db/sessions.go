@@ -9,6 +9,7 @@ import (
 // Session represents a Claude Code session.
 type Session struct {
 	ID           string
+	ParentID     string // previous session in an Endless Mode chain, if any
 	Project      string
 	StartedAt    time.Time
 	EndedAt      *time.Time
@@ -19,8 +20,8 @@ type Session struct {
 // InsertSession creates a new session record.
 func (db *DB) InsertSession(s *Session) error {
 	_, err := db.conn.Exec(
-		`INSERT INTO sessions (id, project, metadata) VALUES (?, ?, ?)`,
-		s.ID, s.Project, s.Metadata,
+		`INSERT INTO sessions (id, parent_id, project, metadata) VALUES (?, ?, ?, ?)`,
+		s.ID, s.ParentID, s.Project, s.Metadata,
 	)
 	if err != nil {
 		return fmt.Errorf("insert session: %w", err)
@@ -34,9 +35,9 @@ func (db *DB) GetSession(id string) (*Session, error) {
 	var startedAt string
 	var endedAt sql.NullString
 	err := db.conn.QueryRow(
-		`SELECT id, project, started_at, ended_at, message_count, metadata
+		`SELECT id, parent_id, project, started_at, ended_at, message_count, metadata
 		 FROM sessions WHERE id = ?`, id,
-	).Scan(&s.ID, &s.Project, &startedAt, &endedAt, &s.MessageCount, &s.Metadata)
+	).Scan(&s.ID, &s.ParentID, &s.Project, &startedAt, &endedAt, &s.MessageCount, &s.Metadata)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -92,7 +93,7 @@ func (db *DB) CleanupStaleSessions(maxAgeHours int) (int, error) {
 // ListActiveSessions returns sessions that have not ended.
 func (db *DB) ListActiveSessions() ([]*Session, error) {
 	rows, err := db.conn.Query(
-		`SELECT id, project, started_at, ended_at, message_count, metadata
+		`SELECT id, parent_id, project, started_at, ended_at, message_count, metadata
 		 FROM sessions WHERE ended_at IS NULL ORDER BY started_at DESC`,
 	)
 	if err != nil {
@@ -105,7 +106,7 @@ func (db *DB) ListActiveSessions() ([]*Session, error) {
 		s := &Session{}
 		var startedAt string
 		var endedAt sql.NullString
-		if err := rows.Scan(&s.ID, &s.Project, &startedAt, &endedAt, &s.MessageCount, &s.Metadata); err != nil {
+		if err := rows.Scan(&s.ID, &s.ParentID, &s.Project, &startedAt, &endedAt, &s.MessageCount, &s.Metadata); err != nil {
 			return nil, fmt.Errorf("scan session: %w", err)
 		}
 		s.StartedAt, _ = time.Parse("2006-01-02 15:04:05", startedAt)
@@ -121,7 +122,7 @@ func (db *DB) ListAllSessions(limit int) ([]*Session, error) {
 	}
 
 	rows, err := db.conn.Query(
-		`SELECT id, project, started_at, ended_at, message_count, metadata
+		`SELECT id, parent_id, project, started_at, ended_at, message_count, metadata
 		 FROM sessions ORDER BY started_at DESC LIMIT ?`, limit,
 	)
 	if err != nil {
@@ -134,7 +135,37 @@ func (db *DB) ListAllSessions(limit int) ([]*Session, error) {
 		s := &Session{}
 		var startedAt string
 		var endedAt sql.NullString
-		if err := rows.Scan(&s.ID, &s.Project, &startedAt, &endedAt, &s.MessageCount, &s.Metadata); err != nil {
+		if err := rows.Scan(&s.ID, &s.ParentID, &s.Project, &startedAt, &endedAt, &s.MessageCount, &s.Metadata); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		s.StartedAt, _ = time.Parse("2006-01-02 15:04:05", startedAt)
+		if endedAt.Valid {
+			t, _ := time.Parse("2006-01-02 15:04:05", endedAt.String)
+			s.EndedAt = &t
+		}
+		results = append(results, s)
+	}
+	return results, rows.Err()
+}
+
+// ListChildSessions returns the sessions continued from the given parent,
+// ordered oldest first.
+func (db *DB) ListChildSessions(parentID string) ([]*Session, error) {
+	rows, err := db.conn.Query(
+		`SELECT id, parent_id, project, started_at, ended_at, message_count, metadata
+		 FROM sessions WHERE parent_id = ? ORDER BY started_at, id`, parentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list child sessions of %s: %w", parentID, err)
+	}
+	defer rows.Close()
+
+	var results []*Session
+	for rows.Next() {
+		s := &Session{}
+		var startedAt string
+		var endedAt sql.NullString
+		if err := rows.Scan(&s.ID, &s.ParentID, &s.Project, &startedAt, &endedAt, &s.MessageCount, &s.Metadata); err != nil {
 			return nil, fmt.Errorf("scan session: %w", err)
 		}
 		s.StartedAt, _ = time.Parse("2006-01-02 15:04:05", startedAt)
@@ -1,24 +1,43 @@
 package db
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 )
 
-// Summary represents a session-end summary.
+// Summary represents a session-end summary. Text holds the rendered summary
+// (headline on the first line); Sections holds the same content in structured
+// form for compact context injection.
 type Summary struct {
 	ID        int64
 	SessionID string
 	Text      string
+	Sections  []SummarySection
 	Project   string
 	CreatedAt time.Time
 }
 
+// SummarySection is one titled block of a structured session summary.
+type SummarySection struct {
+	Title string   `json:"title"`
+	Items []string `json:"items"`
+}
+
 // InsertSummary stores a new session summary.
 func (db *DB) InsertSummary(s *Summary) (int64, error) {
+	sections := s.Sections
+	if sections == nil {
+		sections = []SummarySection{}
+	}
+	sectionsJSON, err := json.Marshal(sections)
+	if err != nil {
+		return 0, fmt.Errorf("marshal summary sections: %w", err)
+	}
+
 	res, err := db.conn.Exec(
-		`INSERT INTO summaries (session_id, text) VALUES (?, ?)`,
-		s.SessionID, s.Text,
+		`INSERT INTO summaries (session_id, text, sections) VALUES (?, ?, ?)`,
+		s.SessionID, s.Text, string(sectionsJSON),
 	)
 	if err != nil {
 		return 0, fmt.Errorf("insert summary: %w", err)
@@ -32,7 +51,7 @@ func (db *DB) RecentSummaries(limit int) ([]*Summary, error) {
 		limit = 10
 	}
 	rows, err := db.conn.Query(
-		`SELECT s.id, s.session_id, s.text, s.created_at, COALESCE(sess.project, '')
+		`SELECT s.id, s.session_id, s.text, s.sections, s.created_at, COALESCE(sess.project, '')
 		 FROM summaries s
 		 LEFT JOIN sessions sess ON s.session_id = sess.id
 		 ORDER BY s.created_at DESC LIMIT ?`,
@@ -46,10 +65,11 @@ func (db *DB) RecentSummaries(limit int) ([]*Summary, error) {
 	var results []*Summary
 	for rows.Next() {
 		s := &Summary{}
-		var createdAt string
-		if err := rows.Scan(&s.ID, &s.SessionID, &s.Text, &createdAt, &s.Project); err != nil {
+		var createdAt, sections string
+		if err := rows.Scan(&s.ID, &s.SessionID, &s.Text, &sections, &createdAt, &s.Project); err != nil {
 			return nil, fmt.Errorf("scan summary: %w", err)
 		}
+		json.Unmarshal([]byte(sections), &s.Sections) //nolint:errcheck
 		s.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
 		results = append(results, s)
 	}
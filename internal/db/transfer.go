@@ -0,0 +1,562 @@
+package db
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Memory export format. An export is JSON Lines: a header line followed by
+// one line per session, observation, summary and plan, each tagged with its
+// kind. Timestamps are stored as SQLite datetime strings (UTC).
+const (
+	ExportFormat  = "icc-memory"
+	ExportVersion = 1
+)
+
+// Record kinds in an export.
+const (
+	kindHeader      = "header"
+	kindSession     = "session"
+	kindObservation = "observation"
+	kindSummary     = "summary"
+	kindPlan        = "plan"
+)
+
+type exportHeader struct {
+	Kind       string `json:"kind"`
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	ExportedAt string `json:"exported_at"`
+}
+
+type exportSession struct {
+	Kind         string `json:"kind"`
+	ID           string `json:"id"`
+	ParentID     string `json:"parent_id,omitempty"`
+	Project      string `json:"project"`
+	StartedAt    string `json:"started_at"`
+	EndedAt      string `json:"ended_at,omitempty"`
+	MessageCount int    `json:"message_count"`
+	Metadata     string `json:"metadata"`
+}
+
+type exportObservation struct {
+	Kind      string `json:"kind"`
+	ID        int64  `json:"id"`
+	SessionID string `json:"session_id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Text      string `json:"text"`
+	Project   string `json:"project"`
+	Metadata  string `json:"metadata"`
+	CreatedAt string `json:"created_at"`
+}
+
+type exportSummary struct {
+	Kind      string           `json:"kind"`
+	ID        int64            `json:"id"`
+	SessionID string           `json:"session_id"`
+	Text      string           `json:"text"`
+	Sections  []SummarySection `json:"sections"`
+	CreatedAt string           `json:"created_at"`
+}
+
+type exportPlan struct {
+	Kind      string `json:"kind"`
+	ID        int64  `json:"id"`
+	Path      string `json:"path"`
+	SessionID string `json:"session_id"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ExportFilter limits an export to a project and/or date range.
+type ExportFilter struct {
+	Project   string
+	DateStart string // YYYY-MM-DD, inclusive
+	DateEnd   string // YYYY-MM-DD, inclusive
+}
+
+// Validate checks that the dates are YYYY-MM-DD.
+func (f ExportFilter) Validate() error {
+	for _, d := range []string{f.DateStart, f.DateEnd} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("invalid date %q, want YYYY-MM-DD", d)
+		}
+	}
+	return nil
+}
+
+// ExportStats counts the records written by Export.
+type ExportStats struct {
+	Sessions     int `json:"sessions"`
+	Observations int `json:"observations"`
+	Summaries    int `json:"summaries"`
+	Plans        int `json:"plans"`
+}
+
+// Export writes observations, summaries and plans matching f as JSON Lines,
+// together with every session they belong to. Summaries and plans match a
+// project filter through their session.
+func (db *DB) Export(w io.Writer, f ExportFilter) (*ExportStats, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin export: %w", err)
+	}
+	defer tx.Rollback()
+
+	enc := json.NewEncoder(w)
+	stats := &ExportStats{}
+
+	if err := enc.Encode(exportHeader{
+		Kind:       kindHeader,
+		Format:     ExportFormat,
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC().Format("2006-01-02 15:04:05"),
+	}); err != nil {
+		return nil, fmt.Errorf("write export header: %w", err)
+	}
+
+	observations, err := exportObservations(tx, f)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := exportSummaries(tx, f)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := exportPlans(tx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIDs := make(map[string]bool)
+	for _, o := range observations {
+		sessionIDs[o.SessionID] = true
+	}
+	for _, s := range summaries {
+		sessionIDs[s.SessionID] = true
+	}
+	for _, p := range plans {
+		sessionIDs[p.SessionID] = true
+	}
+	sessions, err := exportSessions(tx, f, sessionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range sessions {
+		if err := enc.Encode(s); err != nil {
+			return nil, fmt.Errorf("write session: %w", err)
+		}
+		stats.Sessions++
+	}
+	for _, o := range observations {
+		if err := enc.Encode(o); err != nil {
+			return nil, fmt.Errorf("write observation: %w", err)
+		}
+		stats.Observations++
+	}
+	for _, s := range summaries {
+		if err := enc.Encode(s); err != nil {
+			return nil, fmt.Errorf("write summary: %w", err)
+		}
+		stats.Summaries++
+	}
+	for _, p := range plans {
+		if err := enc.Encode(p); err != nil {
+			return nil, fmt.Errorf("write plan: %w", err)
+		}
+		stats.Plans++
+	}
+	return stats, nil
+}
+
+// dateClause appends created-at range conditions for column to query.
+func dateClause(query string, args []any, column string, f ExportFilter) (string, []any) {
+	if f.DateStart != "" {
+		query += " AND " + column + " >= ?"
+		args = append(args, f.DateStart)
+	}
+	if f.DateEnd != "" {
+		query += " AND " + column + " < date(?, '+1 day')"
+		args = append(args, f.DateEnd)
+	}
+	return query, args
+}
+
+func exportObservations(tx *sql.Tx, f ExportFilter) ([]exportObservation, error) {
+	query := `SELECT id, session_id, type, title, text, project, metadata, created_at
+		FROM observations WHERE 1 = 1`
+	var args []any
+	if f.Project != "" {
+		query += " AND project = ?"
+		args = append(args, f.Project)
+	}
+	query, args = dateClause(query, args, "created_at", f)
+
+	rows, err := tx.Query(query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("export observations: %w", err)
+	}
+	defer rows.Close()
+
+	var results []exportObservation
+	for rows.Next() {
+		o := exportObservation{Kind: kindObservation}
+		if err := rows.Scan(&o.ID, &o.SessionID, &o.Type, &o.Title, &o.Text, &o.Project, &o.Metadata, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan observation: %w", err)
+		}
+		results = append(results, o)
+	}
+	return results, rows.Err()
+}
+
+func exportSummaries(tx *sql.Tx, f ExportFilter) ([]exportSummary, error) {
+	query := `SELECT s.id, s.session_id, s.text, s.sections, s.created_at
+		FROM summaries s LEFT JOIN sessions sess ON s.session_id = sess.id WHERE 1 = 1`
+	var args []any
+	if f.Project != "" {
+		query += " AND sess.project = ?"
+		args = append(args, f.Project)
+	}
+	query, args = dateClause(query, args, "s.created_at", f)
+
+	rows, err := tx.Query(query+" ORDER BY s.id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("export summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var results []exportSummary
+	for rows.Next() {
+		s := exportSummary{Kind: kindSummary}
+		var sections string
+		if err := rows.Scan(&s.ID, &s.SessionID, &s.Text, &sections, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan summary: %w", err)
+		}
+		json.Unmarshal([]byte(sections), &s.Sections)
+		results = append(results, s)
+	}
+	return results, rows.Err()
+}
+
+func exportPlans(tx *sql.Tx, f ExportFilter) ([]exportPlan, error) {
+	query := `SELECT p.id, p.path, p.session_id, p.status, p.created_at, p.updated_at
+		FROM plans p LEFT JOIN sessions sess ON p.session_id = sess.id WHERE 1 = 1`
+	var args []any
+	if f.Project != "" {
+		query += " AND sess.project = ?"
+		args = append(args, f.Project)
+	}
+	query, args = dateClause(query, args, "p.created_at", f)
+
+	rows, err := tx.Query(query+" ORDER BY p.id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("export plans: %w", err)
+	}
+	defer rows.Close()
+
+	var results []exportPlan
+	for rows.Next() {
+		p := exportPlan{Kind: kindPlan}
+		if err := rows.Scan(&p.ID, &p.Path, &p.SessionID, &p.Status, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan plan: %w", err)
+		}
+		results = append(results, p)
+	}
+	return results, rows.Err()
+}
+
+// exportSessions returns sessions matching f plus those listed in include.
+func exportSessions(tx *sql.Tx, f ExportFilter, include map[string]bool) ([]exportSession, error) {
+	rows, err := tx.Query(
+		`SELECT id, parent_id, project, started_at, COALESCE(ended_at, ''), message_count, metadata
+		 FROM sessions ORDER BY started_at, id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var results []exportSession
+	for rows.Next() {
+		s := exportSession{Kind: kindSession}
+		if err := rows.Scan(&s.ID, &s.ParentID, &s.Project, &s.StartedAt, &s.EndedAt, &s.MessageCount, &s.Metadata); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		matches := (f.Project == "" || s.Project == f.Project) &&
+			(f.DateStart == "" || s.StartedAt >= f.DateStart) &&
+			(f.DateEnd == "" || s.StartedAt[:min(len(s.StartedAt), 10)] <= f.DateEnd)
+		if matches || include[s.ID] {
+			results = append(results, s)
+		}
+	}
+	return results, rows.Err()
+}
+
+// ImportCounts counts the records of one kind read by Import.
+type ImportCounts struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"` // Already present
+}
+
+// ImportStats summarizes an import.
+type ImportStats struct {
+	Sessions     ImportCounts `json:"sessions"`
+	Observations ImportCounts `json:"observations"`
+	Summaries    ImportCounts `json:"summaries"`
+	Plans        ImportCounts `json:"plans"`
+}
+
+// maxImportLine bounds the size of a single export line.
+const maxImportLine = 16 << 20
+
+// Import reads an export produced by Export in a single transaction.
+// Observations, summaries and plans get new IDs. Records that already exist
+// are skipped, so importing the same export twice is harmless: sessions
+// match by ID, observations by session, type, title, text and creation time,
+// summaries by session, text and creation time, and plans by path and
+// session.
+func (db *DB) Import(r io.Reader) (*ImportStats, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	stats := &ImportStats{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLine)
+
+	line, sawHeader := 0, false
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+
+		var rec struct {
+			Kind    string `json:"kind"`
+			Format  string `json:"format"`
+			Version int    `json:"version"`
+		}
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !sawHeader && rec.Kind != kindHeader {
+			return nil, fmt.Errorf("line %d: missing %s header", line, ExportFormat)
+		}
+
+		switch rec.Kind {
+		case kindHeader:
+			if rec.Format != ExportFormat || rec.Version > ExportVersion {
+				return nil, fmt.Errorf("line %d: unsupported export format %s v%d", line, rec.Format, rec.Version)
+			}
+			sawHeader = true
+		case kindSession:
+			var s exportSession
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if err := importSession(tx, &s, &stats.Sessions); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		case kindObservation:
+			var o exportObservation
+			if err := json.Unmarshal(data, &o); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if err := importObservation(tx, &o, &stats.Observations); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		case kindSummary:
+			var s exportSummary
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if err := importSummary(tx, &s, &stats.Summaries); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		case kindPlan:
+			var p exportPlan
+			if err := json.Unmarshal(data, &p); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if err := importPlan(tx, &p, &stats.Plans); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unknown record kind %q", line, rec.Kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read import: %w", err)
+	}
+	if !sawHeader {
+		return nil, fmt.Errorf("empty import")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit import: %w", err)
+	}
+	return stats, nil
+}
+
+// exists reports whether query returns a row.
+func exists(tx *sql.Tx, query string, args ...any) (bool, error) {
+	var one int
+	err := tx.QueryRow(query, args...).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// timestampOrNow substitutes the current time for a missing timestamp.
+func timestampOrNow(ts string) string {
+	if ts == "" {
+		return time.Now().UTC().Format("2006-01-02 15:04:05")
+	}
+	return ts
+}
+
+func importSession(tx *sql.Tx, s *exportSession, counts *ImportCounts) error {
+	if s.ID == "" {
+		return fmt.Errorf("session without id")
+	}
+	found, err := exists(tx, `SELECT 1 FROM sessions WHERE id = ?`, s.ID)
+	if err != nil {
+		return fmt.Errorf("look up session %s: %w", s.ID, err)
+	}
+	if found {
+		counts.Skipped++
+		return nil
+	}
+
+	var endedAt any
+	if s.EndedAt != "" {
+		endedAt = s.EndedAt
+	}
+	if s.Metadata == "" {
+		s.Metadata = "{}"
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO sessions (id, parent_id, project, started_at, ended_at, message_count, metadata)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		s.ID, s.ParentID, s.Project, timestampOrNow(s.StartedAt), endedAt, s.MessageCount, s.Metadata,
+	); err != nil {
+		return fmt.Errorf("insert session %s: %w", s.ID, err)
+	}
+	counts.Imported++
+	return nil
+}
+
+func importObservation(tx *sql.Tx, o *exportObservation, counts *ImportCounts) error {
+	if o.Text == "" {
+		return fmt.Errorf("observation %d without text", o.ID)
+	}
+	if o.Type == "" {
+		o.Type = "discovery"
+	}
+	if o.Metadata == "" {
+		o.Metadata = "{}"
+	}
+	o.CreatedAt = timestampOrNow(o.CreatedAt)
+
+	found, err := exists(tx,
+		`SELECT 1 FROM observations
+		 WHERE session_id = ? AND type = ? AND title = ? AND text = ? AND created_at = ?`,
+		o.SessionID, o.Type, o.Title, o.Text, o.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("look up observation %d: %w", o.ID, err)
+	}
+	if found {
+		counts.Skipped++
+		return nil
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO observations (session_id, type, title, text, project, metadata, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		o.SessionID, o.Type, o.Title, o.Text, o.Project, o.Metadata, o.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("insert observation %d: %w", o.ID, err)
+	}
+	counts.Imported++
+	return nil
+}
+
+func importSummary(tx *sql.Tx, s *exportSummary, counts *ImportCounts) error {
+	s.CreatedAt = timestampOrNow(s.CreatedAt)
+	found, err := exists(tx,
+		`SELECT 1 FROM summaries WHERE session_id = ? AND text = ? AND created_at = ?`,
+		s.SessionID, s.Text, s.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("look up summary %d: %w", s.ID, err)
+	}
+	if found {
+		counts.Skipped++
+		return nil
+	}
+
+	sections := s.Sections
+	if sections == nil {
+		sections = []SummarySection{}
+	}
+	sectionsJSON, err := json.Marshal(sections)
+	if err != nil {
+		return fmt.Errorf("marshal summary sections: %w", err)
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO summaries (session_id, text, sections, created_at) VALUES (?, ?, ?, ?)`,
+		s.SessionID, s.Text, string(sectionsJSON), s.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("insert summary %d: %w", s.ID, err)
+	}
+	counts.Imported++
+	return nil
+}
+
+func importPlan(tx *sql.Tx, p *exportPlan, counts *ImportCounts) error {
+	if p.Path == "" {
+		return fmt.Errorf("plan %d without path", p.ID)
+	}
+	found, err := exists(tx, `SELECT 1 FROM plans WHERE path = ? AND session_id = ?`, p.Path, p.SessionID)
+	if err != nil {
+		return fmt.Errorf("look up plan %d: %w", p.ID, err)
+	}
+	if found {
+		counts.Skipped++
+		return nil
+	}
+
+	if p.Status == "" {
+		p.Status = "PENDING"
+	}
+	createdAt := timestampOrNow(p.CreatedAt)
+	updatedAt := p.UpdatedAt
+	if updatedAt == "" {
+		updatedAt = createdAt
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO plans (path, session_id, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		p.Path, p.SessionID, p.Status, createdAt, updatedAt,
+	); err != nil {
+		return fmt.Errorf("insert plan %d: %w", p.ID, err)
+	}
+	counts.Imported++
+	return nil
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func seedTransferData(t *testing.T, db *DB) {
+	t.Helper()
+	for _, s := range []*Session{
+		{ID: "sess-a", Project: "alpha"},
+		{ID: "sess-b", Project: "beta"},
+	} {
+		if err := db.InsertSession(s); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+	db.InsertObservation(&Observation{SessionID: "sess-a", Type: "bugfix", Title: "a1", Text: "alpha fix", Project: "alpha"})
+	db.InsertObservation(&Observation{SessionID: "sess-a", Type: "decision", Title: "a2", Text: "alpha decision", Project: "alpha"})
+	db.InsertObservation(&Observation{SessionID: "sess-b", Type: "feature", Title: "b1", Text: "beta feature", Project: "beta"})
+	db.InsertSummary(&Summary{SessionID: "sess-a", Text: "alpha summary", Sections: []SummarySection{{Title: "Files Touched", Items: []string{"a.go"}}}})
+	db.InsertSummary(&Summary{SessionID: "sess-b", Text: "beta summary"})
+	db.InsertPlan(&Plan{Path: "docs/plans/alpha.md", SessionID: "sess-a", Status: "VERIFIED"})
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := testDB(t)
+	seedTransferData(t, src)
+
+	var buf bytes.Buffer
+	stats, err := src.Export(&buf, ExportFilter{})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if *stats != (ExportStats{Sessions: 2, Observations: 3, Summaries: 2, Plans: 1}) {
+		t.Errorf("export stats = %+v", stats)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 9 {
+		t.Errorf("export has %d lines, want 9 (header + 8 records)", lines)
+	}
+
+	// Give the destination an existing observation so imported IDs are remapped
+	dst := testDB(t)
+	localID, _ := dst.InsertObservation(&Observation{SessionID: "local", Text: "local note"})
+
+	exported := buf.String()
+	imported, err := dst.Import(strings.NewReader(exported))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want := ImportStats{
+		Sessions:     ImportCounts{Imported: 2},
+		Observations: ImportCounts{Imported: 3},
+		Summaries:    ImportCounts{Imported: 2},
+		Plans:        ImportCounts{Imported: 1},
+	}
+	if *imported != want {
+		t.Errorf("import stats = %+v, want %+v", imported, want)
+	}
+
+	obs, _ := dst.ListBySessionID("sess-a", 10)
+	if len(obs) != 2 {
+		t.Fatalf("imported %d observations for sess-a, want 2", len(obs))
+	}
+	for _, o := range obs {
+		if o.ID <= localID {
+			t.Errorf("imported observation kept a clashing ID %d", o.ID)
+		}
+	}
+	if results, _ := dst.SearchObservations("decision", 10); len(results) != 1 {
+		t.Errorf("imported observations not in FTS index")
+	}
+	orig, _ := src.GetObservation(1)
+	if obs[0].CreatedAt != orig.CreatedAt {
+		t.Errorf("created_at = %v, want %v", obs[0].CreatedAt, orig.CreatedAt)
+	}
+	summaries, _ := dst.RecentSummaries(10)
+	var sections int
+	for _, s := range summaries {
+		sections += len(s.Sections)
+	}
+	if len(summaries) != 2 || sections != 1 {
+		t.Errorf("imported summaries = %d with %d sections, want 2 with 1", len(summaries), sections)
+	}
+	if p, _ := dst.GetPlanByPath("docs/plans/alpha.md"); p == nil || p.Status != "VERIFIED" {
+		t.Errorf("imported plan = %+v", p)
+	}
+
+	// Importing again is a no-op
+	again, err := dst.Import(strings.NewReader(exported))
+	if err != nil {
+		t.Fatalf("second Import: %v", err)
+	}
+	want = ImportStats{
+		Sessions:     ImportCounts{Skipped: 2},
+		Observations: ImportCounts{Skipped: 3},
+		Summaries:    ImportCounts{Skipped: 2},
+		Plans:        ImportCounts{Skipped: 1},
+	}
+	if *again != want {
+		t.Errorf("second import stats = %+v, want %+v", again, want)
+	}
+}
+
+func TestExportFilter(t *testing.T) {
+	db := testDB(t)
+	seedTransferData(t, db)
+
+	var buf bytes.Buffer
+	stats, err := db.Export(&buf, ExportFilter{Project: "alpha"})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if *stats != (ExportStats{Sessions: 1, Observations: 2, Summaries: 1, Plans: 1}) {
+		t.Errorf("project export stats = %+v", stats)
+	}
+	if strings.Contains(buf.String(), "beta") {
+		t.Error("project export contains other projects")
+	}
+
+	stats, err = db.Export(&bytes.Buffer{}, ExportFilter{DateEnd: "2000-01-01"})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if *stats != (ExportStats{}) {
+		t.Errorf("date-filtered export stats = %+v, want nothing", stats)
+	}
+}
+
+func TestImportRejectsInvalidInput(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"missing header": `{"kind":"observation","text":"x"}`,
+		"wrong format":   `{"kind":"header","format":"other","version":1}`,
+		"unknown kind":   `{"kind":"header","format":"icc-memory","version":1}` + "\n" + `{"kind":"prompt"}`,
+		"bad json":       `{"kind":"header","format":"icc-memory","version":1}` + "\n{",
+	}
+	for name, input := range tests {
+		db := testDB(t)
+		if _, err := db.Import(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// A failing line rolls back the whole import
+	db := testDB(t)
+	input := `{"kind":"header","format":"icc-memory","version":1}
+{"kind":"observation","session_id":"s","text":"kept?"}
+{"kind":"observation","session_id":"s","text":""}`
+	if _, err := db.Import(strings.NewReader(input)); err == nil {
+		t.Fatal("expected error for observation without text")
+	}
+	if results, _ := db.SearchObservations("kept", 10); len(results) != 0 {
+		t.Error("failed import left observations behind")
+	}
+}
@@ -2,17 +2,32 @@ package hooks
 
 import (
 	"encoding/json"
+	"fmt"
 	"os/exec"
+	"path"
+	"slices"
 	"strings"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/shellparse"
 )
 
 func init() {
 	Register("branch-guard", branchGuardHook)
+	RegisterDryRun("branch-guard", func(input *Input) (*Decision, error) {
+		var bash BashToolInput
+		if input.ToolName != "Bash" || json.Unmarshal(input.ToolInput, &bash) != nil {
+			return nil, nil
+		}
+		return branchGuardDecision(bash.Command, input.Cwd, loadBranchPolicy(input), currentBranch), nil
+	})
 }
 
 // branchGuardHook enforces the branch-based PR workflow. It handles two events:
-//   - SessionStart: injects a reminder about the branching workflow if on main
-//   - PreToolUse (Bash): blocks git commit/push operations when on the main branch
+//   - SessionStart: injects the protected-branch policy, with a warning if the
+//     current branch is protected
+//   - PreToolUse (Bash): blocks or asks about git operations the policy
+//     restricts, such as commits and pushes to main
 func branchGuardHook(input *Input) error {
 	switch input.HookEventName {
 	case "SessionStart":
@@ -26,17 +41,25 @@ func branchGuardHook(input *Input) error {
 }
 
 func branchGuardSessionStart(input *Input) error {
-	branch := currentBranch(input.Cwd)
-	if branch == "main" || branch == "master" {
-		WriteOutput(&Output{
-			HookSpecific: &HookSpecificOuput{
-				HookEventName:     "SessionStart",
-				AdditionalContext: "You are currently on the `" + branch + "` branch. Do NOT commit or push directly to this branch. Create a feature branch first (e.g., `git checkout -b feat/my-feature`), then open a PR when ready.",
-			},
-		})
+	policy := loadBranchPolicy(input)
+	if len(policy.Protected) == 0 {
+		ExitOK()
 		return nil
 	}
-	ExitOK()
+
+	var msg strings.Builder
+	branch := currentBranch(input.Cwd)
+	if action, _ := policy.action(branch, "commit"); action == "deny" {
+		msg.WriteString("You are currently on the `" + branch + "` branch. Do NOT commit or push directly to this branch. Create a feature branch first (e.g., `git checkout -b feat/my-feature`), then open a PR when ready.\n\n")
+	}
+	msg.WriteString(policy.describe())
+
+	WriteOutput(&Output{
+		HookSpecific: &HookSpecificOuput{
+			HookEventName:     "SessionStart",
+			AdditionalContext: msg.String(),
+		},
+	})
 	return nil
 }
 
@@ -52,117 +75,289 @@ func branchGuardPreToolUse(input *Input) error {
 		return nil
 	}
 
-	cmd := strings.TrimSpace(bash.Command)
-	if !isGitCommand(cmd) {
+	d := branchGuardDecision(bash.Command, input.Cwd, loadBranchPolicy(input), currentBranch)
+	switch {
+	case d == nil:
 		ExitOK()
-		return nil
+	case d.Action == "deny":
+		BlockWithError(d.Reason)
+	default:
+		WriteOutput(d.output())
 	}
+	return nil
+}
 
-	// Check for push targeting main regardless of current branch
-	if isPushToMain(cmd) {
-		BlockWithError("Blocked: Do not push directly to main. Push your feature branch and open a PR instead.\n\nExample:\n  git push -u origin feat/my-feature\n  gh pr create")
-		return nil
-	}
+// branchPolicy is the effective protected-branch policy.
+type branchPolicy struct {
+	Protected []config.BranchRule
+}
 
-	// For commit and push-without-explicit-target, check current branch
-	branch := currentBranch(input.Cwd)
-	if branch != "main" && branch != "master" {
-		ExitOK()
-		return nil
+// loadBranchPolicy returns the configured policy, with the remote's default
+// branch added when detection is enabled and no global rule covers it yet.
+func loadBranchPolicy(input *Input) *branchPolicy {
+	cfg := loadConfig(input).BranchPolicy
+	p := &branchPolicy{Protected: cfg.Protected}
+	if cfg.DetectDefault {
+		if def := defaultBranch(input.Cwd); def != "" && p.match(def, false) == nil {
+			p.Protected = append(slices.Clone(p.Protected), config.BranchRule{Pattern: def, Deny: config.DefaultBranchOperations})
+		}
 	}
+	return p
+}
 
-	if isGitCommit(cmd) {
-		BlockWithError("Blocked: Do not commit directly to " + branch + ". Create a feature branch first.\n\nExample:\n  git checkout -b feat/my-feature")
+// match returns the first global or project rule whose pattern matches
+// branch, or nil.
+func (p *branchPolicy) match(branch string, project bool) *config.BranchRule {
+	if branch == "" {
 		return nil
 	}
-
-	if isGitPush(cmd) {
-		BlockWithError("Blocked: Do not push directly to " + branch + ". Push your feature branch and open a PR instead.\n\nExample:\n  git checkout -b feat/my-feature\n  git push -u origin feat/my-feature\n  gh pr create")
-		return nil
+	for i, rule := range p.Protected {
+		if ok, _ := path.Match(rule.Pattern, branch); ok && rule.Project == project {
+			return &p.Protected[i]
+		}
 	}
-
-	ExitOK()
 	return nil
 }
 
-// currentBranch returns the current git branch name for the given directory.
-// Returns empty string if git is not available or the directory is not a repo.
-func currentBranch(cwd string) string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	if cwd != "" {
-		cmd.Dir = cwd
+// action returns "deny", "ask" or "" for an operation on a branch, the
+// strictest of the first matching global and project rules. Branch "*"
+// stands for every branch, and gets the strictest action of all rules.
+func (p *branchPolicy) action(branch, op string) (string, *config.BranchRule) {
+	rules := p.Protected
+	if branch != "*" {
+		rules = nil
+		for _, project := range []bool{false, true} {
+			if rule := p.match(branch, project); rule != nil {
+				rules = append(rules, *rule)
+			}
+		}
 	}
-	out, err := cmd.Output()
-	if err != nil {
-		return ""
+
+	var action string
+	var decided *config.BranchRule
+	for i, rule := range rules {
+		switch {
+		case slices.Contains(rule.Deny, op):
+			return "deny", &rules[i]
+		case slices.Contains(rule.Ask, op) && action == "":
+			action, decided = "ask", &rules[i]
+		}
 	}
-	return strings.TrimSpace(string(out))
+	return action, decided
 }
 
-// isGitCommand checks if a command string starts with or contains a git command.
-func isGitCommand(cmd string) bool {
-	// Handle chained commands: "git add . && git commit -m ..."
-	parts := splitChainedCommands(cmd)
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if strings.HasPrefix(trimmed, "git ") || trimmed == "git" {
-			return true
+// describe lists the policy for the SessionStart reminder.
+func (p *branchPolicy) describe() string {
+	var sb strings.Builder
+	sb.WriteString("Protected branch policy:")
+	for _, rule := range p.Protected {
+		fmt.Fprintf(&sb, "\n- `%s`:", rule.Pattern)
+		if len(rule.Deny) > 0 {
+			fmt.Fprintf(&sb, " blocked: %s.", strings.Join(rule.Deny, ", "))
+		}
+		if len(rule.Ask) > 0 {
+			fmt.Fprintf(&sb, " needs confirmation: %s.", strings.Join(rule.Ask, ", "))
 		}
 	}
-	return false
+	sb.WriteString("\nWork on a feature branch and open a PR instead.")
+	return sb.String()
+}
+
+// branchOp is a git operation on a branch.
+type branchOp struct {
+	op     string // One of config.BranchOperations
+	branch string // "*" for every branch
 }
 
-// isGitCommit checks if the command contains a git commit operation.
-func isGitCommit(cmd string) bool {
-	parts := splitChainedCommands(cmd)
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if strings.HasPrefix(trimmed, "git commit") {
-			return true
+// gitBranchOps returns the branch operations a git invocation performs.
+// current is the checked-out branch of the invocation's directory.
+func gitBranchOps(g shellparse.Git, current string) []branchOp {
+	var ops []branchOp
+	add := func(op, branch string) {
+		if branch != "" {
+			ops = append(ops, branchOp{op, branch})
+		}
+	}
+
+	switch g.Subcommand {
+	case "commit":
+		add("commit", current)
+	case "push":
+		p, _ := g.Push()
+		if p.All || p.Mirror || len(p.Refspecs) == 0 {
+			op := "push"
+			if p.Force {
+				op = "force-push"
+			}
+			for _, b := range p.Branches(current) {
+				add(op, b)
+			}
+			break
+		}
+		for _, r := range p.Refspecs {
+			single := p
+			single.Refspecs = []shellparse.Refspec{r}
+			op := "push"
+			switch {
+			case r.Src == "":
+				op = "delete"
+			case r.Force || p.Force:
+				op = "force-push"
+			}
+			for _, b := range single.Branches(current) {
+				add(op, b)
+			}
+		}
+	case "rebase":
+		if g.HasFlag("--abort", "--continue", "--skip", "--quit", "--edit-todo", "--show-current-patch") {
+			break
+		}
+		// "git rebase <upstream> <branch>" checks out and rebases <branch>
+		if pos := positionalArgs(g.SubArgs, "--onto", "-s", "--strategy", "-X", "--strategy-option", "-x", "--exec"); len(pos) >= 2 {
+			add("rebase", pos[1])
+		} else {
+			add("rebase", current)
+		}
+	case "pull":
+		if g.HasFlag("-r", "--rebase") && !g.HasFlag("--rebase=false", "--no-rebase") {
+			add("rebase", current)
+		}
+	case "branch":
+		if g.HasFlag("-d", "-D", "--delete") {
+			for _, b := range positionalArgs(g.SubArgs) {
+				add("delete", b)
+			}
+		}
+	case "tag":
+		if !g.HasFlag("-l", "--list", "-d", "--delete", "-v", "--verify") && len(positionalArgs(g.SubArgs, "-m", "--message", "-F", "--file", "-u", "--local-user")) > 0 {
+			add("tag", current)
+		}
+	case "reset":
+		if g.HasFlag("--hard") {
+			add("reset-hard", current)
 		}
 	}
-	return false
+	return ops
 }
 
-// isGitPush checks if the command contains a git push operation.
-func isGitPush(cmd string) bool {
-	parts := splitChainedCommands(cmd)
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if strings.HasPrefix(trimmed, "git push") {
-			return true
+// positionalArgs returns the non-option arguments. withValue lists options
+// that consume the next argument.
+func positionalArgs(args []string, withValue ...string) []string {
+	var pos []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--":
+			return append(pos, args[i+1:]...)
+		case slices.Contains(withValue, a):
+			i++
+		case strings.HasPrefix(a, "-") && a != "-":
+		default:
+			pos = append(pos, a)
 		}
 	}
-	return false
+	return pos
 }
 
-// isPushToMain checks if the command explicitly pushes to main/master.
-func isPushToMain(cmd string) bool {
-	parts := splitChainedCommands(cmd)
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if !strings.HasPrefix(trimmed, "git push") {
-			continue
+// unresolvedBranch stands for a checked-out branch that can't be found,
+// such as that of a repository given by a variable.
+const unresolvedBranch = "\x00unresolved"
+
+// branchGuardDecision returns the decision for the first git operation in
+// the command that the policy restricts, or nil if all are allowed.
+// branchOf returns the checked-out branch of a directory. Commands that
+// mention git but don't parse need confirmation, and so do operations on a
+// branch that is only known when the command runs.
+func branchGuardDecision(command, cwd string, policy *branchPolicy, branchOf func(dir string) string) *Decision {
+	cmds, err := shellparse.Parse(command)
+	if err != nil {
+		if !strings.Contains(command, "git") {
+			return nil
 		}
-		// Check for "git push origin main" or "git push origin master"
-		words := strings.Fields(trimmed)
-		for i, w := range words {
-			if i > 1 && (w == "main" || w == "master") {
-				return true
+		return unparsedDecision("protected-branch operations", err)
+	}
+
+	for _, g := range shellparse.GitCommands(cmds) {
+		current := branchOf(g.WorkDir(cwd))
+		if g.DynamicDir || (current == "" && g.Dir != "") {
+			current = unresolvedBranch
+		}
+		for _, op := range gitBranchOps(g, current) {
+			switch {
+			case op.branch == unresolvedBranch:
+				return unparsedDecision("protected-branch operations", fmt.Errorf("the branch checked out in %s is unknown", g.Dir))
+			case g.IsDynamic(op.branch):
+				return unparsedDecision("protected-branch operations", fmt.Errorf("%s is only known when it runs", op.branch))
 			}
+			action, rule := policy.action(op.branch, op.op)
+			if action == "" {
+				continue
+			}
+			d := &Decision{Rule: rule.Pattern, Action: action, Reason: branchOpMessage(op)}
+			if action == "ask" {
+				d.Reason = fmt.Sprintf("`%s` is a protected branch; %s needs confirmation.", branchName(op.branch), op.op)
+			}
+			return d
 		}
 	}
-	return false
+	return nil
 }
 
-// splitChainedCommands splits a shell command string on && and ; delimiters.
-func splitChainedCommands(cmd string) []string {
-	// Split on && first, then on ;
-	var results []string
-	for _, part := range strings.Split(cmd, "&&") {
-		for _, sub := range strings.Split(part, ";") {
-			results = append(results, sub)
-		}
+// branchOpMessage explains why an operation is blocked.
+func branchOpMessage(op branchOp) string {
+	name := branchName(op.branch)
+	switch op.op {
+	case "commit":
+		return "Blocked: Do not commit directly to " + name + ". Create a feature branch first.\n\nExample:\n  git checkout -b feat/my-feature"
+	case "push":
+		return "Blocked: Do not push directly to " + name + ". Push your feature branch and open a PR instead.\n\nExample:\n  git checkout -b feat/my-feature\n  git push -u origin feat/my-feature\n  gh pr create"
+	case "force-push":
+		return "Blocked: Do not force-push to " + name + ". It rewrites history others depend on."
+	case "rebase":
+		return "Blocked: Do not rebase " + name + ". It is a shared branch; rebase your feature branch instead."
+	case "delete":
+		return "Blocked: Do not delete the protected branch " + name + "."
+	case "tag":
+		return "Blocked: Do not create tags on " + name + "."
+	case "reset-hard":
+		return "Blocked: Do not run git reset --hard on " + name + ". Create a feature branch to experiment instead."
+	default:
+		return "Blocked: " + op.op + " on " + name + " is not allowed."
+	}
+}
+
+// branchName describes a branch; "*" is every branch.
+func branchName(branch string) string {
+	if branch == "*" {
+		return "all branches"
+	}
+	return branch
+}
+
+// currentBranch returns the current git branch name for the given directory.
+// Returns empty string if git is not available or the directory is not a repo.
+func currentBranch(cwd string) string {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if cwd != "" {
+		cmd.Dir = cwd
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// defaultBranch returns the default branch of the origin remote, as
+// recorded in refs/remotes/origin/HEAD. Returns empty string if unknown.
+func defaultBranch(cwd string) string {
+	cmd := exec.Command("git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if cwd != "" {
+		cmd.Dir = cwd
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
 	}
-	return results
+	return strings.TrimPrefix(strings.TrimSpace(string(out)), "origin/")
 }
@@ -1,108 +1,285 @@
 package hooks
 
-import "testing"
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
 
-func TestIsGitCommand(t *testing.T) {
-	tests := []struct {
-		cmd  string
-		want bool
-	}{
-		{"git status", true},
-		{"git commit -m 'test'", true},
-		{"git add . && git commit -m 'test'", true},
-		{"ls -la", false},
-		{"echo git", false},
-		{"go test ./...", false},
-		{"git", true},
-		{"  git push", true},
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+)
+
+// defaultPolicy returns the built-in policy without default-branch detection.
+func defaultPolicy() *branchPolicy {
+	return &branchPolicy{Protected: config.Defaults().BranchPolicy.Protected}
+}
+
+// reason returns the reason of a decision, or "" if there is none.
+func reason(d *Decision) string {
+	if d == nil {
+		return ""
 	}
-	for _, tt := range tests {
-		t.Run(tt.cmd, func(t *testing.T) {
-			if got := isGitCommand(tt.cmd); got != tt.want {
-				t.Errorf("isGitCommand(%q) = %v, want %v", tt.cmd, got, tt.want)
-			}
-		})
+	return d.Reason
+}
+
+// fakeBranches returns a branchOf func for tests: the repo at cwd is on
+// main, anything under "feature" is on a feature branch.
+func fakeBranches(dir string) string {
+	if strings.Contains(dir, "feature") {
+		return "feat/x"
+	}
+	if filepath.Base(dir) == "norepo" {
+		return ""
 	}
+	return "main"
 }
 
-func TestIsGitCommit(t *testing.T) {
+func TestBranchGuardViolation(t *testing.T) {
 	tests := []struct {
-		cmd  string
-		want bool
+		cmd     string
+		blocked string // substring of the message, "" if allowed
 	}{
-		{"git commit -m 'test'", true},
-		{"git commit --amend", true},
-		{"git add . && git commit -m 'test'", true},
-		{"git push", false},
-		{"git status", false},
-		{"echo 'git commit'", false},
+		// Commits on the current branch
+		{"git commit -m 'test'", "commit directly to main"},
+		{"git commit --amend", "commit directly to main"},
+		{"git add . && git commit -m 'test'", "commit directly to main"},
+		{"git add .; git commit -m 'test'", "commit directly to main"},
+		{"make lint || git commit -m wip", "commit directly to main"},
+		{"(cd sub && git commit -m x)", "commit directly to main"},
+		{`bash -c "git commit -m 'x'"`, "commit directly to main"},
+		{"sh -lc 'git add -A && git commit -m x'", "commit directly to main"},
+		{"env GIT_AUTHOR_NAME=x git commit -m y", "commit directly to main"},
+		{"GIT_EDITOR=true git commit", "commit directly to main"},
+		{"echo $(git commit -m x)", "commit directly to main"},
+		{"git -C feature commit -m x", ""},
+		{"git -C norepo commit -m x", "could not be analysed"},
+		{"echo 'git commit'", ""},
+		{"git status", ""},
+		{"ls -la", ""},
+
+		// Pushes
+		{"git push", "push directly to main"},
+		{"git push origin main", "push directly to main"},
+		{"git push origin refs/tags/v1", ""},
+		{"git push origin master", "push directly to master"},
+		{"git push --force origin main", "force-push to main"},
+		{"git push origin HEAD:main", "push directly to main"},
+		{"git push origin feat/x:refs/heads/main", "push directly to main"},
+		{"git push origin +feat/x:main", "force-push to main"},
+		{"git push origin :main", "delete the protected branch main"},
+		{"git push --all origin", "all branches"},
+		{"git push --force origin feat/x:main", "force-push to main"},
+		{"git push --delete origin main", "delete the protected branch main"},
+		{"git branch -D main", "delete the protected branch main"},
+		{"git branch -D feat/old", ""},
+		{"git reset --hard HEAD~1", ""},
+		{"git rebase origin/main", ""},
+		{"git status | grep x && git push -u origin feat/x && git push origin main", "push directly to main"},
+		{"git -C feature push", ""},
+		{"git -C feature push origin HEAD:main", "push directly to main"},
+		{"git push -u origin feat/my-feature", ""},
+		{"git push origin feat/test", ""},
+		{"git push origin main:feat/test", ""},
+		{"git push origin v1.0 --tags", ""},
+		{"git push --tags", ""},
+		{"git commit -m 'main'", "commit directly to main"},
+
+		// Unparseable commands without git are left to the other guards
+		{"echo 'unterminated", ""},
 	}
 	for _, tt := range tests {
 		t.Run(tt.cmd, func(t *testing.T) {
-			if got := isGitCommit(tt.cmd); got != tt.want {
-				t.Errorf("isGitCommit(%q) = %v, want %v", tt.cmd, got, tt.want)
+			got := reason(branchGuardDecision(tt.cmd, "/repo", defaultPolicy(), fakeBranches))
+			if tt.blocked == "" {
+				if got != "" {
+					t.Errorf("expected allowed, got %q", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.blocked) {
+				t.Errorf("message = %q, want it to contain %q", got, tt.blocked)
 			}
 		})
 	}
 }
 
-func TestIsGitPush(t *testing.T) {
-	tests := []struct {
-		cmd  string
-		want bool
-	}{
-		{"git push", true},
-		{"git push origin feat/my-feature", true},
-		{"git push -u origin feat/my-feature", true},
-		{"git commit -m 'test'", false},
-		{"git status", false},
+func TestBranchGuardUnparsed(t *testing.T) {
+	// zsh syntax the parser rejects; the shell would still run the push
+	for _, cmd := range []string{"git push -f origin main; echo ${(f)x}", "git commit -m 'unterminated"} {
+		d := branchGuardDecision(cmd, "/repo", defaultPolicy(), fakeBranches)
+		if d == nil || d.Action != "ask" || d.Rule != "unparsed-command" {
+			t.Errorf("%q: decision = %+v, want ask", cmd, d)
+		}
 	}
-	for _, tt := range tests {
-		t.Run(tt.cmd, func(t *testing.T) {
-			if got := isGitPush(tt.cmd); got != tt.want {
-				t.Errorf("isGitPush(%q) = %v, want %v", tt.cmd, got, tt.want)
+}
+
+func TestBranchGuardDynamic(t *testing.T) {
+	// The branch or repository is only known when the command runs
+	for _, cmd := range []string{
+		"B=main; git push origin $B",
+		`git push origin "$(echo main)"`,
+		"git push origin HEAD:`cat branch`",
+		`git -C "$HOME/repo" commit -m x`,
+		"git --git-dir=$REPO/.git commit -m x",
+		"git -C ~/repo commit -m x",
+	} {
+		d := branchGuardDecision(cmd, "/repo", defaultPolicy(), func(dir string) string {
+			if dir == "/repo" {
+				return "feat/x"
 			}
+			return ""
 		})
+		if d == nil || d.Action != "ask" || d.Rule != "unparsed-command" {
+			t.Errorf("%q: decision = %+v, want ask", cmd, d)
+		}
+	}
+
+	// Dynamic words that don't name the branch are fine
+	for _, cmd := range []string{`git commit -m "$(cat msg)"`, `git -C "$HOME/repo" status`, "git push origin feat/$NAME:feat/x"} {
+		if d := branchGuardDecision(cmd, "/repo", defaultPolicy(), fakeBranches); d != nil && d.Rule == "unparsed-command" {
+			t.Errorf("%q: decision = %+v, want no ask", cmd, d)
+		}
 	}
 }
 
-func TestIsPushToMain(t *testing.T) {
+func TestBranchGuardFeatureBranch(t *testing.T) {
+	onFeature := func(string) string { return "feat/x" }
+	for _, cmd := range []string{"git commit -m x", "git push", "git push origin HEAD"} {
+		if got := branchGuardDecision(cmd, "/repo", defaultPolicy(), onFeature); got != nil {
+			t.Errorf("%q on a feature branch: got %q, want allowed", cmd, got)
+		}
+	}
+	if got := branchGuardDecision("git push origin HEAD:main", "/repo", defaultPolicy(), onFeature); got == nil {
+		t.Error("pushing HEAD to main from a feature branch should be blocked")
+	}
+}
+
+func TestBranchGuardCustomPolicy(t *testing.T) {
+	policy := &branchPolicy{Protected: []config.BranchRule{
+		{Pattern: "main", Deny: []string{"commit", "push", "force-push", "rebase", "delete", "reset-hard"}, Ask: []string{"tag"}},
+		{Pattern: "develop", Deny: []string{"force-push", "reset-hard"}},
+		{Pattern: "release/*", Deny: []string{"force-push", "delete", "rebase"}, Ask: []string{"push"}},
+		{Pattern: "hotfix/*", Deny: []string{"force-push"}},
+	}}
+	on := func(branch string) func(string) string {
+		return func(string) string { return branch }
+	}
+
 	tests := []struct {
-		cmd  string
-		want bool
+		cmd    string
+		branch string
+		action string
+		rule   string
 	}{
-		{"git push origin main", true},
-		{"git push origin master", true},
-		{"git push --force origin main", true},
-		{"git push -u origin feat/my-feature", false},
-		{"git push origin feat/test", false},
-		{"git push", false},
-		{"git commit -m 'main'", false},
+		{"git commit -m x", "develop", "", ""},
+		{"git push", "develop", "", ""},
+		{"git push -f", "develop", "deny", "develop"},
+		{"git reset --hard origin/develop", "develop", "deny", "develop"},
+		{"git reset --soft HEAD~1", "develop", "", ""},
+		{"git push origin release/1.2", "feat/x", "ask", "release/*"},
+		{"git push --force-with-lease origin HEAD:release/1.2", "feat/x", "deny", "release/*"},
+		{"git push origin release/1.2/extra", "feat/x", "", ""},
+		{"git rebase main", "release/1.2", "deny", "release/*"},
+		{"git rebase origin/main release/2.0", "feat/x", "deny", "release/*"},
+		{"git rebase --continue", "release/1.2", "", ""},
+		{"git pull --rebase", "main", "deny", "main"},
+		{"git pull", "main", "", ""},
+		{"git tag v1.0", "main", "ask", "main"},
+		{"git tag -l", "main", "", ""},
+		{"git tag -a v1.0 -m 'Release'", "develop", "", ""},
+		{"git push --mirror backup", "feat/x", "deny", "main"},
+		{"git push origin hotfix/urgent", "feat/x", "", ""},
+		{"git push origin +hotfix/urgent", "feat/x", "deny", "hotfix/*"},
 	}
 	for _, tt := range tests {
-		t.Run(tt.cmd, func(t *testing.T) {
-			if got := isPushToMain(tt.cmd); got != tt.want {
-				t.Errorf("isPushToMain(%q) = %v, want %v", tt.cmd, got, tt.want)
+		t.Run(tt.cmd+" on "+tt.branch, func(t *testing.T) {
+			d := branchGuardDecision(tt.cmd, "/repo", policy, on(tt.branch))
+			if tt.action == "" {
+				if d != nil {
+					t.Errorf("expected allowed, got %+v", d)
+				}
+				return
+			}
+			if d == nil || d.Action != tt.action || d.Rule != tt.rule {
+				t.Errorf("decision = %+v, want %s by %s", d, tt.action, tt.rule)
 			}
 		})
 	}
 }
 
-func TestSplitChainedCommands(t *testing.T) {
+func TestBranchPolicyDescribe(t *testing.T) {
+	got := defaultPolicy().describe()
+	for _, want := range []string{"`main`: blocked: commit, push, force-push, delete.", "`master`"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("describe() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestLoadBranchPolicyDetectsDefault(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv(config.EnvPrefix+"_HOME", t.TempDir())
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/trunk"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	policy := loadBranchPolicy(&Input{Cwd: dir})
+	rule := policy.match("trunk", false)
+	if rule == nil || rule.Pattern != "trunk" {
+		t.Fatalf("detected default branch not protected: %+v", policy.Protected)
+	}
+	if d := branchGuardDecision("git push origin trunk", dir, policy, func(string) string { return "feat/x" }); d == nil || d.Action != "deny" {
+		t.Errorf("push to detected default branch = %+v, want deny", d)
+	}
+}
+
+func TestLoadBranchPolicyProjectRules(t *testing.T) {
+	t.Setenv(config.EnvPrefix+"_HOME", t.TempDir())
+	on := func(branch string) func(string) string {
+		return func(string) string { return branch }
+	}
+	const (
+		mainTag   = "  protected:\n    - pattern: main\n      ask: [tag]\n"
+		develop   = "  protected:\n    - pattern: develop\n      deny: [force-push]\n"
+		allBranch = "  protected:\n    - pattern: \"*\"\n      ask: [tag]\n"
+		none      = "  protected: []\n"
+	)
+
 	tests := []struct {
-		cmd  string
-		want int // expected number of parts
+		name      string
+		protected string
+		cmd       string
+		branch    string
+		action    string
 	}{
-		{"git add .", 1},
-		{"git add . && git commit -m 'test'", 2},
-		{"git add .; git commit -m 'test'", 2},
-		{"git add . && git commit -m 'test' && git push", 3},
+		// Project rules add to the defaults; they can't loosen main or
+		// drop master
+		{"loosen main", mainTag, "git commit -m x", "main", "deny"},
+		{"add to main", mainTag, "git tag v1", "main", "ask"},
+		{"drop master", develop, "git push", "master", "deny"},
+		{"add develop", develop, "git push -f", "develop", "deny"},
+		{"catch-all", allBranch, "git push", "main", "deny"},
+		{"empty list", none, "git push origin main", "feat/x", "deny"},
 	}
 	for _, tt := range tests {
-		t.Run(tt.cmd, func(t *testing.T) {
-			parts := splitChainedCommands(tt.cmd)
-			if len(parts) != tt.want {
-				t.Errorf("splitChainedCommands(%q) returned %d parts, want %d", tt.cmd, len(parts), tt.want)
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			policy := "branch_policy:\n  detect_default: false\n" + tt.protected
+			if err := os.WriteFile(filepath.Join(dir, config.ProjectConfigName), []byte(policy), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			d := branchGuardDecision(tt.cmd, dir, loadBranchPolicy(&Input{Cwd: dir}), on(tt.branch))
+			if d == nil || d.Action != tt.action {
+				t.Errorf("decision = %+v, want %s", d, tt.action)
 			}
 		})
 	}
@@ -0,0 +1,173 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/checkers"
+)
+
+// lineRanges are the changed lines of a file, as inclusive [start, end]
+// pairs. nil means the whole file counts as changed.
+type lineRanges [][2]int
+
+// contains reports whether a line is changed. Diagnostics without a line
+// are about the whole file, so they always count.
+func (r lineRanges) contains(line int) bool {
+	if r == nil || line <= 0 {
+		return true
+	}
+	for _, rg := range r {
+		if line >= rg[0] && line <= rg[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// changedLines returns the lines of filePath that the tool call changed.
+// Edits are located by their new text in the file; Writes, and edits that
+// can't be located (deletions, or text a formatter has since rewritten),
+// use git diff against HEAD. Returns nil when the whole file counts as
+// changed: for new and untracked files, and outside git.
+func changedLines(input *Input, filePath string) lineRanges {
+	if input.ToolName == "Edit" || input.ToolName == "MultiEdit" {
+		var edits []EditToolInput
+		if input.ToolName == "Edit" {
+			var e EditToolInput
+			if json.Unmarshal(input.ToolInput, &e) == nil {
+				edits = append(edits, e)
+			}
+		} else {
+			var m MultiEditToolInput
+			if json.Unmarshal(input.ToolInput, &m) == nil {
+				edits = m.Edits
+			}
+		}
+		if content, err := os.ReadFile(filePath); err == nil && len(edits) > 0 {
+			if r, ok := locateEdits(string(content), edits); ok {
+				return r
+			}
+		}
+	}
+	return gitChangedLines(filePath)
+}
+
+// locateEdits returns the lines holding each edit's new text, at every
+// place it occurs. It returns false if an edit can't be found.
+func locateEdits(content string, edits []EditToolInput) (lineRanges, bool) {
+	r := lineRanges{}
+	for _, e := range edits {
+		if strings.TrimSpace(e.NewString) == "" {
+			return nil, false
+		}
+		found := false
+		for offset := 0; ; {
+			i := strings.Index(content[offset:], e.NewString)
+			if i < 0 {
+				break
+			}
+			found = true
+			start := offset + i
+			first := strings.Count(content[:start], "\n") + 1
+			r = append(r, [2]int{first, first + strings.Count(strings.TrimSuffix(e.NewString, "\n"), "\n")})
+			offset = start + len(e.NewString)
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return r, true
+}
+
+// gitChangedLines returns the lines of a tracked file that differ from
+// HEAD, or nil if the file isn't tracked or git fails.
+func gitChangedLines(filePath string) lineRanges {
+	dir, name := filepath.Split(filePath)
+	if err := exec.Command("git", "-C", dir, "ls-files", "--error-unmatch", "--", name).Run(); err != nil {
+		return nil
+	}
+	out, err := exec.Command("git", "-C", dir, "diff", "-U0", "--no-color", "--no-ext-diff", "HEAD", "--", name).Output()
+	if err != nil {
+		return nil
+	}
+	return parseHunkRanges(string(out))
+}
+
+// parseHunkRanges returns the new-side line ranges of the hunks in a
+// unified diff. A pure deletion marks the line before it, so problems
+// where lines were removed still count.
+func parseHunkRanges(diff string) lineRanges {
+	r := lineRanges{}
+	for _, line := range strings.Split(diff, "\n") {
+		if !strings.HasPrefix(line, "@@ ") {
+			continue
+		}
+		// @@ -a,b +c,d @@
+		_, after, ok := strings.Cut(line, " +")
+		if !ok {
+			continue
+		}
+		spec, _, _ := strings.Cut(after, " ")
+		startStr, countStr, hasCount := strings.Cut(spec, ",")
+		start, err := strconv.Atoi(startStr)
+		if err != nil {
+			continue
+		}
+		count := 1
+		if hasCount {
+			count, _ = strconv.Atoi(countStr)
+		}
+		if count == 0 {
+			r = append(r, [2]int{max(start, 1), max(start, 1)})
+		} else {
+			r = append(r, [2]int{start, start + count - 1})
+		}
+	}
+	return r
+}
+
+// splitByChange separates the diagnostics on changed lines of filePath from
+// the rest, which are returned as a count. Diagnostics for other files,
+// such as tsc reports for the whole project, count as pre-existing.
+func splitByChange(result *checkers.Result, filePath, cwd string, changed lineRanges) (*checkers.Result, int) {
+	current := &checkers.Result{Fixed: result.Fixed}
+	preexisting := 0
+	keep := func(d checkers.Diagnostic) bool {
+		if sameFile(d.File, filePath, cwd) && changed.contains(d.Line) {
+			return true
+		}
+		preexisting++
+		return false
+	}
+	for _, d := range result.Errors {
+		if keep(d) {
+			current.Errors = append(current.Errors, d)
+		}
+	}
+	for _, d := range result.Warnings {
+		if keep(d) {
+			current.Warnings = append(current.Warnings, d)
+		}
+	}
+	return current, preexisting
+}
+
+// sameFile reports whether a diagnostic's file, which tools may give
+// relative to their working directory, is filePath.
+func sameFile(file, filePath, cwd string) bool {
+	if file == "" {
+		return true
+	}
+	if filepath.IsAbs(file) {
+		return filepath.Clean(file) == filepath.Clean(filePath)
+	}
+	if cwd != "" && filepath.Join(cwd, file) == filepath.Clean(filePath) {
+		return true
+	}
+	return strings.HasSuffix(filepath.ToSlash(filePath), "/"+filepath.ToSlash(filepath.Clean(file)))
+}
@@ -0,0 +1,150 @@
+package hooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/checkers"
+)
+
+func TestLocateEdits(t *testing.T) {
+	content := "package x\n\nfunc a() {}\n\nfunc b() {\n\treturn\n}\n\nfunc a() {}\n"
+	tests := []struct {
+		name  string
+		edits []EditToolInput
+		want  lineRanges
+		ok    bool
+	}{
+		{"single line", []EditToolInput{{NewString: "func b() {"}}, lineRanges{{5, 5}}, true},
+		{"multiple lines", []EditToolInput{{NewString: "func b() {\n\treturn\n}\n"}}, lineRanges{{5, 7}}, true},
+		{"every occurrence", []EditToolInput{{NewString: "func a() {}"}}, lineRanges{{3, 3}, {9, 9}}, true},
+		{"several edits", []EditToolInput{{NewString: "package x"}, {NewString: "\treturn"}}, lineRanges{{1, 1}, {6, 6}}, true},
+		{"deletion", []EditToolInput{{OldString: "x", NewString: ""}}, nil, false},
+		{"rewritten by formatter", []EditToolInput{{NewString: "func c(){}"}}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := locateEdits(content, tt.edits)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("locateEdits() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseHunkRanges(t *testing.T) {
+	diff := `diff --git a/app.py b/app.py
+--- a/app.py
++++ b/app.py
+@@ -3 +3 @@ import os
+-x = 1
++x = 2
+@@ -10,0 +11,3 @@ def f():
++    a
++    b
++    c
+@@ -20,2 +22,0 @@ def g():
+-    y
+-    z
+@@ -1,2 +0,0 @@
+-a
+-b
+`
+	want := lineRanges{{3, 3}, {11, 13}, {22, 22}, {1, 1}}
+	if got := parseHunkRanges(diff); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHunkRanges() = %v, want %v", got, want)
+	}
+	if got := parseHunkRanges(""); got == nil || len(got) != 0 {
+		t.Errorf("parseHunkRanges(\"\") = %#v, want empty, non-nil", got)
+	}
+}
+
+func TestSplitByChange(t *testing.T) {
+	result := &checkers.Result{
+		Fixed: true,
+		Errors: []checkers.Diagnostic{
+			{File: "/p/src/app.ts", Line: 4, Message: "in edit"},
+			{File: "/p/src/app.ts", Line: 40, Message: "old"},
+			{File: "src/other.ts", Line: 4, Message: "other file"},
+		},
+		Warnings: []checkers.Diagnostic{
+			{File: "src/app.ts", Line: 5, Message: "relative, in edit"},
+			{Message: "whole file"},
+		},
+	}
+	current, pre := splitByChange(result, "/p/src/app.ts", "/p", lineRanges{{3, 5}})
+	if pre != 2 {
+		t.Errorf("pre-existing = %d, want 2", pre)
+	}
+	if len(current.Errors) != 1 || current.Errors[0].Message != "in edit" {
+		t.Errorf("errors = %v", current.Errors)
+	}
+	if len(current.Warnings) != 2 || !current.Fixed {
+		t.Errorf("warnings = %v, fixed = %v", current.Warnings, current.Fixed)
+	}
+
+	// nil ranges: the whole file is new, but other files still don't count
+	current, pre = splitByChange(result, "/p/src/app.ts", "/p", nil)
+	if pre != 1 || len(current.Errors) != 2 {
+		t.Errorf("whole file: errors = %v, pre-existing = %d", current.Errors, pre)
+	}
+}
+
+func TestSameFile(t *testing.T) {
+	tests := []struct {
+		file, cwd string
+		want      bool
+	}{
+		{"", "", true},
+		{"/p/src/app.go", "", true},
+		{"/p/src/other.go", "", false},
+		{"src/app.go", "/p", true},
+		{"app.go", "/elsewhere", true},
+		{"pp.go", "", false},
+	}
+	for _, tt := range tests {
+		if got := sameFile(tt.file, "/p/src/app.go", tt.cwd); got != tt.want {
+			t.Errorf("sameFile(%q, cwd %q) = %v, want %v", tt.file, tt.cwd, got, tt.want)
+		}
+	}
+}
+
+func TestChangedLinesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	file := filepath.Join(dir, "app.py")
+	git("init", "-q")
+	os.WriteFile(file, []byte("a = 1\nb = 2\nc = 3\nd = 4\n"), 0o644)
+	git("add", "app.py")
+	git("commit", "-q", "-m", "init")
+	os.WriteFile(file, []byte("a = 1\nb = 20\nc = 3\nd = 4\ne = 5\n"), 0o644)
+
+	write := toolInput(t, dir, "Write", WriteToolInput{FilePath: file})
+	if got, want := changedLines(write, file), (lineRanges{{2, 2}, {5, 5}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Write: changedLines() = %v, want %v", got, want)
+	}
+
+	// Located edits don't need git
+	edit := toolInput(t, dir, "Edit", EditToolInput{FilePath: file, OldString: "b = 2", NewString: "b = 20"})
+	if got, want := changedLines(edit, file), (lineRanges{{2, 2}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Edit: changedLines() = %v, want %v", got, want)
+	}
+
+	untracked := filepath.Join(dir, "new.py")
+	os.WriteFile(untracked, []byte("x = 1\n"), 0o644)
+	if got := changedLines(toolInput(t, dir, "Write", WriteToolInput{FilePath: untracked}), untracked); got != nil {
+		t.Errorf("untracked: changedLines() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,300 @@
+package checkers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Cacheable is implemented by checkers whose result depends only on the
+// files of a project, their tools and config files, so it can be reused
+// while none of them change.
+type Cacheable interface {
+	Inputs(filePath string) CacheInputs
+}
+
+// CacheInputs is what a checker's result for a file depends on besides the
+// file itself.
+type CacheInputs struct {
+	// Root is the project the tools analyse. Tools such as tsc and
+	// golangci-lint check the file against the rest of it, so a change to
+	// any other file in it invalidates the result.
+	Root    string
+	Tools   []string // Paths of the tools that would run; "" if missing
+	Configs []string // Paths of the config files they read
+}
+
+// Cache stores checker results in a directory, keyed by a hash of the
+// file's content, the state of the rest of its project, the checker's
+// tools and its config files.
+type Cache struct {
+	dir    string
+	mu     sync.Mutex        // Guards the stats file
+	stateM sync.Mutex        // Guards states
+	states map[string]string // Project states by root and file, for this process
+}
+
+// CacheStats counts the lookups in a cache and what it holds.
+type CacheStats struct {
+	Hits    int   `json:"hits"`
+	Misses  int   `json:"misses"`
+	Entries int   `json:"entries"`
+	Size    int64 `json:"size"` // Bytes used by the entries
+}
+
+// HitRate returns the share of lookups that were hits, from 0 to 1.
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// cacheStatsFile holds the hit and miss counts in the cache directory.
+const cacheStatsFile = "stats.json"
+
+// CacheDir returns the checker cache directory of a session.
+func CacheDir(sessionDir string) string {
+	return filepath.Join(sessionDir, "checker-cache")
+}
+
+// NewCache returns a cache in dir, which is created when the first result
+// is stored. Project states are read once per Cache, so each hook run uses
+// a new one.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+type cacheKey struct{}
+
+// WithCache returns a context in which Run reuses results from c.
+func WithCache(ctx context.Context, c *Cache) context.Context {
+	return context.WithValue(ctx, cacheKey{}, c)
+}
+
+func cacheFrom(ctx context.Context) *Cache {
+	c, _ := ctx.Value(cacheKey{}).(*Cache)
+	return c
+}
+
+// lookup returns the cached result of a checker for the file as it is now.
+// Only lookups for cacheable checkers are counted.
+func (c *Cache) lookup(ch Checker, filePath string) (*Result, bool) {
+	if c == nil {
+		return nil, false
+	}
+	key, ok := c.key(ch, filePath)
+	if !ok {
+		return nil, false
+	}
+	var result Result
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	hit := err == nil && json.Unmarshal(data, &result) == nil
+	c.count(hit)
+	if !hit {
+		return nil, false
+	}
+	return &result, true
+}
+
+// store caches a checker's result for the file as the checker left it.
+// Running the checker on that content again would find the same problems
+// and have nothing left to fix.
+func (c *Cache) store(ch Checker, filePath string, result *Result) {
+	if c == nil {
+		return
+	}
+	key, ok := c.key(ch, filePath)
+	if !ok {
+		return
+	}
+	stored := *result
+	stored.Fixed = false
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return
+	}
+	writeFileAtomic(filepath.Join(c.dir, key+".json"), data) //nolint:errcheck // A failed store is only a later miss
+}
+
+// key hashes what a checker's result for the file depends on: the path and
+// content of the file, the state of the other files in the project, each
+// tool's path, size and modification time, which change when it is
+// upgraded, and the content of each config file. It reports false for
+// checkers that can't be cached, unreadable files and projects outside git.
+func (c *Cache) key(ch Checker, filePath string) (string, bool) {
+	cacheable, ok := ch.(Cacheable)
+	if !ok {
+		return "", false
+	}
+	in := cacheable.Inputs(filePath)
+	state, ok := c.projectState(in.Root, filePath)
+	if !ok {
+		return "", false
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "checker %s\nfile %s %x\nproject %s %s\n", ch.Name(), filePath, sha256.Sum256(content), in.Root, state)
+	tools, configs := in.Tools, in.Configs
+	for _, t := range tools {
+		if info, err := os.Stat(t); t != "" && err == nil {
+			fmt.Fprintf(h, "tool %s %d %d\n", t, info.Size(), info.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(h, "tool none\n")
+		}
+	}
+	for _, conf := range configs {
+		if data, err := os.ReadFile(conf); conf != "" && err == nil {
+			fmt.Fprintf(h, "config %s %x\n", conf, sha256.Sum256(data))
+		} else {
+			fmt.Fprintf(h, "config none\n")
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// projectState returns a hash of the files in root other than filePath:
+// the index, the unstaged changes, and the size and modification time of
+// the untracked files git doesn't ignore. The file itself is left out, so
+// the state is the same before and after a formatter changes it, and is
+// computed once per process.
+func (c *Cache) projectState(root, filePath string) (string, bool) {
+	rel, err := filepath.Rel(root, filePath)
+	if root == "" || err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	c.stateM.Lock()
+	defer c.stateM.Unlock()
+	id := root + "\x00" + filePath
+	if state, ok := c.states[id]; ok {
+		return state, true
+	}
+
+	pathspec := []string{"--", ".", ":(exclude,literal)" + filepath.ToSlash(rel)}
+	h := sha256.New()
+	for _, args := range [][]string{
+		{"ls-files", "--stage"},
+		{"diff", "--no-color", "--no-ext-diff", "--binary"},
+	} {
+		out, err := gitOutput(root, append(args, pathspec...)...)
+		if err != nil {
+			return "", false
+		}
+		h.Write(out)
+	}
+	untracked, err := gitOutput(root, append([]string{"ls-files", "-z", "--others", "--exclude-standard"}, pathspec...)...)
+	if err != nil {
+		return "", false
+	}
+	for _, name := range strings.Split(string(untracked), "\x00") {
+		if info, err := os.Stat(filepath.Join(root, name)); name != "" && err == nil {
+			fmt.Fprintf(h, "untracked %s %d %d\n", name, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+
+	state := hex.EncodeToString(h.Sum(nil))
+	if c.states == nil {
+		c.states = make(map[string]string)
+	}
+	c.states[id] = state
+	return state, true
+}
+
+// gitOutput runs git in dir and returns its output.
+func gitOutput(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	return cmd.Output()
+}
+
+// count records a lookup in the stats file.
+func (c *Cache) count(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.readStats()
+	if hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+	data, err := json.Marshal(&stats)
+	if err != nil {
+		return
+	}
+	writeFileAtomic(filepath.Join(c.dir, cacheStatsFile), data) //nolint:errcheck // Stats are best effort
+}
+
+// readStats reads the hit and miss counts.
+func (c *Cache) readStats() CacheStats {
+	var stats CacheStats
+	if data, err := os.ReadFile(filepath.Join(c.dir, cacheStatsFile)); err == nil {
+		json.Unmarshal(data, &stats) //nolint:errcheck // A corrupt stats file starts over
+	}
+	return CacheStats{Hits: stats.Hits, Misses: stats.Misses}
+}
+
+// Stats returns the lookup counts and the number and size of the entries.
+func (c *Cache) Stats() (CacheStats, error) {
+	stats := c.readStats()
+	entries, err := os.ReadDir(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("read checker cache: %w", err)
+	}
+	for _, e := range entries {
+		if e.Name() == cacheStatsFile || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			stats.Entries++
+			stats.Size += info.Size()
+		}
+	}
+	return stats, nil
+}
+
+// Clear removes the cached results and the stats.
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.RemoveAll(c.dir); err != nil {
+		return fmt.Errorf("clear checker cache: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes a file through a temporary file, so concurrent
+// hooks never read a partial one.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
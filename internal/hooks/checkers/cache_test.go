@@ -0,0 +1,221 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// cacheableChecker is a fake checker of a project with a tool and a config
+// file.
+type cacheableChecker struct {
+	fakeChecker
+	root, tool, config string
+	runs               int
+}
+
+func (c *cacheableChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	c.runs++
+	return c.fakeChecker.Check(ctx, filePath)
+}
+
+func (c *cacheableChecker) Inputs(filePath string) CacheInputs {
+	return CacheInputs{Root: c.root, Tools: []string{c.tool}, Configs: []string{c.config}}
+}
+
+// cacheRepo returns a git repository with a committed a.x and b.x, and a
+// function that runs git in it.
+func cacheRepo(t *testing.T) (string, func(args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	for _, name := range []string{"a.x", "b.x"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("v1"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	return root, git
+}
+
+func TestCacheRun(t *testing.T) {
+	root, _ := cacheRepo(t)
+	dir := t.TempDir()
+	file := filepath.Join(root, "a.x")
+	c := &cacheableChecker{
+		fakeChecker: fakeChecker{name: "fake", result: Result{Errors: []Diagnostic{diag("fake")}, Fixed: true}},
+		root:        root,
+		tool:        filepath.Join(dir, "tool"),
+		config:      filepath.Join(dir, "config"),
+	}
+	for _, path := range []string{c.tool, c.config} {
+		if err := os.WriteFile(path, []byte("v1"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := NewCache(filepath.Join(dir, "cache"))
+	ctx := WithCache(context.Background(), cache)
+
+	run := func(wantRuns int) *Result {
+		t.Helper()
+		r, err := Run(ctx, []Checker{c}, file)
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if c.runs != wantRuns {
+			t.Fatalf("checker ran %d times, want %d", c.runs, wantRuns)
+		}
+		return r
+	}
+
+	if r := run(1); !r.Fixed {
+		t.Error("first run isn't Fixed")
+	}
+	r := run(1)
+	if len(r.Errors) != 1 || r.Errors[0] != diag("fake") {
+		t.Errorf("cached Errors = %v", r.Errors)
+	}
+	if r.Fixed {
+		t.Error("cached result is Fixed; there was nothing left to fix")
+	}
+
+	// Changing the file, the config or the tool misses
+	for i, path := range []string{file, c.config, c.tool} {
+		if err := os.WriteFile(path, []byte("v2 changed"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		run(2 + i)
+		run(2 + i)
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Hits != 4 || stats.Misses != 4 || stats.Entries != 4 {
+		t.Errorf("Stats() = %+v, want 4 hits, 4 misses and 4 entries", stats)
+	}
+	if got := stats.HitRate(); got != 0.5 {
+		t.Errorf("HitRate() = %v, want 0.5", got)
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := cache.Stats(); stats != (CacheStats{}) {
+		t.Errorf("Stats() after Clear = %+v, want zero", stats)
+	}
+}
+
+// TestCacheProjectState checks that a result is only reused while the
+// rest of the project is unchanged, as tools like tsc check a file against
+// the files it imports.
+func TestCacheProjectState(t *testing.T) {
+	root, git := cacheRepo(t)
+	dir := t.TempDir()
+	file := filepath.Join(root, "a.x")
+	c := &cacheableChecker{fakeChecker: fakeChecker{name: "fake"}, root: root}
+
+	// Each hook run has its own Cache
+	run := func(wantRuns int) {
+		t.Helper()
+		ctx := WithCache(context.Background(), NewCache(filepath.Join(dir, "cache")))
+		if _, err := Run(ctx, []Checker{c}, file); err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if c.runs != wantRuns {
+			t.Fatalf("checker ran %d times, want %d", c.runs, wantRuns)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run(1)
+	run(1)
+
+	// Editing the checked file itself only changes the file's hash
+	write("a.x", "v2")
+	run(2)
+	run(2)
+
+	// Editing, staging and adding other files all miss
+	write("b.x", "v2 with a changed signature")
+	run(3)
+	run(3)
+	git("add", "b.x")
+	run(4)
+	write("c.x", "new")
+	run(5)
+	run(5)
+	write("ignored.x", "build output")
+	write(".gitignore", "ignored.x\n")
+	run(6)
+	write("ignored.x", "more build output")
+	run(6)
+}
+
+func TestCacheSkipsFailuresAndUncacheable(t *testing.T) {
+	root, _ := cacheRepo(t)
+	dir := t.TempDir()
+	file := filepath.Join(root, "a.x")
+	cache := NewCache(filepath.Join(dir, "cache"))
+	ctx := WithCache(context.Background(), cache)
+
+	failing := &cacheableChecker{fakeChecker: fakeChecker{name: "failing", err: errors.New("timed out")}, root: root}
+	plain := &fakeChecker{name: "plain"}
+	for range 2 {
+		Run(ctx, []Checker{failing, plain}, file) //nolint:errcheck
+	}
+	if failing.runs != 2 {
+		t.Errorf("failing checker ran %d times, want 2", failing.runs)
+	}
+	stats, _ := cache.Stats()
+	if stats.Hits != 0 || stats.Misses != 2 || stats.Entries != 0 {
+		t.Errorf("Stats() = %+v, want 2 misses and nothing cached", stats)
+	}
+}
+
+func TestCacheOutsideGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Without git the rest of the project can't be tracked, so nothing is
+	// cached
+	root := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(root))
+	file := filepath.Join(root, "a.x")
+	if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCache(filepath.Join(t.TempDir(), "cache"))
+	ctx := WithCache(context.Background(), cache)
+
+	c := &cacheableChecker{fakeChecker: fakeChecker{name: "fake"}, root: root}
+	for range 2 {
+		Run(ctx, []Checker{c}, file) //nolint:errcheck
+	}
+	if c.runs != 2 {
+		t.Errorf("checker ran %d times, want 2", c.runs)
+	}
+	if stats, _ := cache.Stats(); stats != (CacheStats{}) {
+		t.Errorf("Stats() = %+v, want no lookups", stats)
+	}
+}
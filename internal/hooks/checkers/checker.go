@@ -4,8 +4,19 @@
 package checkers
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // Diagnostic represents a single error or warning from a checker.
@@ -15,6 +26,7 @@ type Diagnostic struct {
 	Column  int    `json:"column,omitempty"`
 	Message string `json:"message"`
 	Source  string `json:"source"`
+	Rule    string `json:"rule,omitempty"` // The tool's rule or error code, e.g. "E501"
 }
 
 // Result holds the output from running a checker on a file.
@@ -24,13 +36,37 @@ type Result struct {
 	Fixed    bool         `json:"fixed"`
 }
 
+// Priority orders the checkers for a file.
+type Priority int
+
+const (
+	// Formatter checkers change the file, so they run first, one at a time.
+	Formatter Priority = iota
+	// Linter checkers only read the file, so they run concurrently.
+	Linter
+)
+
 // Checker is the interface that language-specific checkers implement.
 type Checker interface {
 	Name() string
 	Extensions() []string
+	Priority() Priority
 	Check(ctx context.Context, filePath string) (*Result, error)
 }
 
+// BasenameMatcher is implemented by checkers for files that are known by
+// name rather than extension, such as Dockerfile.
+type BasenameMatcher interface {
+	// Basenames returns filepath.Match patterns for file names.
+	Basenames() []string
+}
+
+// PathMatcher is implemented by checkers that decide themselves which
+// files they handle, such as custom checkers with path globs.
+type PathMatcher interface {
+	Matches(filePath string) bool
+}
+
 // registry holds all registered checkers.
 var registry []Checker
 
@@ -39,17 +75,38 @@ func Register(c Checker) {
 	registry = append(registry, c)
 }
 
-// ForExtension returns the first checker that handles the given file extension.
-// Returns nil if no checker matches.
-func ForExtension(ext string) Checker {
+// ForFile returns the checkers that handle the file, by extension or by
+// name, formatters first and otherwise in the order they were registered.
+func ForFile(filePath string) []Checker {
+	var matched []Checker
 	for _, c := range registry {
-		for _, e := range c.Extensions() {
-			if e == ext {
-				return c
+		if handles(c, filePath) {
+			matched = append(matched, c)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b Checker) int {
+		return cmp.Compare(a.Priority(), b.Priority())
+	})
+	return matched
+}
+
+// handles reports whether a checker handles the file.
+func handles(c Checker, filePath string) bool {
+	if slices.Contains(c.Extensions(), filepath.Ext(filePath)) {
+		return true
+	}
+	if m, ok := c.(PathMatcher); ok && m.Matches(filePath) {
+		return true
+	}
+	if m, ok := c.(BasenameMatcher); ok {
+		base := filepath.Base(filePath)
+		for _, pattern := range m.Basenames() {
+			if ok, _ := filepath.Match(pattern, base); ok {
+				return true
 			}
 		}
 	}
-	return nil
+	return false
 }
 
 // toolExists checks if a command-line tool is available on PATH.
@@ -57,3 +114,140 @@ func toolExists(name string) bool {
 	_, err := exec.LookPath(name)
 	return err == nil
 }
+
+// findUp returns the nearest directory from dir upwards that contains
+// name, or "" if there is none.
+func findUp(dir, name string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// locationLine matches "file:line:col: message" diagnostics, as printed by
+// gofmt and many other tools. The column is optional.
+var locationLine = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)?\s*(.*)$`)
+
+// parseLocationLines parses "file:line:col: message" lines. Lines that
+// don't match are reported without a location; blank lines are skipped.
+func parseLocationLines(out, defaultFile, source string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		d := Diagnostic{File: defaultFile, Message: line, Source: source}
+		if m := locationLine.FindStringSubmatch(line); m != nil {
+			d.File, d.Message = m[1], m[4]
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// parseCheckstyle parses a checkstyle XML report, which many linters can
+// write. Severity "error" and "fatal" are errors; the rest are warnings.
+func parseCheckstyle(data []byte, source string) (errs, warnings []Diagnostic, err error) {
+	var report struct {
+		Files []struct {
+			Name   string `xml:"name,attr"`
+			Errors []struct {
+				Line     int    `xml:"line,attr"`
+				Column   int    `xml:"column,attr"`
+				Severity string `xml:"severity,attr"`
+				Message  string `xml:"message,attr"`
+				Source   string `xml:"source,attr"`
+			} `xml:"error"`
+		} `xml:"file"`
+	}
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return nil, nil, fmt.Errorf("parse %s checkstyle output: %w", source, err)
+	}
+	for _, f := range report.Files {
+		for _, e := range f.Errors {
+			d := Diagnostic{
+				File:    f.Name,
+				Line:    e.Line,
+				Column:  e.Column,
+				Message: e.Message,
+				Source:  source,
+				Rule:    e.Source,
+			}
+			if e.Severity == "error" || e.Severity == "fatal" {
+				errs = append(errs, d)
+			} else {
+				warnings = append(warnings, d)
+			}
+		}
+	}
+	return errs, warnings, nil
+}
+
+// parseSARIF parses a SARIF log, which many security and lint tools can
+// write. Results at level "error" are errors; the rest, including those
+// without a level, which SARIF treats as warnings, are warnings.
+func parseSARIF(data []byte, source string) (errs, warnings []Diagnostic, err error) {
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				RuleID  string `json:"ruleId"`
+				Level   string `json:"level"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, nil, fmt.Errorf("parse %s SARIF output: %w", source, err)
+	}
+	for _, run := range log.Runs {
+		for _, r := range run.Results {
+			d := Diagnostic{Message: r.Message.Text, Source: source, Rule: r.RuleID}
+			if len(r.Locations) > 0 {
+				loc := r.Locations[0].PhysicalLocation
+				d.File = sarifPath(loc.ArtifactLocation.URI)
+				d.Line = loc.Region.StartLine
+				d.Column = loc.Region.StartColumn
+			}
+			if r.Level == "error" {
+				errs = append(errs, d)
+			} else if r.Level != "none" {
+				warnings = append(warnings, d)
+			}
+		}
+	}
+	return errs, warnings, nil
+}
+
+// sarifPath returns the file path of a SARIF artifact URI, which is
+// either relative or a file:// URL.
+func sarifPath(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Scheme == "file" {
+		return u.Path
+	}
+	if p, err := url.PathUnescape(uri); err == nil {
+		return p
+	}
+	return uri
+}
@@ -1,50 +1,118 @@
 package checkers
 
 import (
+	"reflect"
 	"testing"
 )
 
-func TestForExtension(t *testing.T) {
+// names returns the name of each checker.
+func names(cs []Checker) []string {
+	var out []string
+	for _, c := range cs {
+		out = append(out, c.Name())
+	}
+	return out
+}
+
+func TestForFile(t *testing.T) {
 	tests := []struct {
-		ext      string
-		wantName string
+		path  string
+		names []string
 	}{
-		{".py", "python"},
-		{".ts", "typescript"},
-		{".tsx", "typescript"},
-		{".js", "typescript"},
-		{".jsx", "typescript"},
-		{".go", "go"},
-		{".rs", ""},
-		{".rb", ""},
+		{"/p/app.py", []string{"python"}},
+		{"/p/app.ts", []string{"typescript"}},
+		{"/p/app.tsx", []string{"typescript"}},
+		{"/p/app.js", []string{"typescript"}},
+		{"/p/app.jsx", []string{"typescript"}},
+		{"/p/main.go", []string{"go"}},
+		{"/p/src/App.php", []string{"php"}},
+		{"/p/foo.module", []string{"php"}},
+		{"/p/foo.theme", []string{"php"}},
+		{"/p/main.rs", []string{"rust"}},
+		{"/p/deploy.sh", []string{"shell"}},
+		{"/p/deploy.bash", []string{"shell"}},
+		{"/p/ci.yml", []string{"yaml"}},
+		{"/p/ci.yaml", []string{"yaml"}},
+		{"/p/package.json", []string{"json"}},
+		{"/p/README.md", []string{"markdown"}},
+		{"/p/base.html.twig", []string{"twig"}},
+		{"/p/Dockerfile", []string{"dockerfile"}},
+		{"/p/docker/Dockerfile.prod", []string{"dockerfile"}},
+		{"/p/api.Dockerfile", []string{"dockerfile"}},
+		{"/p/Containerfile", []string{"dockerfile"}},
+		{"/p/app.rb", nil},
+		{"/p/Makefile", nil},
+		{"/p/notes.txt", nil},
 	}
-
 	for _, tt := range tests {
-		t.Run(tt.ext, func(t *testing.T) {
-			c := ForExtension(tt.ext)
-			if tt.wantName == "" {
-				if c != nil {
-					t.Errorf("ForExtension(%q) = %v, want nil", tt.ext, c.Name())
-				}
-				return
-			}
-			if c == nil {
-				t.Fatalf("ForExtension(%q) = nil, want %q", tt.ext, tt.wantName)
-			}
-			if c.Name() != tt.wantName {
-				t.Errorf("ForExtension(%q).Name() = %q, want %q", tt.ext, c.Name(), tt.wantName)
+		t.Run(tt.path, func(t *testing.T) {
+			if got := names(ForFile(tt.path)); !reflect.DeepEqual(got, tt.names) {
+				t.Errorf("ForFile(%q) = %q, want %q", tt.path, got, tt.names)
 			}
 		})
 	}
 }
 
+func TestForFileOrdersByPriority(t *testing.T) {
+	defer func(saved []Checker) { registry = saved }(registry)
+	registry = nil
+	Register(&fakeChecker{name: "lint-a", exts: []string{".x"}, priority: Linter})
+	Register(&fakeChecker{name: "fmt", exts: []string{".x"}, priority: Formatter})
+	Register(&fakeChecker{name: "other", exts: []string{".y"}, priority: Formatter})
+	Register(&fakeChecker{name: "lint-b", exts: []string{".x"}, priority: Linter})
+
+	if got, want := names(ForFile("/p/a.x")), []string{"fmt", "lint-a", "lint-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ForFile() = %q, want %q", got, want)
+	}
+}
+
+func TestParseCheckstyle(t *testing.T) {
+	out := `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle>
+  <file name="/p/templates/base.html.twig">
+    <error line="3" column="12" severity="error" message="Expecting 1 whitespace after &quot;|&quot;; found 0." source="TwigCsFixer.OperatorSpacing.After"/>
+    <error line="9" severity="warning" message="Unused variable." source="TwigCsFixer.UnusedVariable"/>
+  </file>
+</checkstyle>`
+	errs, warnings, err := parseCheckstyle([]byte(out), "twig-cs-fixer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Diagnostic{File: "/p/templates/base.html.twig", Line: 3, Column: 12, Message: `Expecting 1 whitespace after "|"; found 0.`, Source: "twig-cs-fixer", Rule: "TwigCsFixer.OperatorSpacing.After"}
+	if len(errs) != 1 || errs[0] != want {
+		t.Errorf("errors = %+v, want %+v", errs, want)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 9 {
+		t.Errorf("warnings = %+v, want one at line 9", warnings)
+	}
+}
+
+func TestParseSARIF(t *testing.T) {
+	out := `{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"semgrep"}},"results":[
+		{"ruleId":"sql-injection","level":"error","message":{"text":"User input in SQL query"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"file:///p/src/db%20layer/query.php"},"region":{"startLine":14,"startColumn":9}}}]},
+		{"ruleId":"todo","message":{"text":"TODO left in code"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"src/app.php"},"region":{"startLine":3}}}]},
+		{"ruleId":"info","level":"none","message":{"text":"ignored"}}
+	]}]}`
+	errs, warnings, err := parseSARIF([]byte(out), "semgrep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Diagnostic{{File: "/p/src/db layer/query.php", Line: 14, Column: 9, Message: "User input in SQL query", Source: "semgrep", Rule: "sql-injection"}}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors = %+v, want %+v", errs, want)
+	}
+	if len(warnings) != 1 || warnings[0].File != "src/app.php" || warnings[0].Line != 3 {
+		t.Errorf("warnings = %+v, want the TODO as a warning", warnings)
+	}
+}
+
 func TestAllCheckersRegistered(t *testing.T) {
 	names := map[string]bool{}
 	for _, c := range registry {
 		names[c.Name()] = true
 	}
 
-	want := []string{"python", "typescript", "go"}
+	want := []string{"python", "typescript", "go", "php", "rust", "shell", "yaml", "json", "markdown", "twig", "dockerfile"}
 	for _, name := range want {
 		if !names[name] {
 			t.Errorf("checker %q not registered", name)
@@ -0,0 +1,287 @@
+package checkers
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"mvdan.cc/sh/v3/shell"
+)
+
+// commandChecker runs a lint command from the checker.custom config.
+type commandChecker struct {
+	cfg     config.CustomChecker
+	root    string // Project root; relative globs and paths are resolved against it
+	project *project
+	files   []*regexp.Regexp
+	pattern *regexp.Regexp // For the regex format
+}
+
+// NewCommandChecker returns a checker for a custom checker config, for the
+// project at root.
+func NewCommandChecker(cfg config.CustomChecker, root string) (Checker, error) {
+	c := &commandChecker{cfg: cfg, root: root, project: &project{root: root}}
+	c.project.findBins()
+	for _, glob := range cfg.Files {
+		re, err := globRegexp(glob)
+		if err != nil {
+			return nil, fmt.Errorf("checker %s: %w", cfg.Name, err)
+		}
+		c.files = append(c.files, re)
+	}
+	if cfg.Format == "regex" {
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("checker %s: pattern: %w", cfg.Name, err)
+		}
+		c.pattern = re
+	}
+	return c, nil
+}
+
+func (c *commandChecker) Name() string         { return c.cfg.Name }
+func (c *commandChecker) Extensions() []string { return nil }
+
+func (c *commandChecker) Priority() Priority {
+	if c.cfg.Fix {
+		return Formatter
+	}
+	return Linter
+}
+
+// Matches reports whether one of the checker's globs matches the file.
+// Globs without a slash match the file name, the others its path relative
+// to the project root.
+func (c *commandChecker) Matches(filePath string) bool {
+	rel, err := filepath.Rel(c.root, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = ""
+	}
+	for i, re := range c.files {
+		if !strings.Contains(c.cfg.Files[i], "/") {
+			if re.MatchString(filepath.Base(filePath)) {
+				return true
+			}
+		} else if rel != "" && re.MatchString(filepath.ToSlash(rel)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *commandChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	return runTools(ctx, filePath, []tool{{name: c.cfg.Name, fix: c.cfg.Fix, run: c.run}})
+}
+
+func (c *commandChecker) run(ctx context.Context, filePath string, result *Result) error {
+	args, err := c.args(filePath)
+	if err != nil {
+		return err
+	}
+	// The command may name a tool installed in the project, such as eslint
+	cmd := c.project.command(ctx, args[0], args[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run() // Lint commands exit non-zero when they find problems
+
+	out := stdout.Bytes()
+	if len(bytes.TrimSpace(out)) == 0 {
+		out = stderr.Bytes()
+	}
+	var found []finding
+	if len(bytes.TrimSpace(out)) > 0 {
+		if found, err = c.parse(out); err != nil {
+			return err
+		}
+	}
+	if len(found) == 0 && runErr != nil {
+		// The command failed without reporting problems, e.g. it wasn't found
+		return fmt.Errorf("%w: %s", runErr, strings.TrimSpace(stderr.String()))
+	}
+
+	for _, f := range found {
+		d := f.Diagnostic
+		if d.File == "" {
+			d.File = filePath
+		} else if !filepath.IsAbs(d.File) {
+			d.File = filepath.Join(c.root, d.File)
+		}
+		d.Source = c.cfg.Name
+		severity := f.severity
+		if severity == "" {
+			severity = cmp.Or(c.cfg.Severity, "error")
+		}
+		if isError(severity) {
+			result.Errors = append(result.Errors, d)
+		} else {
+			result.Warnings = append(result.Warnings, d)
+		}
+	}
+	if c.cfg.Fix && runErr == nil {
+		result.Fixed = true
+	}
+	return nil
+}
+
+// args returns the command's arguments, with the placeholders replaced.
+func (c *commandChecker) args(filePath string) ([]string, error) {
+	fields, err := shell.Fields(c.cfg.Command, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse command: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	r := strings.NewReplacer("{file}", filePath, "{dir}", filepath.Dir(filePath), "{root}", c.root)
+	for i, f := range fields {
+		fields[i] = r.Replace(f)
+	}
+	return fields, nil
+}
+
+// globRegexp compiles a file glob. "**/" matches any number of
+// directories, "*" and "?" don't match "/", and "[...]" is a class.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch ch := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case ch == '*':
+			sb.WriteString("[^/]*")
+		case ch == '?':
+			sb.WriteString("[^/]")
+		case ch == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("glob %q: unterminated [", glob)
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("glob %q: %w", glob, err)
+	}
+	return re, nil
+}
+
+// finding is a diagnostic with the severity the tool gave it, if any.
+type finding struct {
+	Diagnostic
+	severity string
+}
+
+// parse parses the command's output in the configured format.
+func (c *commandChecker) parse(out []byte) ([]finding, error) {
+	switch c.cfg.Format {
+	case "regex":
+		return parseRegex(string(out), c.pattern), nil
+	case "sarif":
+		errs, warnings, err := parseSARIF(out, c.cfg.Name)
+		return findings(errs, warnings), err
+	case "checkstyle":
+		errs, warnings, err := parseCheckstyle(out, c.cfg.Name)
+		return findings(errs, warnings), err
+	case "json":
+		return parseFindingsJSON(out)
+	}
+	return nil, fmt.Errorf("unknown format %q", c.cfg.Format)
+}
+
+// findings returns errors and warnings as findings.
+func findings(errs, warnings []Diagnostic) []finding {
+	var out []finding
+	for _, d := range errs {
+		out = append(out, finding{d, "error"})
+	}
+	for _, d := range warnings {
+		out = append(out, finding{d, "warning"})
+	}
+	return out
+}
+
+// isError reports whether a severity, as tools name them, is an error.
+func isError(severity string) bool {
+	switch strings.ToLower(severity) {
+	case "error", "err", "e", "fatal", "failure", "critical", "high":
+		return true
+	}
+	return false
+}
+
+// parseRegex parses output with a pattern, one match per line. The named
+// groups file, line, column, message, rule and severity are used.
+func parseRegex(out string, re *regexp.Regexp) []finding {
+	var found []finding
+	for _, line := range strings.Split(out, "\n") {
+		m := re.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		var f finding
+		for i, name := range re.SubexpNames() {
+			switch name {
+			case "file":
+				f.File = m[i]
+			case "line":
+				f.Line, _ = strconv.Atoi(m[i])
+			case "column":
+				f.Column, _ = strconv.Atoi(m[i])
+			case "message":
+				f.Message = strings.TrimSpace(m[i])
+			case "rule":
+				f.Rule = m[i]
+			case "severity":
+				f.severity = m[i]
+			}
+		}
+		found = append(found, f)
+	}
+	return found
+}
+
+// parseFindingsJSON parses a JSON array of problems with the fields file,
+// line, column, message, rule and severity.
+func parseFindingsJSON(data []byte) ([]finding, error) {
+	var items []struct {
+		File     string `json:"file"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+		Message  string `json:"message"`
+		Rule     string `json:"rule"`
+		Severity string `json:"severity"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("parse JSON output: %w", err)
+	}
+	var found []finding
+	for _, it := range items {
+		found = append(found, finding{
+			Diagnostic: Diagnostic{File: it.File, Line: it.Line, Column: it.Column, Message: it.Message, Rule: it.Rule},
+			severity:   it.Severity,
+		})
+	}
+	return found, nil
+}
@@ -0,0 +1,148 @@
+package checkers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob, path string
+		want       bool
+	}{
+		{"*.xlf", "messages.fr.xlf", true},
+		{"*.xlf", "translations/messages.xlf", false},
+		{"translations/*.yaml", "translations/messages.yaml", true},
+		{"translations/*.yaml", "translations/admin/messages.yaml", false},
+		{"src/**/*.graphql", "src/schema.graphql", true},
+		{"src/**/*.graphql", "src/api/v2/schema.graphql", true},
+		{"src/**", "src/a/b.txt", true},
+		{"file?.txt", "file1.txt", true},
+		{"[!a]*.txt", "b.txt", true},
+		{"[!a]*.txt", "a.txt", false},
+		{"a+b.txt", "a+b.txt", true},
+	}
+	for _, tt := range tests {
+		re, err := globRegexp(tt.glob)
+		if err != nil {
+			t.Fatalf("globRegexp(%q): %v", tt.glob, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("glob %q on %q = %v, want %v", tt.glob, tt.path, got, tt.want)
+		}
+	}
+	if _, err := globRegexp("[abc"); err == nil {
+		t.Error("unterminated class should fail")
+	}
+}
+
+func TestCommandCheckerMatches(t *testing.T) {
+	c, err := NewCommandChecker(config.CustomChecker{
+		Name:    "i18n",
+		Files:   []string{"*.xlf", "translations/**/*.yaml"},
+		Command: "true",
+		Format:  "json",
+	}, "/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := c.(PathMatcher)
+	for path, want := range map[string]bool{
+		"/p/translations/messages.fr.xlf":   true,
+		"/elsewhere/messages.xlf":           true,
+		"/p/translations/admin/labels.yaml": true,
+		"/p/config/services.yaml":           false,
+		"/elsewhere/translations/x.yaml":    false,
+	} {
+		if got := m.Matches(path); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if c.Priority() != Linter {
+		t.Errorf("Priority() = %v, want Linter", c.Priority())
+	}
+}
+
+func TestCommandCheckerArgs(t *testing.T) {
+	c := &commandChecker{cfg: config.CustomChecker{Command: `bin/lint --root={root} "{dir}" {file}`}, root: "/p"}
+	got, err := c.args("/p/my dir/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bin/lint", "--root=/p", "/p/my dir", "/p/my dir/a.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("args() = %q, want %q", got, want)
+	}
+}
+
+func TestParseRegex(t *testing.T) {
+	re := regexp.MustCompile(`^(?P<file>[^:]+):(?P<line>\d+):(?P<column>\d+): (?P<severity>\w+) (?P<rule>[\w-]+): (?P<message>.*)$`)
+	out := "a.yaml:3:5: error missing-key: key \"title\" is missing\nChecked 1 file\nb.yaml:9:1: warning unused: unused key\n"
+	got := parseRegex(out, re)
+	want := []finding{
+		{Diagnostic{File: "a.yaml", Line: 3, Column: 5, Message: `key "title" is missing`, Rule: "missing-key"}, "error"},
+		{Diagnostic{File: "b.yaml", Line: 9, Column: 1, Message: "unused key", Rule: "unused"}, "warning"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRegex() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFindingsJSON(t *testing.T) {
+	out := `[{"file":"schema.graphql","line":4,"message":"Unknown type Foo","rule":"known-types","severity":"error"},{"message":"deprecated field"}]`
+	got, err := parseFindingsJSON([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].severity != "error" || got[0].Line != 4 || got[1].severity != "" {
+		t.Errorf("parseFindingsJSON() = %+v", got)
+	}
+}
+
+func TestCommandCheckerCheck(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "messages.xlf")
+	os.WriteFile(file, []byte("<xliff/>"), 0o644)
+	script := filepath.Join(root, "lint.sh")
+	os.WriteFile(script, []byte(`#!/bin/sh
+echo "messages.xlf:2: E missing target"
+echo "messages.xlf:7: W empty note"
+echo "messages.xlf:9: untranslated"
+exit 1
+`), 0o755)
+
+	c, err := NewCommandChecker(config.CustomChecker{
+		Name:     "xliff",
+		Files:    []string{"*.xlf"},
+		Command:  script + " {file}",
+		Format:   "regex",
+		Pattern:  `^(?P<file>[^:]+):(?P<line>\d+): (?:(?P<severity>[EW]) )?(?P<message>.*)$`,
+		Severity: "warning",
+	}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.Check(context.Background(), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].File != file || result.Errors[0].Line != 2 || result.Errors[0].Source != "xliff" {
+		t.Errorf("errors = %+v, want missing target at %s:2", result.Errors, file)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("warnings = %+v, want two, using the default severity for the last", result.Warnings)
+	}
+
+	// A command that fails without output is a checker error
+	c, _ = NewCommandChecker(config.CustomChecker{Name: "missing", Files: []string{"*.xlf"}, Command: filepath.Join(root, "nope") + " {file}", Format: "json"}, root)
+	if _, err := c.Check(context.Background(), file); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("err = %v, want the command's failure", err)
+	}
+}
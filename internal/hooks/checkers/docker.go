@@ -0,0 +1,85 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+)
+
+type dockerChecker struct{}
+
+func init() {
+	Register(&dockerChecker{})
+}
+
+func (c *dockerChecker) Name() string         { return "dockerfile" }
+func (c *dockerChecker) Extensions() []string { return []string{".dockerfile"} }
+func (c *dockerChecker) Priority() Priority   { return Linter }
+
+// Basenames matches Dockerfiles, which rarely have an extension.
+func (c *dockerChecker) Basenames() []string {
+	return []string{"Dockerfile", "Dockerfile.*", "*.Dockerfile", "Containerfile"}
+}
+
+func (c *dockerChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	var tools []tool
+	if toolExists("hadolint") {
+		tools = append(tools, tool{name: "hadolint", run: c.runHadolint})
+	}
+	return runTools(ctx, filePath, tools)
+}
+
+func (c *dockerChecker) runHadolint(ctx context.Context, filePath string, result *Result) error {
+	cmd := exec.CommandContext(ctx, "hadolint", "--format", "json", filePath)
+	cmd.Dir = filepath.Dir(filePath) // hadolint reads .hadolint.yaml from here
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Run() // hadolint exits non-zero when it finds problems
+
+	if stdout.Len() == 0 {
+		return nil
+	}
+	errs, warnings, err := parseHadolint(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// parseHadolint parses the output of hadolint --format json. Info and
+// style notes are dropped.
+func parseHadolint(data []byte) (errs, warnings []Diagnostic, err error) {
+	var problems []struct {
+		File    string `json:"file"`
+		Line    int    `json:"line"`
+		Column  int    `json:"column"`
+		Level   string `json:"level"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &problems); err != nil {
+		return nil, nil, fmt.Errorf("parse hadolint output: %w", err)
+	}
+	for _, p := range problems {
+		d := Diagnostic{
+			File:    p.File,
+			Line:    p.Line,
+			Column:  p.Column,
+			Message: p.Message,
+			Source:  "hadolint",
+			Rule:    p.Code,
+		}
+		switch p.Level {
+		case "error":
+			errs = append(errs, d)
+		case "warning":
+			warnings = append(warnings, d)
+		}
+	}
+	return errs, warnings, nil
+}
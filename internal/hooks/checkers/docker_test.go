@@ -0,0 +1,23 @@
+package checkers
+
+import (
+	"testing"
+)
+
+func TestParseHadolint(t *testing.T) {
+	out := `[
+		{"code":"DL3008","column":1,"file":"Dockerfile","level":"warning","line":4,"message":"Pin versions in apt get install."},
+		{"code":"DL3000","column":1,"file":"Dockerfile","level":"error","line":6,"message":"Use absolute WORKDIR"},
+		{"code":"DL3059","column":1,"file":"Dockerfile","level":"info","line":8,"message":"Multiple consecutive RUN instructions."}
+	]`
+	errs, warnings, err := parseHadolint([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Rule != "DL3000" || errs[0].Line != 6 {
+		t.Errorf("errors = %+v, want DL3000 at line 6", errs)
+	}
+	if len(warnings) != 1 || warnings[0].Rule != "DL3008" {
+		t.Errorf("warnings = %+v, want DL3008 only", warnings)
+	}
+}
@@ -3,7 +3,8 @@ package checkers
 import (
 	"bytes"
 	"context"
-	"os/exec"
+	"encoding/json"
+	"fmt"
 	"strings"
 )
 
@@ -15,54 +16,120 @@ func init() {
 
 func (c *golangChecker) Name() string         { return "go" }
 func (c *golangChecker) Extensions() []string { return []string{".go"} }
+func (c *golangChecker) Priority() Priority   { return Formatter }
 
-func (c *golangChecker) Check(ctx context.Context, filePath string) (*Result, error) {
-	result := &Result{}
+// golangciConfigs are the module files golangci-lint's result depends on.
+var golangciConfigs = []string{"go.mod", ".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"}
 
-	if toolExists("gofmt") {
-		if err := c.runGofmt(ctx, filePath, result); err != nil {
-			return result, err
-		}
+// Inputs returns the module, the tools and its golangci-lint config.
+func (c *golangChecker) Inputs(filePath string) CacheInputs {
+	p := findProject(filePath, "go.mod")
+	return CacheInputs{
+		Root:    p.root,
+		Tools:   []string{p.tool("gofmt"), p.tool("golangci-lint")},
+		Configs: p.paths(golangciConfigs...),
 	}
+}
 
-	if toolExists("golangci-lint") {
-		c.runGolangciLint(ctx, filePath, result)
+// Check runs golangci-lint from the module root, where it finds the
+// module's .golangci.yml.
+func (c *golangChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	p := findProject(filePath, "go.mod")
+	var tools []tool
+	if p.tool("gofmt") != "" {
+		tools = append(tools, tool{name: "gofmt", fix: true, gate: true, run: p.runGofmt})
 	}
-
-	return result, nil
+	if p.tool("golangci-lint") != "" {
+		tools = append(tools, tool{name: "golangci-lint", run: p.runGolangciLint})
+	}
+	return runTools(ctx, filePath, tools)
 }
 
-func (c *golangChecker) runGofmt(ctx context.Context, filePath string, result *Result) error {
-	cmd := exec.CommandContext(ctx, "gofmt", "-w", filePath)
+func (p *project) runGofmt(ctx context.Context, filePath string, result *Result) error {
+	cmd := p.command(ctx, "gofmt", "-w", filePath)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		result.Errors = append(result.Errors, Diagnostic{
-			File:    filePath,
-			Message: stderr.String(),
-			Source:  "gofmt",
-		})
+		// Syntax errors, as "file:line:col: message"
+		result.Errors = append(result.Errors, parseLocationLines(stderr.String(), filePath, "gofmt")...)
 		return nil
 	}
 	result.Fixed = true
 	return nil
 }
 
-func (c *golangChecker) runGolangciLint(ctx context.Context, filePath string, result *Result) {
-	cmd := exec.CommandContext(ctx, "golangci-lint", "run", "--new-from-rev=HEAD", filePath)
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Run()
+// golangciJSONArgs select golangci-lint's JSON output: v2's flags first,
+// then v1's, which v2 removed.
+var golangciJSONArgs = [][]string{
+	{"--output.json.path=stdout", "--show-stats=false"},
+	{"--out-format", "json"},
+}
+
+func (p *project) runGolangciLint(ctx context.Context, filePath string, result *Result) error {
+	var stdout, stderr bytes.Buffer
+	var err error
+	for _, args := range golangciJSONArgs {
+		stdout.Reset()
+		stderr.Reset()
+		cmd := p.command(ctx, "golangci-lint", append(append([]string{"run"}, args...), "--new-from-rev=HEAD", filePath)...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		// golangci-lint exits non-zero when it finds issues; try the other
+		// flags if these are from another version
+		err = cmd.Run()
+		if err == nil || stdout.Len() > 0 || !strings.Contains(stderr.String(), "unknown flag") {
+			break
+		}
+	}
 
-	for _, line := range strings.Split(stdout.String(), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	if stdout.Len() == 0 {
+		if err != nil {
+			return fmt.Errorf("golangci-lint: %w: %s", err, strings.TrimSpace(stderr.String()))
 		}
-		result.Warnings = append(result.Warnings, Diagnostic{
-			File:    filePath,
-			Message: line,
+		return nil
+	}
+	errs, warnings, err := parseGolangciLint(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// parseGolangciLint parses golangci-lint's JSON output. Issues are
+// warnings unless the linter reports them with severity "error".
+func parseGolangciLint(data []byte) (errs, warnings []Diagnostic, err error) {
+	var out struct {
+		Issues []struct {
+			FromLinter string `json:"FromLinter"`
+			Text       string `json:"Text"`
+			Severity   string `json:"Severity"`
+			Pos        struct {
+				Filename string `json:"Filename"`
+				Line     int    `json:"Line"`
+				Column   int    `json:"Column"`
+			} `json:"Pos"`
+		} `json:"Issues"`
+	}
+	// Only the first value: stats or other text may follow
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&out); err != nil {
+		return nil, nil, fmt.Errorf("parse golangci-lint output: %w", err)
+	}
+	for _, issue := range out.Issues {
+		d := Diagnostic{
+			File:    issue.Pos.Filename,
+			Line:    issue.Pos.Line,
+			Column:  issue.Pos.Column,
+			Message: issue.Text,
 			Source:  "golangci-lint",
-		})
+			Rule:    issue.FromLinter,
+		}
+		if issue.Severity == "error" {
+			errs = append(errs, d)
+		} else {
+			warnings = append(warnings, d)
+		}
 	}
+	return errs, warnings, nil
 }
@@ -0,0 +1,83 @@
+package checkers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGolangciLint(t *testing.T) {
+	out := `{"Issues":[
+		{"FromLinter":"errcheck","Text":"Error return value is not checked","Severity":"","Pos":{"Filename":"main.go","Line":12,"Column":9}},
+		{"FromLinter":"typecheck","Text":"undefined: foo","Severity":"error","Pos":{"Filename":"main.go","Line":3,"Column":2}}
+	],"Report":{}}`
+	errs, warnings, err := parseGolangciLint([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWarn := []Diagnostic{{File: "main.go", Line: 12, Column: 9, Message: "Error return value is not checked", Source: "golangci-lint", Rule: "errcheck"}}
+	if !reflect.DeepEqual(warnings, wantWarn) {
+		t.Errorf("warnings = %+v, want %+v", warnings, wantWarn)
+	}
+	if len(errs) != 1 || errs[0].Rule != "typecheck" || errs[0].Line != 3 {
+		t.Errorf("errors = %+v, want the typecheck issue", errs)
+	}
+
+	if _, _, err := parseGolangciLint([]byte("level=error msg=oops")); err == nil {
+		t.Error("expected error for non-JSON output")
+	}
+}
+
+func TestRunGolangciLintVersions(t *testing.T) {
+	const issues = `echo '{"Issues":[{"FromLinter":"errcheck","Text":"unchecked","Pos":{"Filename":"main.go","Line":3}}]}'; echo '1 issues:'; exit 1`
+	tests := []struct {
+		name   string
+		script string
+		err    string // substring of the error, "" for none
+	}{
+		{"v2", `case "$*" in *--out-format*) echo "Error: unknown flag: --out-format" >&2; exit 3;; esac; ` + issues, ""},
+		{"v1", `case "$*" in *--output.json.path*) echo "Error: unknown flag: --output.json.path" >&2; exit 3;; esac; ` + issues, ""},
+		{"broken", `echo "can't load config: unsupported version" >&2; exit 3`, "unsupported version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin := t.TempDir()
+			if err := os.WriteFile(filepath.Join(bin, "golangci-lint"), []byte("#!/bin/sh\n"+tt.script+"\n"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			p := &project{root: t.TempDir()}
+			var result Result
+			err := p.runGolangciLint(context.Background(), filepath.Join(p.root, "main.go"), &result)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("error = %v, want it to mention %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Warnings) != 1 || result.Warnings[0].Rule != "errcheck" {
+				t.Errorf("warnings = %+v, want the errcheck issue", result.Warnings)
+			}
+		})
+	}
+}
+
+func TestParseLocationLines(t *testing.T) {
+	out := "main.go:4:2: expected declaration, found foo\nmain.go:9: missing return\n\nsomething else\n"
+	got := parseLocationLines(out, "fallback.go", "gofmt")
+	want := []Diagnostic{
+		{File: "main.go", Line: 4, Column: 2, Message: "expected declaration, found foo", Source: "gofmt"},
+		{File: "main.go", Line: 9, Message: "missing return", Source: "gofmt"},
+		{File: "fallback.go", Message: "something else", Source: "gofmt"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLocationLines() = %+v, want %+v", got, want)
+	}
+}
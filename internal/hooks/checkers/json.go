@@ -0,0 +1,135 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type jsonChecker struct{}
+
+func init() {
+	Register(&jsonChecker{})
+}
+
+func (c *jsonChecker) Name() string         { return "json" }
+func (c *jsonChecker) Extensions() []string { return []string{".json"} }
+func (c *jsonChecker) Priority() Priority   { return Linter }
+
+// jsoncFiles are JSON files that tools read with comments and trailing
+// commas allowed, so they aren't checked.
+var jsoncFiles = []string{"tsconfig*.json", "jsconfig*.json", "devcontainer.json", ".devcontainer.json", ".eslintrc.json", ".babelrc.json"}
+
+// Check checks that the file is valid JSON and, if it names a $schema and
+// check-jsonschema is installed, that it matches the schema.
+func (c *jsonChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	if isJSONC(filePath) {
+		return &Result{}, nil
+	}
+	tools := []tool{{name: "json", gate: true, run: c.parse}}
+	if toolExists("check-jsonschema") {
+		tools = append(tools, tool{name: "check-jsonschema", run: c.runCheckJSONSchema})
+	}
+	return runTools(ctx, filePath, tools)
+}
+
+// parse reports a syntax error in the file.
+func (c *jsonChecker) parse(ctx context.Context, filePath string, result *Result) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		result.Errors = append(result.Errors, jsonSyntaxError(data, filePath, err))
+	}
+	return nil
+}
+
+// isJSONC reports whether the file is JSON with comments.
+func isJSONC(filePath string) bool {
+	if filepath.Base(filepath.Dir(filePath)) == ".vscode" {
+		return true
+	}
+	for _, pattern := range jsoncFiles {
+		if ok, _ := filepath.Match(pattern, filepath.Base(filePath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonSyntaxError returns a diagnostic for a JSON parse error, at the line
+// and column of its offset.
+func jsonSyntaxError(data []byte, filePath string, err error) Diagnostic {
+	d := Diagnostic{File: filePath, Message: err.Error(), Source: "json"}
+	offset := int64(len(data))
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// The offset is just past the byte that failed
+		offset = syntaxErr.Offset - 1
+	}
+	before := data[:min(max(offset, 0), int64(len(data)))]
+	d.Line = bytes.Count(before, []byte("\n")) + 1
+	d.Column = len(before) - bytes.LastIndexByte(before, '\n')
+	return d
+}
+
+// schemaLocation resolves a relative $schema against the file's directory.
+// URLs are left to check-jsonschema, which fetches and caches them.
+func schemaLocation(schema, filePath string) string {
+	if strings.Contains(schema, "://") || filepath.IsAbs(schema) {
+		return schema
+	}
+	return filepath.Join(filepath.Dir(filePath), schema)
+}
+
+// runCheckJSONSchema validates the file against the schema its $schema
+// names, if any.
+func (c *jsonChecker) runCheckJSONSchema(ctx context.Context, filePath string, result *Result) error {
+	var doc struct {
+		Schema string `json:"$schema"`
+	}
+	data, _ := os.ReadFile(filePath)
+	if json.Unmarshal(data, &doc) != nil || doc.Schema == "" {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "check-jsonschema", "--schemafile", schemaLocation(doc.Schema, filePath), filePath)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stdout
+	if err := cmd.Run(); err == nil {
+		return nil
+	}
+	errs := parseCheckJSONSchema(stdout.String())
+	if len(errs) == 0 {
+		// Not a validation failure, e.g. a schema that couldn't be fetched
+		result.Warnings = append(result.Warnings, Diagnostic{File: filePath, Message: strings.TrimSpace(stdout.String()), Source: "check-jsonschema"})
+		return nil
+	}
+	result.Errors = append(result.Errors, errs...)
+	return nil
+}
+
+// schemaErrorLine matches a check-jsonschema error:
+// "  config.json::$.port: 'x' is not of type 'integer'".
+var schemaErrorLine = regexp.MustCompile(`^\s+(.+?)::(\$\S*): (.*)$`)
+
+// parseCheckJSONSchema parses check-jsonschema's text output. Errors have
+// a JSON path rather than a line, which is put in the message.
+func parseCheckJSONSchema(out string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(out, "\n") {
+		if m := schemaErrorLine.FindStringSubmatch(line); m != nil {
+			diags = append(diags, Diagnostic{File: m[1], Message: m[2] + ": " + m[3], Source: "check-jsonschema"})
+		}
+	}
+	return diags
+}
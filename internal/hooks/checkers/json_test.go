@@ -0,0 +1,73 @@
+package checkers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJSONCheck(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		line, col  int
+		wantErrors bool
+	}{
+		{"valid", write("package.json", "{\n  \"name\": \"app\"\n}\n"), 0, 0, false},
+		{"trailing comma", write("composer.json", "{\n  \"a\": 1,\n}\n"), 3, 1, true},
+		{"truncated", write("data.json", "{\n  \"a\": [1,"), 2, 10, true},
+		{"tsconfig comments", write("tsconfig.json", "{\n  // strict\n  \"strict\": true,\n}\n"), 0, 0, false},
+		{"vscode settings", write(".vscode/settings.json", "{ /* x */ }"), 0, 0, false},
+	}
+	c := &jsonChecker{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Check(context.Background(), tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantErrors {
+				if len(result.Errors) > 0 {
+					t.Errorf("errors = %+v, want none", result.Errors)
+				}
+				return
+			}
+			if len(result.Errors) != 1 || result.Errors[0].Line != tt.line || result.Errors[0].Column != tt.col {
+				t.Errorf("errors = %+v, want one at %d:%d", result.Errors, tt.line, tt.col)
+			}
+		})
+	}
+}
+
+func TestParseCheckJSONSchema(t *testing.T) {
+	out := `Schema validation errors were encountered.
+  /p/config.json::$.port: 'x' is not of type 'integer'
+  /p/config.json::$: 'name' is a required property
+`
+	want := []Diagnostic{
+		{File: "/p/config.json", Message: "$.port: 'x' is not of type 'integer'", Source: "check-jsonschema"},
+		{File: "/p/config.json", Message: "$: 'name' is a required property", Source: "check-jsonschema"},
+	}
+	if got := parseCheckJSONSchema(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCheckJSONSchema() = %+v, want %+v", got, want)
+	}
+	if got := schemaLocation("./schema.json", "/p/conf/app.json"); got != "/p/conf/schema.json" {
+		t.Errorf("schemaLocation() = %q", got)
+	}
+	if got := schemaLocation("https://json.schemastore.org/package.json", "/p/package.json"); got != "https://json.schemastore.org/package.json" {
+		t.Errorf("schemaLocation(url) = %q", got)
+	}
+}
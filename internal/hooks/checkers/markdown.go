@@ -0,0 +1,88 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type markdownChecker struct{}
+
+func init() {
+	Register(&markdownChecker{})
+}
+
+func (c *markdownChecker) Name() string         { return "markdown" }
+func (c *markdownChecker) Extensions() []string { return []string{".md", ".markdown"} }
+func (c *markdownChecker) Priority() Priority   { return Formatter }
+
+// Check runs markdownlint, from node_modules/.bin if the project has it.
+func (c *markdownChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	p := findProject(filePath)
+	var tools []tool
+	if p.tool("markdownlint") != "" {
+		tools = append(tools, tool{name: "markdownlint", fix: true, run: p.runMarkdownlint})
+	}
+	return runTools(ctx, filePath, tools)
+}
+
+// runMarkdownlint fixes what markdownlint can and reports the rest as
+// warnings: Markdown style problems shouldn't block.
+func (p *project) runMarkdownlint(ctx context.Context, filePath string, result *Result) error {
+	cmd := p.command(ctx, "markdownlint", "--fix", "--json", filePath)
+	cmd.Dir = filepath.Dir(filePath) // markdownlint looks for its config from here up
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	result.Fixed = true
+	if err == nil {
+		return nil
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(stderr.String()), "[") {
+		// markdownlint itself failed, e.g. on an invalid config
+		return fmt.Errorf("markdownlint: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	warnings, err := parseMarkdownlint(stderr.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// parseMarkdownlint parses the output of markdownlint --json.
+func parseMarkdownlint(data []byte) ([]Diagnostic, error) {
+	var problems []struct {
+		FileName        string   `json:"fileName"`
+		LineNumber      int      `json:"lineNumber"`
+		RuleNames       []string `json:"ruleNames"`
+		RuleDescription string   `json:"ruleDescription"`
+		ErrorDetail     string   `json:"errorDetail"`
+		ErrorRange      []int    `json:"errorRange"`
+	}
+	if err := json.Unmarshal(data, &problems); err != nil {
+		return nil, fmt.Errorf("parse markdownlint output: %w", err)
+	}
+	var diags []Diagnostic
+	for _, p := range problems {
+		d := Diagnostic{
+			File:    p.FileName,
+			Line:    p.LineNumber,
+			Message: p.RuleDescription,
+			Source:  "markdownlint",
+			Rule:    strings.Join(p.RuleNames, "/"),
+		}
+		if p.ErrorDetail != "" {
+			d.Message += " [" + p.ErrorDetail + "]"
+		}
+		if len(p.ErrorRange) > 0 {
+			d.Column = p.ErrorRange[0]
+		}
+		diags = append(diags, d)
+	}
+	return diags, nil
+}
@@ -0,0 +1,22 @@
+package checkers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMarkdownlint(t *testing.T) {
+	out := `[{"fileName":"/p/README.md","lineNumber":12,"ruleNames":["MD013","line-length"],"ruleDescription":"Line length","ruleInformation":"https://github.com/DavidAnson/markdownlint/blob/v0.33.0/doc/md013.md","errorDetail":"Expected: 80; Actual: 120","errorContext":null,"errorRange":[81,40]},
+{"fileName":"/p/README.md","lineNumber":3,"ruleNames":["MD041","first-line-heading"],"ruleDescription":"First line in a file should be a top-level heading","errorDetail":null,"errorContext":"Intro","errorRange":null}]`
+	got, err := parseMarkdownlint([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Diagnostic{
+		{File: "/p/README.md", Line: 12, Column: 81, Message: "Line length [Expected: 80; Actual: 120]", Source: "markdownlint", Rule: "MD013/line-length"},
+		{File: "/p/README.md", Line: 3, Message: "First line in a file should be a top-level heading", Source: "markdownlint", Rule: "MD041/first-line-heading"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMarkdownlint() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,344 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type phpChecker struct{}
+
+func init() {
+	Register(&phpChecker{})
+}
+
+func (c *phpChecker) Name() string { return "php" }
+
+// Extensions include the Drupal module and theme file types.
+func (c *phpChecker) Extensions() []string {
+	return []string{".php", ".module", ".inc", ".install", ".theme"}
+}
+
+// Config files the PHP tools read from the project root, in the order the
+// tools look for them.
+var (
+	phpCsFixerConfigs = []string{".php-cs-fixer.php", ".php-cs-fixer.dist.php"}
+	phpcsConfigs      = []string{".phpcs.xml", "phpcs.xml", ".phpcs.xml.dist", "phpcs.xml.dist"}
+	phpstanConfigs    = []string{"phpstan.neon", "phpstan.neon.dist", "phpstan.dist.neon"}
+	psalmConfigs      = []string{"psalm.xml", "psalm.xml.dist"}
+)
+
+// phpProject is the Composer project a file belongs to.
+type phpProject struct {
+	*project
+}
+
+// newPHPProject returns the project of filePath: the nearest directory
+// above it with a composer.json.
+func newPHPProject(filePath string) *phpProject {
+	return &phpProject{findProject(filePath, "composer.json")}
+}
+
+func (c *phpChecker) Priority() Priority { return Formatter }
+
+// Inputs returns the project, the tools and its Composer files and tool
+// configs.
+func (c *phpChecker) Inputs(filePath string) CacheInputs {
+	p := newPHPProject(filePath)
+	in := CacheInputs{Root: p.root, Configs: p.paths("composer.json", "composer.lock")}
+	for _, name := range []string{"php", "php-cs-fixer", "phpcbf", "phpcs", "phpstan", "psalm"} {
+		in.Tools = append(in.Tools, p.tool(name))
+	}
+	for _, names := range [][]string{phpCsFixerConfigs, phpcsConfigs, phpstanConfigs, psalmConfigs} {
+		in.Configs = append(in.Configs, p.paths(names...)...)
+	}
+	return in
+}
+
+// Check lints the file for syntax errors, fixes its style and runs the
+// static analysers. php-cs-fixer is used when the project configures it or
+// has no phpcs config; otherwise phpcbf and phpcs apply the phpcs config.
+// phpstan and psalm only run when the project configures them, as both
+// need a level or baseline to give useful results.
+func (c *phpChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	p := newPHPProject(filePath)
+	var tools []tool
+
+	if p.tool("php") != "" {
+		tools = append(tools, tool{name: "php", gate: true, run: p.lint})
+	}
+
+	csFixer, csFixerConfig := p.tool("php-cs-fixer"), p.config(phpCsFixerConfigs)
+	phpcsConfig := p.config(phpcsConfigs)
+	useCsFixer := csFixer != "" && (csFixerConfig != "" || phpcsConfig == "")
+
+	if useCsFixer {
+		tools = append(tools, tool{name: "php-cs-fixer", fix: true, run: func(ctx context.Context, filePath string, result *Result) error {
+			return p.runCsFixer(ctx, csFixer, csFixerConfig, filePath, result)
+		}})
+	} else if phpcbf := p.tool("phpcbf"); phpcbf != "" {
+		tools = append(tools, tool{name: "phpcbf", fix: true, run: func(ctx context.Context, filePath string, result *Result) error {
+			return p.runPhpcbf(ctx, phpcbf, phpcsConfig, filePath, result)
+		}})
+	}
+
+	if phpcs := p.tool("phpcs"); phpcs != "" && (phpcsConfig != "" || !useCsFixer) {
+		tools = append(tools, tool{name: "phpcs", run: func(ctx context.Context, filePath string, result *Result) error {
+			return p.runPhpcs(ctx, phpcs, phpcsConfig, filePath, result)
+		}})
+	}
+
+	if phpstan, conf := p.tool("phpstan"), p.config(phpstanConfigs); phpstan != "" && conf != "" {
+		tools = append(tools, tool{name: "phpstan", run: func(ctx context.Context, filePath string, result *Result) error {
+			return p.runPhpstan(ctx, phpstan, conf, filePath, result)
+		}})
+	} else if psalm, conf := p.tool("psalm"), p.config(psalmConfigs); psalm != "" && conf != "" {
+		tools = append(tools, tool{name: "psalm", run: func(ctx context.Context, filePath string, result *Result) error {
+			return p.runPsalm(ctx, psalm, conf, filePath, result)
+		}})
+	}
+
+	return runTools(ctx, filePath, tools)
+}
+
+// lint checks the file's syntax with php -l.
+func (p *phpProject) lint(ctx context.Context, filePath string, result *Result) error {
+	out, err := p.command(ctx, "php", "-d", "display_errors=stdout", "-d", "log_errors=0", "-l", filePath).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	diags := parsePhpLint(string(out))
+	if len(diags) == 0 {
+		diags = parseLocationLines(string(out), filePath, "php")
+	}
+	result.Errors = append(result.Errors, diags...)
+	return nil
+}
+
+// phpLintLine matches a php -l error:
+// "PHP Parse error:  syntax error, unexpected token "}" in app.php on line 3".
+var phpLintLine = regexp.MustCompile(`^(?:PHP )?(?:Parse|Fatal) error:\s+(.*) in (.+) on line (\d+)$`)
+
+// parsePhpLint parses php -l output. PHP may print each error twice, to
+// the log and to the display, so duplicates are dropped.
+func parsePhpLint(out string) []Diagnostic {
+	var diags []Diagnostic
+	seen := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		m := phpLintLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || seen[m[2]+":"+m[3]+":"+m[1]] {
+			continue
+		}
+		seen[m[2]+":"+m[3]+":"+m[1]] = true
+		d := Diagnostic{File: m[2], Message: m[1], Source: "php"}
+		d.Line, _ = strconv.Atoi(m[3])
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+func (p *phpProject) runCsFixer(ctx context.Context, tool, conf, filePath string, result *Result) error {
+	args := []string{"fix", "--quiet", "--no-interaction"}
+	if conf != "" {
+		// Only fix the file, even if the config's finder doesn't include it
+		args = append(args, "--config="+conf, "--path-mode=intersection")
+	}
+	cmd := p.command(ctx, tool, append(args, filePath)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("php-cs-fixer: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	result.Fixed = true
+	return nil
+}
+
+func (p *phpProject) runPhpcbf(ctx context.Context, tool, conf, filePath string, result *Result) error {
+	cmd := p.command(ctx, tool, append(phpcsArgs(conf), filePath)...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	// phpcbf exits 1 when it fixed everything and 2 when some problems
+	// remain, which phpcs reports
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() <= 2 {
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("phpcbf: %w: %s", err, strings.TrimSpace(stdout.String()))
+	}
+	result.Fixed = true
+	return nil
+}
+
+func (p *phpProject) runPhpcs(ctx context.Context, tool, conf, filePath string, result *Result) error {
+	cmd := p.command(ctx, tool, append(phpcsArgs(conf), "--report=json", filePath)...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Run() // phpcs exits non-zero when it finds problems
+
+	if stdout.Len() == 0 {
+		return nil
+	}
+	errs, warnings, err := parsePhpcs(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// phpcsArgs returns the phpcs and phpcbf arguments for the project's
+// config, if it has one.
+func phpcsArgs(conf string) []string {
+	args := []string{"-q", "--no-colors"}
+	if conf != "" {
+		args = append(args, "--standard="+conf)
+	}
+	return args
+}
+
+// parsePhpcs parses the output of phpcs --report=json.
+func parsePhpcs(data []byte) (errs, warnings []Diagnostic, err error) {
+	var out struct {
+		Files map[string]struct {
+			Messages []struct {
+				Message string `json:"message"`
+				Source  string `json:"source"`
+				Type    string `json:"type"`
+				Line    int    `json:"line"`
+				Column  int    `json:"column"`
+			} `json:"messages"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, nil, fmt.Errorf("parse phpcs output: %w", err)
+	}
+	for _, file := range slices.Sorted(maps.Keys(out.Files)) {
+		for _, m := range out.Files[file].Messages {
+			d := Diagnostic{
+				File:    file,
+				Line:    m.Line,
+				Column:  m.Column,
+				Message: m.Message,
+				Source:  "phpcs",
+				Rule:    m.Source,
+			}
+			if m.Type == "ERROR" {
+				errs = append(errs, d)
+			} else {
+				warnings = append(warnings, d)
+			}
+		}
+	}
+	return errs, warnings, nil
+}
+
+func (p *phpProject) runPhpstan(ctx context.Context, tool, conf, filePath string, result *Result) error {
+	cmd := p.command(ctx, tool, "analyse", "--configuration="+conf, "--error-format=json", "--no-progress", "--no-interaction", filePath)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Run() // phpstan exits non-zero when it finds errors
+
+	if stdout.Len() == 0 {
+		return nil
+	}
+	errs, err := parsePhpstan(stdout.Bytes(), filePath)
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	return nil
+}
+
+// parsePhpstan parses the output of phpstan analyse --error-format=json.
+// Errors that aren't about a file, such as config problems, are reported
+// against filePath.
+func parsePhpstan(data []byte, filePath string) ([]Diagnostic, error) {
+	var out struct {
+		Files map[string]struct {
+			Messages []struct {
+				Message    string `json:"message"`
+				Line       int    `json:"line"`
+				Identifier string `json:"identifier"`
+			} `json:"messages"`
+		} `json:"files"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("parse phpstan output: %w", err)
+	}
+	var diags []Diagnostic
+	for _, file := range slices.Sorted(maps.Keys(out.Files)) {
+		for _, m := range out.Files[file].Messages {
+			diags = append(diags, Diagnostic{
+				File:    file,
+				Line:    m.Line,
+				Message: m.Message,
+				Source:  "phpstan",
+				Rule:    m.Identifier,
+			})
+		}
+	}
+	for _, e := range out.Errors {
+		diags = append(diags, Diagnostic{File: filePath, Message: e, Source: "phpstan"})
+	}
+	return diags, nil
+}
+
+func (p *phpProject) runPsalm(ctx context.Context, tool, conf, filePath string, result *Result) error {
+	cmd := p.command(ctx, tool, "--config="+conf, "--output-format=json", "--no-progress", "--no-cache", filePath)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Run() // psalm exits non-zero when it finds errors
+
+	if stdout.Len() == 0 {
+		return nil
+	}
+	errs, warnings, err := parsePsalm(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// parsePsalm parses the output of psalm --output-format=json. Issues below
+// the config's error level have severity "info" and become warnings.
+func parsePsalm(data []byte) (errs, warnings []Diagnostic, err error) {
+	var issues []struct {
+		Severity   string `json:"severity"`
+		Type       string `json:"type"`
+		Message    string `json:"message"`
+		FilePath   string `json:"file_path"`
+		LineFrom   int    `json:"line_from"`
+		ColumnFrom int    `json:"column_from"`
+	}
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, nil, fmt.Errorf("parse psalm output: %w", err)
+	}
+	for _, i := range issues {
+		d := Diagnostic{
+			File:    i.FilePath,
+			Line:    i.LineFrom,
+			Column:  i.ColumnFrom,
+			Message: i.Message,
+			Source:  "psalm",
+			Rule:    i.Type,
+		}
+		if i.Severity == "error" {
+			errs = append(errs, d)
+		} else {
+			warnings = append(warnings, d)
+		}
+	}
+	return errs, warnings, nil
+}
@@ -0,0 +1,114 @@
+package checkers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePhpLint(t *testing.T) {
+	out := `PHP Parse error:  syntax error, unexpected token "}" in /p/src/App.php on line 12
+Parse error: syntax error, unexpected token "}" in /p/src/App.php on line 12
+Errors parsing /p/src/App.php
+`
+	want := []Diagnostic{{File: "/p/src/App.php", Line: 12, Message: `syntax error, unexpected token "}"`, Source: "php"}}
+	if got := parsePhpLint(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePhpLint() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePhpcs(t *testing.T) {
+	out := `{"totals":{"errors":1,"warnings":1,"fixable":0},"files":{"/p/web/modules/custom/foo/foo.module":{"errors":1,"warnings":1,"messages":[
+		{"message":"Missing function doc comment","source":"Drupal.Commenting.FunctionComment.Missing","severity":5,"fixable":false,"type":"ERROR","line":8,"column":1},
+		{"message":"Line exceeds 80 characters; contains 94 characters","source":"Drupal.Files.LineLength.TooLong","severity":5,"fixable":false,"type":"WARNING","line":10,"column":94}
+	]}}}`
+	errs, warnings, err := parsePhpcs([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Line != 8 || errs[0].Rule != "Drupal.Commenting.FunctionComment.Missing" {
+		t.Errorf("errors = %+v, want one at line 8", errs)
+	}
+	if len(warnings) != 1 || warnings[0].Column != 94 || warnings[0].Source != "phpcs" {
+		t.Errorf("warnings = %+v, want one at 10:94", warnings)
+	}
+}
+
+func TestParsePhpstan(t *testing.T) {
+	out := `{"totals":{"errors":1,"file_errors":1},"files":{"/p/src/Controller/HomeController.php":{"errors":1,"messages":[
+		{"message":"Undefined variable: $foo","line":21,"ignorable":true,"identifier":"variable.undefined"}
+	]}},"errors":["Ignored error pattern #^Foo$# was not matched in reported errors."]}`
+	got, err := parsePhpstan([]byte(out), "/p/src/Controller/HomeController.php")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Diagnostic{
+		{File: "/p/src/Controller/HomeController.php", Line: 21, Message: "Undefined variable: $foo", Source: "phpstan", Rule: "variable.undefined"},
+		{File: "/p/src/Controller/HomeController.php", Message: "Ignored error pattern #^Foo$# was not matched in reported errors.", Source: "phpstan"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePhpstan() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePsalm(t *testing.T) {
+	out := `[
+		{"severity":"error","line_from":7,"line_to":7,"type":"UndefinedClass","message":"Class Foo does not exist","file_name":"src/App.php","file_path":"/p/src/App.php","column_from":9,"column_to":12},
+		{"severity":"info","line_from":3,"line_to":3,"type":"MissingReturnType","message":"Method App::run does not have a return type","file_name":"src/App.php","file_path":"/p/src/App.php","column_from":21,"column_to":24}
+	]`
+	errs, warnings, err := parsePsalm([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Line != 7 || errs[0].Column != 9 || errs[0].Rule != "UndefinedClass" {
+		t.Errorf("errors = %+v, want one at 7:9", errs)
+	}
+	if len(warnings) != 1 || warnings[0].Rule != "MissingReturnType" {
+		t.Errorf("warnings = %+v, want MissingReturnType", warnings)
+	}
+}
+
+func TestPHPProject(t *testing.T) {
+	root := t.TempDir()
+	module := filepath.Join(root, "web", "modules", "custom", "foo")
+	for _, dir := range []string{module, filepath.Join(root, "vendor", "bin")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, mode := range map[string]os.FileMode{
+		"composer.json":                     0o644,
+		"phpcs.xml.dist":                    0o644,
+		"vendor/bin/phpcs":                  0o755,
+		"vendor/bin/not-a-tool":             0o644,
+		"web/modules/custom/foo/foo.module": 0o644,
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := newPHPProject(filepath.Join(module, "foo.module"))
+	if p.root != root {
+		t.Fatalf("root = %q, want %q", p.root, root)
+	}
+	if got, want := p.tool("phpcs"), filepath.Join(root, "vendor", "bin", "phpcs"); got != want {
+		t.Errorf("tool(phpcs) = %q, want %q", got, want)
+	}
+	if got := p.tool("not-a-tool"); got == filepath.Join(root, "vendor", "bin", "not-a-tool") {
+		t.Error("tool() returned a file that isn't executable")
+	}
+	if got, want := p.config(phpcsConfigs), filepath.Join(root, "phpcs.xml.dist"); got != want {
+		t.Errorf("config(phpcs) = %q, want %q", got, want)
+	}
+	if got := p.config(phpstanConfigs); got != "" {
+		t.Errorf("config(phpstan) = %q, want none", got)
+	}
+
+	// Without composer.json, the file's directory is the root
+	other := t.TempDir()
+	if p := newPHPProject(filepath.Join(other, "index.php")); p.root != other {
+		t.Errorf("root without composer.json = %q, want %q", p.root, other)
+	}
+}
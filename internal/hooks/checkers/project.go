@@ -0,0 +1,173 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// projectMarkers are the files that mark a project root, for checkers
+// whose tools may come from any ecosystem.
+var projectMarkers = []string{"package.json", "go.mod", "pyproject.toml", "composer.json"}
+
+// project is the project a file belongs to, and where its tools are
+// installed.
+type project struct {
+	root string   // Directory with a marker file, or the file's directory
+	bins []string // Project-local tool directories, nearest first
+	venv string   // Python virtual environment, if any
+}
+
+// findProject returns the project of filePath: the nearest directory above
+// it with one of the marker files, or projectMarkers if none are given.
+func findProject(filePath string, markers ...string) *project {
+	if len(markers) == 0 {
+		markers = projectMarkers
+	}
+	dir := filepath.Dir(filePath)
+	root := dir
+	for d := dir; ; {
+		if slices.ContainsFunc(markers, func(m string) bool { return exists(filepath.Join(d, m)) }) {
+			root = d
+			break
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+	p := &project{root: root}
+	p.findBins()
+	return p
+}
+
+// findBins collects the directories project-local tools are installed in:
+// node_modules/.bin here and in parent directories (for workspaces),
+// Composer's vendor/bin, and the bin directory of the Python environment
+// uv or Poetry would run tools in.
+func (p *project) findBins() {
+	for d := p.root; ; {
+		if bin := filepath.Join(d, "node_modules", ".bin"); isDir(bin) {
+			p.bins = append(p.bins, bin)
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+	if bin := filepath.Join(p.root, "vendor", "bin"); isDir(bin) {
+		p.bins = append(p.bins, bin)
+	}
+	if p.venv = p.pythonEnv(); p.venv != "" {
+		p.bins = append(p.bins, filepath.Join(p.venv, "bin"))
+	}
+}
+
+// pythonEnv returns the project's virtual environment: the one uv uses
+// (UV_PROJECT_ENVIRONMENT, or .venv), a venv directory, or the one Poetry
+// manages for the project.
+func (p *project) pythonEnv() string {
+	if !exists(filepath.Join(p.root, "pyproject.toml")) {
+		return ""
+	}
+	candidates := []string{".venv", "venv"}
+	if env := os.Getenv("UV_PROJECT_ENVIRONMENT"); env != "" {
+		candidates = append([]string{env}, candidates...)
+	}
+	for _, env := range candidates {
+		if !filepath.IsAbs(env) {
+			env = filepath.Join(p.root, env)
+		}
+		if isDir(filepath.Join(env, "bin")) {
+			return env
+		}
+	}
+	if exists(filepath.Join(p.root, "poetry.lock")) && toolExists("poetry") {
+		// Poetry keeps environments outside the project by default
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "poetry", "env", "info", "--path")
+		cmd.Dir = p.root
+		if out, err := cmd.Output(); err == nil {
+			if env := string(bytes.TrimSpace(out)); env != "" && isDir(filepath.Join(env, "bin")) {
+				return env
+			}
+		}
+	}
+	return ""
+}
+
+// tool returns the path of a tool, preferring the project's own
+// installation over PATH, or "" if it isn't installed.
+func (p *project) tool(name string) string {
+	for _, bin := range p.bins {
+		local := filepath.Join(bin, name)
+		if info, err := os.Stat(local); err == nil && !info.IsDir() && info.Mode()&0o111 != 0 {
+			return local
+		}
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		return path
+	}
+	return ""
+}
+
+// config returns the first of the config files that exists in the project
+// root, or "".
+func (p *project) config(names []string) string {
+	for _, name := range names {
+		path := filepath.Join(p.root, name)
+		if exists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// paths returns the paths of files in the project root.
+func (p *project) paths(names ...string) []string {
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(p.root, name)
+	}
+	return paths
+}
+
+// command returns a command for a tool that runs in the project root, so
+// the tool finds the project's config, with the project's tool directories
+// first on PATH. A tool given by name, rather than by path, is resolved
+// with tool.
+func (p *project) command(ctx context.Context, tool string, args ...string) *exec.Cmd {
+	if filepath.Base(tool) == tool {
+		if path := p.tool(tool); path != "" {
+			tool = path
+		}
+	}
+	cmd := exec.CommandContext(ctx, tool, args...)
+	cmd.Dir = p.root
+	if len(p.bins) > 0 {
+		path := strings.Join(append(slices.Clone(p.bins), os.Getenv("PATH")), string(os.PathListSeparator))
+		cmd.Env = append(os.Environ(), "PATH="+path)
+		if p.venv != "" {
+			cmd.Env = append(cmd.Env, "VIRTUAL_ENV="+p.venv)
+		}
+	}
+	return cmd
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
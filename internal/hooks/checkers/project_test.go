@@ -0,0 +1,112 @@
+package checkers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files under root with the given modes.
+func writeTree(t *testing.T, root string, files map[string]os.FileMode) {
+	t.Helper()
+	for name, mode := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindProject(t *testing.T) {
+	t.Setenv("UV_PROJECT_ENVIRONMENT", "")
+	root := t.TempDir()
+	writeTree(t, root, map[string]os.FileMode{
+		"package.json":                              0o644,
+		"node_modules/.bin/prettier":                0o755,
+		"packages/app/package.json":                 0o644,
+		"packages/app/node_modules/.bin/eslint":     0o755,
+		"packages/app/src/index.ts":                 0o644,
+		"services/api/pyproject.toml":               0o644,
+		"services/api/.venv/bin/ruff":               0o755,
+		"services/api/src/api/main.py":              0o644,
+		"services/api/node_modules/.bin/not-a-tool": 0o644,
+	})
+
+	app := findProject(filepath.Join(root, "packages", "app", "src", "index.ts"), "package.json")
+	if want := filepath.Join(root, "packages", "app"); app.root != want {
+		t.Fatalf("root = %q, want %q", app.root, want)
+	}
+	if got, want := app.tool("eslint"), filepath.Join(root, "packages", "app", "node_modules", ".bin", "eslint"); got != want {
+		t.Errorf("tool(eslint) = %q, want %q", got, want)
+	}
+	// Workspaces hoist tools to the root's node_modules
+	if got, want := app.tool("prettier"), filepath.Join(root, "node_modules", ".bin", "prettier"); got != want {
+		t.Errorf("tool(prettier) = %q, want %q", got, want)
+	}
+
+	api := findProject(filepath.Join(root, "services", "api", "src", "api", "main.py"))
+	if want := filepath.Join(root, "services", "api"); api.root != want {
+		t.Fatalf("root = %q, want %q", api.root, want)
+	}
+	if got, want := api.tool("ruff"), filepath.Join(api.root, ".venv", "bin", "ruff"); got != want {
+		t.Errorf("tool(ruff) = %q, want %q", got, want)
+	}
+	if got := api.tool("not-a-tool"); strings.HasPrefix(got, root) {
+		t.Errorf("tool() returned a file that isn't executable: %q", got)
+	}
+
+	// Without a marker, the file's directory is the root
+	other := t.TempDir()
+	if p := findProject(filepath.Join(other, "script.py"), "pyproject.toml"); p.root != other || len(p.bins) != 0 {
+		t.Errorf("project without marker = %+v, want root %q and no bins", p, other)
+	}
+}
+
+func TestFindProjectUVEnvironment(t *testing.T) {
+	root := t.TempDir()
+	env := t.TempDir()
+	writeTree(t, root, map[string]os.FileMode{"pyproject.toml": 0o644, ".venv/bin/ruff": 0o755})
+	writeTree(t, env, map[string]os.FileMode{"bin/ruff": 0o755})
+	t.Setenv("UV_PROJECT_ENVIRONMENT", env)
+
+	p := findProject(filepath.Join(root, "main.py"))
+	if p.venv != env {
+		t.Errorf("venv = %q, want %q", p.venv, env)
+	}
+	if got, want := p.tool("ruff"), filepath.Join(env, "bin", "ruff"); got != want {
+		t.Errorf("tool(ruff) = %q, want %q", got, want)
+	}
+}
+
+func TestProjectCommand(t *testing.T) {
+	t.Setenv("UV_PROJECT_ENVIRONMENT", "")
+	root := t.TempDir()
+	writeTree(t, root, map[string]os.FileMode{"pyproject.toml": 0o644, ".venv/bin/ruff": 0o755})
+	p := findProject(filepath.Join(root, "main.py"))
+
+	cmd := p.command(context.Background(), "ruff", "check")
+	if want := filepath.Join(root, ".venv", "bin", "ruff"); cmd.Path != want {
+		t.Errorf("Path = %q, want %q", cmd.Path, want)
+	}
+	if cmd.Dir != root {
+		t.Errorf("Dir = %q, want %q", cmd.Dir, root)
+	}
+	venv := filepath.Join(root, ".venv")
+	if !slices.Contains(cmd.Env, "VIRTUAL_ENV="+venv) {
+		t.Error("Env has no VIRTUAL_ENV")
+	}
+	if !slices.ContainsFunc(cmd.Env, func(e string) bool { return strings.HasPrefix(e, "PATH="+filepath.Join(venv, "bin")) }) {
+		t.Error("Env doesn't put the environment first on PATH")
+	}
+
+	// A relative path is left for the command to resolve in the root
+	if cmd := p.command(context.Background(), "bin/console", "lint"); !strings.HasSuffix(cmd.Path, "bin/console") {
+		t.Errorf("Path = %q, want bin/console", cmd.Path)
+	}
+}
@@ -3,9 +3,8 @@ package checkers
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
-	"strings"
 )
 
 type pythonChecker struct{}
@@ -16,45 +15,53 @@ func init() {
 
 func (c *pythonChecker) Name() string         { return "python" }
 func (c *pythonChecker) Extensions() []string { return []string{".py"} }
+func (c *pythonChecker) Priority() Priority   { return Formatter }
 
-func (c *pythonChecker) Check(ctx context.Context, filePath string) (*Result, error) {
-	result := &Result{}
+// pythonConfigs are the project files ruff and basedpyright read.
+var pythonConfigs = []string{"pyproject.toml", "ruff.toml", ".ruff.toml", "pyrightconfig.json"}
 
-	if toolExists("ruff") {
-		if err := c.runRuff(ctx, filePath, result); err != nil {
-			return result, err
-		}
+// Inputs returns the project, the tools and its ruff and basedpyright
+// config.
+func (c *pythonChecker) Inputs(filePath string) CacheInputs {
+	p := findProject(filePath, "pyproject.toml")
+	return CacheInputs{
+		Root:    p.root,
+		Tools:   []string{p.tool("ruff"), p.tool("basedpyright")},
+		Configs: p.paths(pythonConfigs...),
 	}
+}
 
-	if toolExists("basedpyright") {
-		if err := c.runBasedpyright(ctx, filePath, result); err != nil {
-			return result, err
-		}
+// Check runs the tools from the project's virtual environment if they are
+// installed there, from the directory with pyproject.toml.
+func (c *pythonChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	p := findProject(filePath, "pyproject.toml")
+	var tools []tool
+	if p.tool("ruff") != "" {
+		tools = append(tools, tool{name: "ruff", fix: true, run: p.runRuff})
 	}
-
-	return result, nil
+	if p.tool("basedpyright") != "" {
+		tools = append(tools, tool{name: "basedpyright", run: p.runBasedpyright})
+	}
+	return runTools(ctx, filePath, tools)
 }
 
-func (c *pythonChecker) runRuff(ctx context.Context, filePath string, result *Result) error {
-	// ruff check --fix
-	cmd := exec.CommandContext(ctx, "ruff", "check", "--fix", "--quiet", filePath)
-	var stderr bytes.Buffer
+func (p *project) runRuff(ctx context.Context, filePath string, result *Result) error {
+	// ruff check --fix, reporting what it couldn't fix
+	cmd := p.command(ctx, "ruff", "check", "--fix", "--output-format", "json", "--quiet", filePath)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		for _, line := range strings.Split(stderr.String(), "\n") {
-			line = strings.TrimSpace(line)
-			if line != "" {
-				result.Errors = append(result.Errors, Diagnostic{
-					File:    filePath,
-					Message: line,
-					Source:  "ruff",
-				})
-			}
+		diags, perr := parseRuff(stdout.Bytes())
+		if perr != nil || len(diags) == 0 {
+			// Not a lint failure, e.g. a syntax error in ruff's config
+			diags = parseLocationLines(stderr.String(), filePath, "ruff")
 		}
+		result.Errors = append(result.Errors, diags...)
 	}
 
 	// ruff format
-	cmd = exec.CommandContext(ctx, "ruff", "format", "--quiet", filePath)
+	cmd = p.command(ctx, "ruff", "format", "--quiet", filePath)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("ruff format: %w", err)
 	}
@@ -63,30 +70,88 @@ func (c *pythonChecker) runRuff(ctx context.Context, filePath string, result *Re
 	return nil
 }
 
-func (c *pythonChecker) runBasedpyright(ctx context.Context, filePath string, result *Result) error {
-	cmd := exec.CommandContext(ctx, "basedpyright", filePath)
+// parseRuff parses the output of ruff check --output-format json.
+func parseRuff(data []byte) ([]Diagnostic, error) {
+	var violations []struct {
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+		Filename string `json:"filename"`
+		Location struct {
+			Row    int `json:"row"`
+			Column int `json:"column"`
+		} `json:"location"`
+	}
+	if err := json.Unmarshal(data, &violations); err != nil {
+		return nil, fmt.Errorf("parse ruff output: %w", err)
+	}
+	var diags []Diagnostic
+	for _, v := range violations {
+		diags = append(diags, Diagnostic{
+			File:    v.Filename,
+			Line:    v.Location.Row,
+			Column:  v.Location.Column,
+			Message: v.Message,
+			Source:  "ruff",
+			Rule:    v.Code,
+		})
+	}
+	return diags, nil
+}
+
+func (p *project) runBasedpyright(ctx context.Context, filePath string, result *Result) error {
+	cmd := p.command(ctx, "basedpyright", "--outputjson", filePath)
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Run() // basedpyright exits non-zero on errors
 
-	for _, line := range strings.Split(stdout.String(), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Found") {
-			continue
+	if stdout.Len() == 0 {
+		return nil
+	}
+	errs, warnings, err := parseBasedpyright(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// parseBasedpyright parses the output of basedpyright --outputjson.
+// Positions in it are zero-based. Informational diagnostics are dropped.
+func parseBasedpyright(data []byte) (errs, warnings []Diagnostic, err error) {
+	type position struct {
+		Line      int `json:"line"`
+		Character int `json:"character"`
+	}
+	var out struct {
+		GeneralDiagnostics []struct {
+			File     string `json:"file"`
+			Severity string `json:"severity"`
+			Message  string `json:"message"`
+			Rule     string `json:"rule"`
+			Range    struct {
+				Start position `json:"start"`
+			} `json:"range"`
+		} `json:"generalDiagnostics"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, nil, fmt.Errorf("parse basedpyright output: %w", err)
+	}
+	for _, g := range out.GeneralDiagnostics {
+		d := Diagnostic{
+			File:    g.File,
+			Line:    g.Range.Start.Line + 1,
+			Column:  g.Range.Start.Character + 1,
+			Message: g.Message,
+			Source:  "basedpyright",
+			Rule:    g.Rule,
 		}
-		if strings.Contains(line, "error:") {
-			result.Errors = append(result.Errors, Diagnostic{
-				File:    filePath,
-				Message: line,
-				Source:  "basedpyright",
-			})
-		} else if strings.Contains(line, "warning:") {
-			result.Warnings = append(result.Warnings, Diagnostic{
-				File:    filePath,
-				Message: line,
-				Source:  "basedpyright",
-			})
+		switch g.Severity {
+		case "error":
+			errs = append(errs, d)
+		case "warning":
+			warnings = append(warnings, d)
 		}
 	}
-	return nil
+	return errs, warnings, nil
 }
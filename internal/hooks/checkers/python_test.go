@@ -0,0 +1,36 @@
+package checkers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRuff(t *testing.T) {
+	out := `[{"code":"F401","message":"` + "`os`" + ` imported but unused","filename":"/p/app.py","location":{"row":1,"column":8},"end_location":{"row":1,"column":10},"fix":null,"url":"https://docs.astral.sh/ruff/rules/unused-import"}]`
+	got, err := parseRuff([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Diagnostic{{File: "/p/app.py", Line: 1, Column: 8, Message: "`os` imported but unused", Source: "ruff", Rule: "F401"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRuff() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseBasedpyright(t *testing.T) {
+	out := `{"version":"1.0","generalDiagnostics":[
+		{"file":"/p/app.py","severity":"error","message":"\"foo\" is not defined","rule":"reportUndefinedVariable","range":{"start":{"line":4,"character":0},"end":{"line":4,"character":3}}},
+		{"file":"/p/app.py","severity":"warning","message":"Import \"x\" is not accessed","rule":"reportUnusedImport","range":{"start":{"line":0,"character":7},"end":{"line":0,"character":8}}},
+		{"file":"/p/app.py","severity":"information","message":"note","range":{"start":{"line":1,"character":0},"end":{"line":1,"character":1}}}
+	],"summary":{"errorCount":1}}`
+	errs, warnings, err := parseBasedpyright([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Line != 5 || errs[0].Column != 1 || errs[0].Rule != "reportUndefinedVariable" {
+		t.Errorf("errors = %+v, want one at 5:1", errs)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 1 || warnings[0].Column != 8 {
+		t.Errorf("warnings = %+v, want one at 1:8", warnings)
+	}
+}
@@ -0,0 +1,142 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// tool is one command a checker runs on a file.
+type tool struct {
+	name string
+	fix  bool // Changes the file, so runs before the linters, one at a time
+	gate bool // Finds syntax errors; if it reports any, later tools are skipped
+	run  func(ctx context.Context, filePath string, result *Result) error
+}
+
+type toolTimeoutKey struct{}
+
+// WithToolTimeout returns a context in which each tool a checker runs is
+// limited to d, within the context's own deadline.
+func WithToolTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, toolTimeoutKey{}, d)
+}
+
+// runTools runs a checker's tools on a file. Fixers and gates run first,
+// in order; the remaining tools only read the file and run concurrently.
+// Results are merged in the order of tools, and tool failures joined into
+// the error; one failing tool doesn't stop the others.
+func runTools(ctx context.Context, filePath string, tools []tool) (*Result, error) {
+	result := &Result{}
+	var errs []error
+	var linters []tool
+	for _, t := range tools {
+		if !t.fix && !t.gate {
+			linters = append(linters, t)
+			continue
+		}
+		r, err := runTool(ctx, t, filePath)
+		result.merge(r)
+		errs = append(errs, err)
+		if t.gate && len(r.Errors) > 0 {
+			// The file doesn't parse; the linters would only repeat that
+			return result, errors.Join(errs...)
+		}
+	}
+
+	results := make([]*Result, len(linters))
+	linterErrs := make([]error, len(linters))
+	var wg sync.WaitGroup
+	for i, t := range linters {
+		wg.Go(func() {
+			results[i], linterErrs[i] = runTool(ctx, t, filePath)
+		})
+	}
+	wg.Wait()
+	for _, r := range results {
+		result.merge(r)
+	}
+	return result, errors.Join(append(errs, linterErrs...)...)
+}
+
+// runTool runs one tool under its own timeout.
+func runTool(ctx context.Context, t tool, filePath string) (*Result, error) {
+	timeout, _ := ctx.Value(toolTimeoutKey{}).(time.Duration)
+	cancel := func() {}
+	tctx := ctx
+	if timeout > 0 {
+		tctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	result := &Result{}
+	err := t.run(tctx, filePath, result)
+	switch {
+	case ctx.Err() != nil:
+		// The whole run is out of time; whatever the tool said is incomplete
+		return result, fmt.Errorf("%s: %w", t.name, ctx.Err())
+	case tctx.Err() != nil:
+		return result, fmt.Errorf("%s: timed out after %s", t.name, timeout)
+	}
+	return result, err
+}
+
+// Run runs checkers on a file and merges their results. Formatters run
+// first, one at a time, then the linters run concurrently. Failures are
+// joined into the error, prefixed with the checker's name, and don't stop
+// the other checkers. With a cache in the context, results of cacheable
+// checkers are reused while the file and their inputs are unchanged.
+func Run(ctx context.Context, checkers []Checker, filePath string) (*Result, error) {
+	result := &Result{}
+	var errs []error
+	cache := cacheFrom(ctx)
+	check := func(c Checker) (*Result, error) {
+		if r, ok := cache.lookup(c, filePath); ok {
+			return r, nil
+		}
+		r, err := c.Check(ctx, filePath)
+		if r == nil {
+			r = &Result{}
+		}
+		if err != nil {
+			// Failed and timed out runs are incomplete, so aren't cached
+			return r, fmt.Errorf("%s: %w", c.Name(), err)
+		}
+		cache.store(c, filePath, r)
+		return r, nil
+	}
+
+	var linters []Checker
+	for _, c := range checkers {
+		if c.Priority() != Formatter {
+			linters = append(linters, c)
+			continue
+		}
+		r, err := check(c)
+		result.merge(r)
+		errs = append(errs, err)
+	}
+
+	results := make([]*Result, len(linters))
+	linterErrs := make([]error, len(linters))
+	var wg sync.WaitGroup
+	for i, c := range linters {
+		wg.Go(func() {
+			results[i], linterErrs[i] = check(c)
+		})
+	}
+	wg.Wait()
+	for _, r := range results {
+		result.merge(r)
+	}
+	return result, errors.Join(append(errs, linterErrs...)...)
+}
+
+// merge adds another result's diagnostics to r.
+func (r *Result) merge(other *Result) {
+	r.Errors = append(r.Errors, other.Errors...)
+	r.Warnings = append(r.Warnings, other.Warnings...)
+	r.Fixed = r.Fixed || other.Fixed
+}
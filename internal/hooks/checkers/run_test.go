@@ -0,0 +1,152 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeChecker is a checker that records when it runs.
+type fakeChecker struct {
+	name     string
+	exts     []string
+	priority Priority
+	result   Result
+	err      error
+	run      func()
+}
+
+func (c *fakeChecker) Name() string         { return c.name }
+func (c *fakeChecker) Extensions() []string { return c.exts }
+func (c *fakeChecker) Priority() Priority   { return c.priority }
+
+func (c *fakeChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	if c.run != nil {
+		c.run()
+	}
+	r := c.result
+	return &r, c.err
+}
+
+// diag returns a diagnostic from a tool.
+func diag(source string) Diagnostic {
+	return Diagnostic{File: "a.x", Message: "problem", Source: source}
+}
+
+func TestRunToolsOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string, fn func(*Result)) func(context.Context, string, *Result) error {
+		return func(ctx context.Context, filePath string, result *Result) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			fn(result)
+			return nil
+		}
+	}
+
+	// Both linters wait for each other, so they must run concurrently
+	var started sync.WaitGroup
+	started.Add(2)
+	linter := func(name string) func(*Result) {
+		return func(r *Result) {
+			started.Done()
+			started.Wait()
+			r.Warnings = append(r.Warnings, diag(name))
+		}
+	}
+
+	result, err := runTools(context.Background(), "a.x", []tool{
+		{name: "fmt", fix: true, run: record("fmt", func(r *Result) { r.Fixed = true })},
+		{name: "lint-a", run: record("lint-a", linter("lint-a"))},
+		{name: "fix2", fix: true, run: record("fix2", func(r *Result) { r.Errors = append(r.Errors, diag("fix2")) })},
+		{name: "lint-b", run: record("lint-b", linter("lint-b"))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(order[:2], []string{"fmt", "fix2"}) {
+		t.Errorf("order = %q, want fixers first", order)
+	}
+	if !result.Fixed || len(result.Errors) != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Warnings) != 2 || result.Warnings[0].Source != "lint-a" || result.Warnings[1].Source != "lint-b" {
+		t.Errorf("warnings = %+v, want lint-a then lint-b", result.Warnings)
+	}
+}
+
+func TestRunToolsGate(t *testing.T) {
+	linted := false
+	result, _ := runTools(context.Background(), "a.x", []tool{
+		{name: "syntax", gate: true, run: func(ctx context.Context, filePath string, r *Result) error {
+			r.Errors = append(r.Errors, diag("syntax"))
+			return nil
+		}},
+		{name: "lint", run: func(ctx context.Context, filePath string, r *Result) error {
+			linted = true
+			return nil
+		}},
+	})
+	if linted {
+		t.Error("linter ran on a file that doesn't parse")
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("errors = %+v", result.Errors)
+	}
+}
+
+func TestRunToolsTimeoutAndErrors(t *testing.T) {
+	ctx := WithToolTimeout(context.Background(), 20*time.Millisecond)
+	result, err := runTools(ctx, "a.x", []tool{
+		{name: "slow", run: func(ctx context.Context, filePath string, r *Result) error {
+			<-ctx.Done()
+			return nil
+		}},
+		{name: "broken", run: func(ctx context.Context, filePath string, r *Result) error {
+			return errors.New("broken: exit status 2")
+		}},
+		{name: "ok", run: func(ctx context.Context, filePath string, r *Result) error {
+			r.Warnings = append(r.Warnings, diag("ok"))
+			return nil
+		}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "slow: timed out after 20ms") || !strings.Contains(err.Error(), "broken: exit status 2") {
+		t.Errorf("err = %v, want the timeout and the failure", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("other tools' results should be kept: %+v", result)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+	}
+	checkers := []Checker{
+		&fakeChecker{name: "fmt", priority: Formatter, result: Result{Fixed: true}, run: record("fmt")},
+		&fakeChecker{name: "lint", priority: Linter, result: Result{Errors: []Diagnostic{diag("lint")}}, run: record("lint")},
+		&fakeChecker{name: "broken", priority: Linter, err: errors.New("exit status 1"), run: record("broken")},
+	}
+	result, err := Run(context.Background(), checkers, "a.x")
+	if err == nil || err.Error() != "broken: exit status 1" {
+		t.Errorf("err = %v, want broken's error prefixed with its name", err)
+	}
+	if order[0] != "fmt" || len(order) != 3 {
+		t.Errorf("order = %q, want fmt first", order)
+	}
+	if !result.Fixed || len(result.Errors) != 1 {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -0,0 +1,166 @@
+package checkers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type rustChecker struct{}
+
+func init() {
+	Register(&rustChecker{})
+}
+
+func (c *rustChecker) Name() string         { return "rust" }
+func (c *rustChecker) Extensions() []string { return []string{".rs"} }
+func (c *rustChecker) Priority() Priority   { return Formatter }
+
+func (c *rustChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	crate := rustCrate(findUp(filepath.Dir(filePath), "Cargo.toml"))
+	var tools []tool
+	if toolExists("rustfmt") {
+		tools = append(tools, tool{name: "rustfmt", fix: true, gate: true, run: crate.runRustfmt})
+	}
+	// clippy checks the whole crate, so it needs one
+	if crate != "" && toolExists("cargo") && toolExists("cargo-clippy") {
+		tools = append(tools, tool{name: "clippy", run: crate.runClippy})
+	}
+	return runTools(ctx, filePath, tools)
+}
+
+// rustCrate is the directory with the Cargo.toml of a file's crate, or ""
+// outside a crate.
+type rustCrate string
+
+// runRustfmt formats the file with the crate's edition.
+func (crate rustCrate) runRustfmt(ctx context.Context, filePath string, result *Result) error {
+	cmd := exec.CommandContext(ctx, "rustfmt", "--edition", crateEdition(string(crate)), filePath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		diags := parseRustc(stderr.String(), "rustfmt")
+		if len(diags) == 0 {
+			diags = parseLocationLines(stderr.String(), filePath, "rustfmt")
+		}
+		result.Errors = append(result.Errors, diags...)
+		return nil
+	}
+	result.Fixed = true
+	return nil
+}
+
+func (crate rustCrate) runClippy(ctx context.Context, filePath string, result *Result) error {
+	cmd := exec.CommandContext(ctx, "cargo", "clippy", "--quiet", "--message-format=json")
+	cmd.Dir = string(crate)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Run() // cargo exits non-zero when clippy finds errors
+
+	errs, warnings, err := parseClippy(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// crateEdition returns the edition in the crate's Cargo.toml. rustfmt run
+// on its own defaults to 2015, which rejects newer syntax.
+func crateEdition(crate string) string {
+	if crate != "" {
+		data, _ := os.ReadFile(filepath.Join(crate, "Cargo.toml"))
+		if m := cargoEdition.FindSubmatch(data); m != nil {
+			return string(m[1])
+		}
+	}
+	return "2021"
+}
+
+var cargoEdition = regexp.MustCompile(`(?m)^\s*edition\s*=\s*"(\d{4})"`)
+
+// rustcLocation matches the location line under a rustc-style error:
+// " --> src/main.rs:3:5".
+var rustcLocation = regexp.MustCompile(`^\s*--> (.+):(\d+):(\d+)$`)
+
+// parseRustc parses rustc-style errors, as printed by rustfmt: an
+// "error: message" line followed by a "--> file:line:col" line.
+func parseRustc(out, source string) []Diagnostic {
+	var diags []Diagnostic
+	message := ""
+	for _, line := range strings.Split(out, "\n") {
+		if msg, ok := strings.CutPrefix(line, "error: "); ok {
+			message = msg
+			continue
+		}
+		if m := rustcLocation.FindStringSubmatch(line); m != nil && message != "" {
+			d := Diagnostic{File: m[1], Message: message, Source: source}
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+			diags = append(diags, d)
+			message = ""
+		}
+	}
+	return diags
+}
+
+// parseClippy parses the JSON lines of cargo clippy --message-format=json.
+// Each diagnostic is reported at its primary span; summaries without one,
+// such as "aborting due to previous error", are dropped.
+func parseClippy(data []byte) (errs, warnings []Diagnostic, err error) {
+	type message struct {
+		Reason  string `json:"reason"`
+		Message struct {
+			Level   string `json:"level"`
+			Message string `json:"message"`
+			Code    *struct {
+				Code string `json:"code"`
+			} `json:"code"`
+			Spans []struct {
+				FileName    string `json:"file_name"`
+				LineStart   int    `json:"line_start"`
+				ColumnStart int    `json:"column_start"`
+				IsPrimary   bool   `json:"is_primary"`
+			} `json:"spans"`
+		} `json:"message"`
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 4*1024*1024)
+	for scanner.Scan() {
+		var m message
+		if json.Unmarshal(scanner.Bytes(), &m) != nil || m.Reason != "compiler-message" {
+			continue
+		}
+		for _, span := range m.Message.Spans {
+			if !span.IsPrimary {
+				continue
+			}
+			d := Diagnostic{
+				File:    span.FileName,
+				Line:    span.LineStart,
+				Column:  span.ColumnStart,
+				Message: m.Message.Message,
+				Source:  "clippy",
+			}
+			if m.Message.Code != nil {
+				d.Rule = m.Message.Code.Code
+			}
+			switch m.Message.Level {
+			case "error":
+				errs = append(errs, d)
+			case "warning":
+				warnings = append(warnings, d)
+			}
+			break
+		}
+	}
+	return errs, warnings, scanner.Err()
+}
@@ -0,0 +1,48 @@
+package checkers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRustc(t *testing.T) {
+	out := "error: expected one of `.`, `;`, `?`, `}`, or an operator, found `let`\n --> /p/src/main.rs:3:5\n  |\n2 |     let x = 1\n  |              - expected one of 5 possible tokens\n3 |     let y = 2;\n  |     ^^^ unexpected token\n\n"
+	want := []Diagnostic{{File: "/p/src/main.rs", Line: 3, Column: 5, Message: "expected one of `.`, `;`, `?`, `}`, or an operator, found `let`", Source: "rustfmt"}}
+	if got := parseRustc(out, "rustfmt"); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRustc() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseClippy(t *testing.T) {
+	out := `{"reason":"compiler-artifact","package_id":"foo 0.1.0"}
+{"reason":"compiler-message","message":{"level":"warning","message":"unneeded ` + "`return`" + ` statement","code":{"code":"clippy::needless_return"},"spans":[{"file_name":"src/main.rs","line_start":4,"column_start":5,"is_primary":true}]}}
+{"reason":"compiler-message","message":{"level":"error","message":"cannot find value ` + "`z`" + ` in this scope","code":{"code":"E0425"},"spans":[{"file_name":"src/lib.rs","line_start":1,"column_start":1,"is_primary":false},{"file_name":"src/lib.rs","line_start":9,"column_start":13,"is_primary":true}]}}
+{"reason":"compiler-message","message":{"level":"error","message":"aborting due to 1 previous error","code":null,"spans":[]}}
+{"reason":"build-finished","success":false}
+`
+	errs, warnings, err := parseClippy([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Diagnostic{{File: "src/lib.rs", Line: 9, Column: 13, Message: "cannot find value `z` in this scope", Source: "clippy", Rule: "E0425"}}; !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors = %+v, want %+v", errs, want)
+	}
+	if len(warnings) != 1 || warnings[0].Rule != "clippy::needless_return" || warnings[0].Line != 4 {
+		t.Errorf("warnings = %+v, want needless_return at line 4", warnings)
+	}
+}
+
+func TestCrateEdition(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte("[package]\nname = \"foo\"\nedition = \"2024\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := crateEdition(dir); got != "2024" {
+		t.Errorf("crateEdition() = %q, want 2024", got)
+	}
+	if got := crateEdition(""); got != "2021" {
+		t.Errorf("crateEdition(\"\") = %q, want 2021", got)
+	}
+}
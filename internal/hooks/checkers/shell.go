@@ -0,0 +1,96 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+type shellChecker struct{}
+
+func init() {
+	Register(&shellChecker{})
+}
+
+func (c *shellChecker) Name() string         { return "shell" }
+func (c *shellChecker) Extensions() []string { return []string{".sh", ".bash"} }
+func (c *shellChecker) Priority() Priority   { return Formatter }
+
+func (c *shellChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	var tools []tool
+	if toolExists("shfmt") {
+		tools = append(tools, tool{name: "shfmt", fix: true, gate: true, run: c.runShfmt})
+	}
+	if toolExists("shellcheck") {
+		tools = append(tools, tool{name: "shellcheck", run: c.runShellcheck})
+	}
+	return runTools(ctx, filePath, tools)
+}
+
+// runShfmt formats the file, following any .editorconfig.
+func (c *shellChecker) runShfmt(ctx context.Context, filePath string, result *Result) error {
+	cmd := exec.CommandContext(ctx, "shfmt", "-w", filePath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		result.Errors = append(result.Errors, parseLocationLines(stderr.String(), filePath, "shfmt")...)
+		return nil
+	}
+	result.Fixed = true
+	return nil
+}
+
+func (c *shellChecker) runShellcheck(ctx context.Context, filePath string, result *Result) error {
+	// Info and style notes are left out
+	cmd := exec.CommandContext(ctx, "shellcheck", "--format=json1", "--severity=warning", filePath)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Run() // shellcheck exits non-zero when it finds problems
+
+	if stdout.Len() == 0 {
+		return nil
+	}
+	errs, warnings, err := parseShellcheck(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// parseShellcheck parses the output of shellcheck --format=json1.
+func parseShellcheck(data []byte) (errs, warnings []Diagnostic, err error) {
+	var out struct {
+		Comments []struct {
+			File    string `json:"file"`
+			Line    int    `json:"line"`
+			Column  int    `json:"column"`
+			Level   string `json:"level"`
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"comments"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, nil, fmt.Errorf("parse shellcheck output: %w", err)
+	}
+	for _, c := range out.Comments {
+		d := Diagnostic{
+			File:    c.File,
+			Line:    c.Line,
+			Column:  c.Column,
+			Message: c.Message,
+			Source:  "shellcheck",
+			Rule:    "SC" + strconv.Itoa(c.Code),
+		}
+		if c.Level == "error" {
+			errs = append(errs, d)
+		} else {
+			warnings = append(warnings, d)
+		}
+	}
+	return errs, warnings, nil
+}
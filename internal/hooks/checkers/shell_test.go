@@ -0,0 +1,22 @@
+package checkers
+
+import (
+	"testing"
+)
+
+func TestParseShellcheck(t *testing.T) {
+	out := `{"comments":[
+		{"file":"/p/deploy.sh","line":3,"endLine":3,"column":6,"endColumn":10,"level":"warning","code":2086,"message":"Double quote to prevent globbing and word splitting.","fix":null},
+		{"file":"/p/deploy.sh","line":7,"endLine":7,"column":1,"endColumn":3,"level":"error","code":1009,"message":"The mentioned syntax error was in this if expression.","fix":null}
+	]}`
+	errs, warnings, err := parseShellcheck([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Rule != "SC1009" || errs[0].Line != 7 {
+		t.Errorf("errors = %+v, want SC1009 at line 7", errs)
+	}
+	if len(warnings) != 1 || warnings[0].Rule != "SC2086" || warnings[0].Column != 6 {
+		t.Errorf("warnings = %+v, want SC2086 at 3:6", warnings)
+	}
+}
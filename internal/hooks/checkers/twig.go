@@ -0,0 +1,60 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+type twigChecker struct{}
+
+func init() {
+	Register(&twigChecker{})
+}
+
+func (c *twigChecker) Name() string         { return "twig" }
+func (c *twigChecker) Extensions() []string { return []string{".twig"} }
+func (c *twigChecker) Priority() Priority   { return Formatter }
+
+var twigCsFixerConfigs = []string{".twig-cs-fixer.php", ".twig-cs-fixer.dist.php"}
+
+// Check fixes the template with twig-cs-fixer, resolved like the PHP tools,
+// and reports what it couldn't fix.
+func (c *twigChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	p := newPHPProject(filePath)
+	var tools []tool
+	if fixer := p.tool("twig-cs-fixer"); fixer != "" {
+		tools = append(tools, tool{name: "twig-cs-fixer", fix: true, run: func(ctx context.Context, filePath string, result *Result) error {
+			return p.runTwigCsFixer(ctx, fixer, filePath, result)
+		}})
+	}
+	return runTools(ctx, filePath, tools)
+}
+
+func (p *phpProject) runTwigCsFixer(ctx context.Context, tool, filePath string, result *Result) error {
+	args := []string{"lint", "--fix", "--no-interaction", "--report=checkstyle"}
+	if conf := p.config(twigCsFixerConfigs); conf != "" {
+		args = append(args, "--config="+conf)
+	}
+	cmd := p.command(ctx, tool, append(args, filePath)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run() // twig-cs-fixer exits non-zero when problems remain
+
+	if !bytes.Contains(stdout.Bytes(), []byte("<checkstyle")) {
+		if err != nil {
+			return fmt.Errorf("twig-cs-fixer: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+	result.Fixed = true
+	errs, warnings, perr := parseCheckstyle(stdout.Bytes(), "twig-cs-fixer")
+	if perr != nil {
+		return perr
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
@@ -3,8 +3,10 @@ package checkers
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -18,31 +20,45 @@ func (c *typescriptChecker) Name() string { return "typescript" }
 func (c *typescriptChecker) Extensions() []string {
 	return []string{".ts", ".tsx", ".js", ".jsx"}
 }
+func (c *typescriptChecker) Priority() Priority { return Formatter }
 
-func (c *typescriptChecker) Check(ctx context.Context, filePath string) (*Result, error) {
-	result := &Result{}
+// typescriptConfigs are the package files prettier, eslint and tsc read.
+var typescriptConfigs = []string{
+	"package.json", "tsconfig.json", ".editorconfig",
+	".prettierrc", ".prettierrc.json", ".prettierrc.yaml", ".prettierrc.yml", ".prettierrc.js", ".prettierrc.mjs", "prettier.config.js", "prettier.config.mjs",
+	"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml",
+}
 
-	if toolExists("prettier") {
-		if err := c.runPrettier(ctx, filePath, result); err != nil {
-			return result, err
-		}
+// Inputs returns the package, the tools and its config.
+func (c *typescriptChecker) Inputs(filePath string) CacheInputs {
+	p := findProject(filePath, "package.json")
+	return CacheInputs{
+		Root:    p.root,
+		Tools:   []string{p.tool("prettier"), p.tool("eslint"), p.tool("tsc")},
+		Configs: p.paths(typescriptConfigs...),
 	}
+}
 
-	if toolExists("eslint") {
-		if err := c.runEslint(ctx, filePath, result); err != nil {
-			return result, err
-		}
+// Check runs prettier, then eslint, which also fixes, then tsc. The tools
+// are taken from node_modules/.bin if the package has them, and run from the
+// directory with package.json, so tsc finds the package's tsconfig.json.
+func (c *typescriptChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	p := findProject(filePath, "package.json")
+	var tools []tool
+	if p.tool("prettier") != "" {
+		tools = append(tools, tool{name: "prettier", fix: true, run: p.runPrettier})
 	}
-
-	if toolExists("tsc") {
-		c.runTsc(ctx, filePath, result)
+	if p.tool("eslint") != "" {
+		tools = append(tools, tool{name: "eslint", fix: true, run: p.runEslint})
 	}
-
-	return result, nil
+	if p.tool("tsc") != "" {
+		tools = append(tools, tool{name: "tsc", run: p.runTsc})
+	}
+	return runTools(ctx, filePath, tools)
 }
 
-func (c *typescriptChecker) runPrettier(ctx context.Context, filePath string, result *Result) error {
-	cmd := exec.CommandContext(ctx, "prettier", "--write", filePath)
+func (p *project) runPrettier(ctx context.Context, filePath string, result *Result) error {
+	cmd := p.command(ctx, "prettier", "--write", filePath)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("prettier: %w", err)
 	}
@@ -50,42 +66,89 @@ func (c *typescriptChecker) runPrettier(ctx context.Context, filePath string, re
 	return nil
 }
 
-func (c *typescriptChecker) runEslint(ctx context.Context, filePath string, result *Result) error {
-	cmd := exec.CommandContext(ctx, "eslint", "--fix", "--quiet", filePath)
-	var stderr bytes.Buffer
+func (p *project) runEslint(ctx context.Context, filePath string, result *Result) error {
+	cmd := p.command(ctx, "eslint", "--fix", "-f", "json", filePath)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		for _, line := range strings.Split(stderr.String(), "\n") {
-			line = strings.TrimSpace(line)
-			if line != "" {
-				result.Errors = append(result.Errors, Diagnostic{
-					File:    filePath,
-					Message: line,
-					Source:  "eslint",
-				})
+	if err := cmd.Run(); err != nil && stdout.Len() == 0 {
+		// eslint itself failed, e.g. on a missing config
+		result.Errors = append(result.Errors, parseLocationLines(stderr.String(), filePath, "eslint")...)
+		return nil
+	}
+
+	errs, warnings, err := parseEslint(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// parseEslint parses the output of eslint -f json. Severity 2 is an
+// error, 1 a warning.
+func parseEslint(data []byte) (errs, warnings []Diagnostic, err error) {
+	var files []struct {
+		FilePath string `json:"filePath"`
+		Messages []struct {
+			RuleID   string `json:"ruleId"`
+			Severity int    `json:"severity"`
+			Message  string `json:"message"`
+			Line     int    `json:"line"`
+			Column   int    `json:"column"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, nil, fmt.Errorf("parse eslint output: %w", err)
+	}
+	for _, f := range files {
+		for _, m := range f.Messages {
+			d := Diagnostic{
+				File:    f.FilePath,
+				Line:    m.Line,
+				Column:  m.Column,
+				Message: m.Message,
+				Source:  "eslint",
+				Rule:    m.RuleID,
+			}
+			if m.Severity >= 2 {
+				errs = append(errs, d)
+			} else {
+				warnings = append(warnings, d)
 			}
 		}
 	}
-	return nil
+	return errs, warnings, nil
 }
 
-func (c *typescriptChecker) runTsc(ctx context.Context, filePath string, result *Result) {
-	cmd := exec.CommandContext(ctx, "tsc", "--noEmit", "--pretty", "false")
+func (p *project) runTsc(ctx context.Context, filePath string, result *Result) error {
+	cmd := p.command(ctx, "tsc", "--noEmit", "--pretty", "false")
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Run()
 
-	for _, line := range strings.Split(stdout.String(), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		if strings.Contains(line, "error TS") {
-			result.Errors = append(result.Errors, Diagnostic{
-				File:    filePath,
-				Message: line,
-				Source:  "tsc",
-			})
+	result.Errors = append(result.Errors, parseTsc(stdout.String())...)
+	return nil
+}
+
+// tscLine matches a tsc diagnostic with --pretty false:
+// "src/app.ts(10,5): error TS2322: Type 'string' is not assignable ...".
+var tscLine = regexp.MustCompile(`^(.+)\((\d+),(\d+)\): error (TS\d+): (.*)$`)
+
+// parseTsc parses tsc --pretty false output. Continuation lines of
+// multi-line messages are appended to the preceding diagnostic.
+func parseTsc(out string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(out, "\n") {
+		if m := tscLine.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			d := Diagnostic{File: m[1], Message: m[5], Source: "tsc", Rule: m[4]}
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+			diags = append(diags, d)
+		} else if strings.TrimSpace(line) != "" && len(diags) > 0 && strings.HasPrefix(line, " ") {
+			diags[len(diags)-1].Message += " " + strings.TrimSpace(line)
 		}
 	}
+	return diags
 }
@@ -0,0 +1,39 @@
+package checkers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEslint(t *testing.T) {
+	out := `[{"filePath":"/p/src/app.ts","messages":[
+		{"ruleId":"no-unused-vars","severity":2,"message":"'x' is assigned a value but never used.","line":3,"column":7},
+		{"ruleId":"prefer-const","severity":1,"message":"'y' is never reassigned.","line":4,"column":5}
+	],"errorCount":1,"warningCount":1}]`
+	errs, warnings, err := parseEslint([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantErr := []Diagnostic{{File: "/p/src/app.ts", Line: 3, Column: 7, Message: "'x' is assigned a value but never used.", Source: "eslint", Rule: "no-unused-vars"}}
+	if !reflect.DeepEqual(errs, wantErr) {
+		t.Errorf("errors = %+v, want %+v", errs, wantErr)
+	}
+	if len(warnings) != 1 || warnings[0].Rule != "prefer-const" {
+		t.Errorf("warnings = %+v, want prefer-const", warnings)
+	}
+}
+
+func TestParseTsc(t *testing.T) {
+	out := `src/app.ts(10,5): error TS2322: Type 'string' is not assignable to type 'number'.
+src/util.ts(2,1): error TS2345: Argument of type 'A' is not assignable to parameter of type 'B'.
+  Property 'id' is missing in type 'A'.
+`
+	got := parseTsc(out)
+	want := []Diagnostic{
+		{File: "src/app.ts", Line: 10, Column: 5, Message: "Type 'string' is not assignable to type 'number'.", Source: "tsc", Rule: "TS2322"},
+		{File: "src/util.ts", Line: 2, Column: 1, Message: "Argument of type 'A' is not assignable to parameter of type 'B'. Property 'id' is missing in type 'A'.", Source: "tsc", Rule: "TS2345"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTsc() = %+v, want %+v", got, want)
+	}
+}
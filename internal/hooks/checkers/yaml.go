@@ -0,0 +1,173 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type yamlChecker struct{}
+
+func init() {
+	Register(&yamlChecker{})
+}
+
+func (c *yamlChecker) Name() string         { return "yaml" }
+func (c *yamlChecker) Extensions() []string { return []string{".yml", ".yaml"} }
+
+func (c *yamlChecker) Priority() Priority { return Linter }
+
+// Check lints the file with yamllint, or checks that it parses if yamllint
+// isn't installed. yamllint is taken from the project's virtual environment
+// if it has one. GitHub Actions workflows are also checked by actionlint.
+func (c *yamlChecker) Check(ctx context.Context, filePath string) (*Result, error) {
+	p := findProject(filePath)
+	var tools []tool
+	if p.tool("yamllint") != "" {
+		tools = append(tools, tool{name: "yamllint", gate: true, run: p.runYamllint})
+	} else {
+		tools = append(tools, tool{name: "yaml", gate: true, run: c.parse})
+	}
+	if repo, ok := workflowRepo(filePath); ok && toolExists("actionlint") {
+		tools = append(tools, tool{name: "actionlint", run: func(ctx context.Context, filePath string, result *Result) error {
+			return c.runActionlint(ctx, repo, filePath, result)
+		}})
+	}
+	return runTools(ctx, filePath, tools)
+}
+
+// parse reports a syntax error, for when yamllint isn't installed.
+func (c *yamlChecker) parse(ctx context.Context, filePath string, result *Result) error {
+	if d := parseYAML(filePath); d != nil {
+		result.Errors = append(result.Errors, *d)
+	}
+	return nil
+}
+
+func (p *project) runYamllint(ctx context.Context, filePath string, result *Result) error {
+	cmd := p.command(ctx, "yamllint", "--format", "parsable", filePath)
+	cmd.Dir = filepath.Dir(filePath) // yamllint looks for .yamllint from here up
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Run() // yamllint exits non-zero when it finds errors
+
+	errs, warnings := parseYamllint(stdout.String())
+	result.Errors = append(result.Errors, errs...)
+	result.Warnings = append(result.Warnings, warnings...)
+	return nil
+}
+
+// yamllintLine matches a yamllint --format parsable line:
+// "ci.yml:3:1: [error] syntax error: could not find expected ':' (syntax)".
+var yamllintLine = regexp.MustCompile(`^(.+?):(\d+):(\d+): \[(error|warning)\] (.*?)(?: \(([\w-]+)\))?$`)
+
+// parseYamllint parses yamllint --format parsable output.
+func parseYamllint(out string) (errs, warnings []Diagnostic) {
+	for _, line := range strings.Split(out, "\n") {
+		m := yamllintLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		d := Diagnostic{File: m[1], Message: m[5], Source: "yamllint", Rule: m[6]}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		if m[4] == "error" {
+			errs = append(errs, d)
+		} else {
+			warnings = append(warnings, d)
+		}
+	}
+	return errs, warnings
+}
+
+// yamlErrorLine finds the line in a yaml.v3 error: "yaml: line 3: ...".
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// parseYAML returns a syntax error in any document of the file, or nil.
+func parseYAML(filePath string) *Diagnostic {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			d := &Diagnostic{File: filePath, Message: strings.TrimPrefix(err.Error(), "yaml: "), Source: "yaml"}
+			if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+				d.Line, _ = strconv.Atoi(m[1])
+				d.Message = m[2]
+			}
+			return d
+		}
+	}
+}
+
+// workflowRepo reports whether the file is a GitHub Actions workflow, in
+// .github/workflows, and returns the directory that holds .github.
+func workflowRepo(filePath string) (string, bool) {
+	dir := filepath.Dir(filePath)
+	if filepath.Base(dir) != "workflows" || filepath.Base(filepath.Dir(dir)) != ".github" {
+		return "", false
+	}
+	return filepath.Dir(filepath.Dir(dir)), true
+}
+
+func (c *yamlChecker) runActionlint(ctx context.Context, repo, filePath string, result *Result) error {
+	cmd := exec.CommandContext(ctx, "actionlint", "-format", "{{json .}}", filePath)
+	cmd.Dir = repo // actionlint reads .github/actionlint.yaml from here
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Run() // actionlint exits non-zero when it finds problems
+
+	if stdout.Len() == 0 {
+		return nil
+	}
+	errs, err := parseActionlint(stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, errs...)
+	return nil
+}
+
+// parseActionlint parses the output of actionlint -format '{{json .}}'.
+func parseActionlint(data []byte) ([]Diagnostic, error) {
+	var problems []struct {
+		Message  string `json:"message"`
+		Filepath string `json:"filepath"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+		Kind     string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &problems); err != nil {
+		return nil, fmt.Errorf("parse actionlint output: %w", err)
+	}
+	var diags []Diagnostic
+	for _, p := range problems {
+		diags = append(diags, Diagnostic{
+			File:    p.Filepath,
+			Line:    p.Line,
+			Column:  p.Column,
+			Message: p.Message,
+			Source:  "actionlint",
+			Rule:    p.Kind,
+		})
+	}
+	return diags, nil
+}
@@ -0,0 +1,63 @@
+package checkers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseYamllint(t *testing.T) {
+	out := `/p/docker-compose.yml:3:1: [error] syntax error: could not find expected ':' (syntax)
+/p/docker-compose.yml:9:81: [warning] line too long (92 > 80 characters) (line-length)
+`
+	errs, warnings := parseYamllint(out)
+	if want := []Diagnostic{{File: "/p/docker-compose.yml", Line: 3, Column: 1, Message: "syntax error: could not find expected ':'", Source: "yamllint", Rule: "syntax"}}; !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors = %+v, want %+v", errs, want)
+	}
+	if len(warnings) != 1 || warnings[0].Rule != "line-length" || warnings[0].Message != "line too long (92 > 80 characters)" {
+		t.Errorf("warnings = %+v", warnings)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yml")
+	invalid := filepath.Join(dir, "invalid.yml")
+	os.WriteFile(valid, []byte("a: 1\n---\nb: [1, 2]\n"), 0o644)
+	os.WriteFile(invalid, []byte("a: 1\n---\nb: [1, 2\nc: 3\n"), 0o644)
+
+	if d := parseYAML(valid); d != nil {
+		t.Errorf("parseYAML(valid) = %+v, want nil", d)
+	}
+	d := parseYAML(invalid)
+	if d == nil || d.Line == 0 || d.Source != "yaml" {
+		t.Errorf("parseYAML(invalid) = %+v, want an error with a line", d)
+	}
+}
+
+func TestWorkflowRepo(t *testing.T) {
+	for path, want := range map[string]string{
+		"/p/.github/workflows/ci.yml":          "/p",
+		"/p/.github/dependabot.yml":            "",
+		"/p/docs/workflows/example.yml":        "",
+		"/p/sub/.github/workflows/deploy.yaml": "/p/sub",
+	} {
+		repo, ok := workflowRepo(path)
+		if ok != (want != "") || repo != want {
+			t.Errorf("workflowRepo(%q) = %q, %v; want %q", path, repo, ok, want)
+		}
+	}
+}
+
+func TestParseActionlint(t *testing.T) {
+	out := `[{"message":"property \"tag\" is not defined in object type {ref: string}","filepath":".github/workflows/ci.yml","line":12,"column":24,"kind":"expression","snippet":"","end_column":31}]`
+	got, err := parseActionlint([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Diagnostic{{File: ".github/workflows/ci.yml", Line: 12, Column: 24, Message: `property "tag" is not defined in object type {ref: string}`, Source: "actionlint", Rule: "expression"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseActionlint() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,678 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/shellparse"
+)
+
+func init() {
+	Register("command-guard", commandGuardHook)
+	RegisterDryRun("command-guard", func(input *Input) (*Decision, error) {
+		var bash BashToolInput
+		if input.ToolName != "Bash" || json.Unmarshal(input.ToolInput, &bash) != nil {
+			return nil, nil
+		}
+		return newCommandGuard(input).decide(bash.Command), nil
+	})
+}
+
+// commandGuardHook denies or asks about Bash commands that are destructive
+// outside the project, run downloaded code, or may leak credentials, such
+// as "rm -rf ~" or "curl ... | sh". Denied commands are recorded as
+// observations.
+func commandGuardHook(input *Input) error {
+	if input.HookEventName != "PreToolUse" || input.ToolName != "Bash" {
+		ExitOK()
+		return nil
+	}
+
+	var bash BashToolInput
+	if err := json.Unmarshal(input.ToolInput, &bash); err != nil {
+		ExitOK()
+		return nil
+	}
+
+	d := newCommandGuard(input).decide(bash.Command)
+	if d == nil {
+		ExitOK()
+		return nil
+	}
+	if d.Action == "deny" {
+		recordBlockedCommand(input, bash.Command, d)
+	}
+	WriteOutput(d.output())
+	return nil
+}
+
+// guardRule is an entry in the command-guard catalogue.
+type guardRule struct {
+	name   string // One of config.CommandGuardRules
+	action string // Default action: deny or ask
+	check  func(g *commandGuard, c guardCall) *guardHit
+}
+
+// guardCall is one command of a script, in context.
+type guardCall struct {
+	shellparse.Command
+	dir   string               // Working directory; "" if unknown
+	input []shellparse.Command // Earlier stages of its pipeline
+}
+
+// guardHit is a rule match.
+type guardHit struct {
+	reason string
+	// unresolved is set when a path couldn't be resolved statically, so
+	// the command may be harmless. Such hits ask instead of deny.
+	unresolved bool
+}
+
+// guardRules is the built-in catalogue, in evaluation order.
+var guardRules = []guardRule{
+	{"rm-outside-project", "deny", checkRemove},
+	{"find-delete-outside-project", "deny", checkFindDelete},
+	{"pipe-to-shell", "deny", checkPipeToShell},
+	{"chmod-unsafe", "deny", checkChmod},
+	{"docker-prune", "ask", checkDockerPrune},
+	{"write-sensitive-path", "deny", checkSensitiveWrite},
+	{"exfiltrate-secrets", "deny", checkExfiltration},
+	{"disk-destroy", "deny", checkDiskDestroy},
+	{"sudo", "ask", checkSudo},
+}
+
+// commandGuard evaluates commands against the enabled rules.
+type commandGuard struct {
+	cwd     string
+	home    string
+	root    string   // Project or worktree root; "" if there is none
+	allowed []string // Directories destructive commands may touch
+	rules   []guardRule
+	actions map[string]string // Per-rule action overrides
+}
+
+// newCommandGuard returns the guard for a hook input, scoped to the git
+// root of its working directory.
+func newCommandGuard(input *Input) *commandGuard {
+	cwd := input.Cwd
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	home, _ := os.UserHomeDir()
+	return buildCommandGuard(loadConfig(input).CommandGuard, cwd, projectRoot(cwd), home)
+}
+
+// buildCommandGuard returns a guard for commands run in cwd. The project
+// root, the temp directory and the configured paths may be modified; the
+// root doesn't count if it is "/" or the home directory itself.
+func buildCommandGuard(cfg config.CommandGuard, cwd, root, home string) *commandGuard {
+	g := &commandGuard{cwd: cwd, home: home, actions: cfg.Actions}
+	if root != "" && root != "/" && root != home {
+		g.root = root
+		g.allowed = append(g.allowed, root)
+	}
+	g.allowed = append(g.allowed, "/tmp", filepath.Clean(os.TempDir()))
+	for _, p := range cfg.AllowedPaths {
+		if p, ok := g.resolve("/", p); ok {
+			g.allowed = append(g.allowed, p)
+		}
+	}
+	for _, rule := range guardRules {
+		if !slices.Contains(cfg.Disabled, rule.name) {
+			g.rules = append(g.rules, rule)
+		}
+	}
+	return g
+}
+
+// decide returns the decision for the first rule a command in the script
+// violates, preferring deny over ask, or nil if the script is allowed.
+// Scripts that don't parse need confirmation.
+func (g *commandGuard) decide(command string) *Decision {
+	cmds, err := shellparse.Parse(command)
+	if err != nil {
+		return unparsedDecision("destructive operations", err)
+	}
+	dirs := g.commandDirs(cmds)
+
+	var ask *Decision
+	for _, rule := range g.rules {
+		for i, cmd := range cmds {
+			hit := rule.check(g, guardCall{Command: cmd, dir: dirs[i], input: shellparse.Piped(cmds, i)})
+			if hit == nil {
+				continue
+			}
+			action := rule.action
+			if a, ok := g.actions[rule.name]; ok {
+				action = a
+			}
+			if hit.unresolved {
+				action = "ask"
+			}
+			if action == "deny" {
+				return &Decision{Rule: rule.name, Action: action, Reason: "Blocked: " + hit.reason}
+			}
+			if ask == nil {
+				ask = &Decision{Rule: rule.name, Action: action, Reason: hit.reason + " Confirm before running it."}
+			}
+		}
+	}
+	return ask
+}
+
+// unparsedDecision asks before running a command the shell parser can't
+// read. The Bash tool runs in the user's shell, often zsh, which accepts
+// syntax the parser rejects, so the command may well run; a guard that
+// couldn't analyse it can't let it through. checked names what the guard
+// looks for.
+func unparsedDecision(checked string, err error) *Decision {
+	return &Decision{
+		Rule:   "unparsed-command",
+		Action: "ask",
+		Reason: fmt.Sprintf("The command could not be analysed for %s (%v). Confirm before running it.", checked, err),
+	}
+}
+
+// commandDirs returns the working directory of each command, following cd
+// with a static argument. After any other cd the directory is unknown.
+func (g *commandGuard) commandDirs(cmds []shellparse.Command) []string {
+	dirs := make([]string, len(cmds))
+	dir := g.cwd
+	for i, c := range cmds {
+		dirs[i] = dir
+		if c.Base() != "cd" && c.Base() != "pushd" {
+			continue
+		}
+		target := "~"
+		if pos := positionalArgs(c.Args); len(pos) > 0 {
+			target = pos[0]
+		}
+		var ok bool
+		if dir, ok = g.resolve(dir, target); !ok || target == "-" {
+			dir = ""
+		}
+	}
+	return dirs
+}
+
+// resolve returns the absolute path of a word relative to dir, expanding
+// "~" and $HOME. It returns false if the word has other expansions or dir
+// is needed but unknown.
+func (g *commandGuard) resolve(dir, word string) (string, bool) {
+	for _, v := range []string{"$HOME", "${HOME}"} {
+		if word == v || strings.HasPrefix(word, v+"/") {
+			word = "~" + strings.TrimPrefix(word, v)
+		}
+	}
+	if (word == "~" || strings.HasPrefix(word, "~/")) && g.home != "" {
+		word = filepath.Join(g.home, word[1:])
+	}
+	if word == "" || strings.ContainsAny(word, "$`") || strings.HasPrefix(word, "~") {
+		return "", false
+	}
+	if !filepath.IsAbs(word) {
+		if dir == "" {
+			return "", false
+		}
+		word = filepath.Join(dir, word)
+	}
+	return filepath.Clean(word), true
+}
+
+// contained reports whether p is strictly inside an allowed directory.
+func (g *commandGuard) contained(p string) bool {
+	for _, dir := range g.allowed {
+		rel, err := filepath.Rel(dir, p)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTargets returns a hit for the first target outside the allowed
+// directories. verb describes what the command does to it, e.g. "`rm`
+// would delete".
+func (g *commandGuard) checkTargets(c guardCall, targets []string, verb string) *guardHit {
+	for _, t := range targets {
+		p, ok := g.resolve(c.dir, t)
+		if !ok {
+			return &guardHit{reason: fmt.Sprintf("%s %s, which can't be resolved to a path inside the project.", verb, t), unresolved: true}
+		}
+		if !g.contained(p) {
+			return &guardHit{reason: fmt.Sprintf("%s %s, which is outside the project%s.", verb, t, g.rootNote())}
+		}
+	}
+	return nil
+}
+
+// rootNote names the project root for messages.
+func (g *commandGuard) rootNote() string {
+	if g.root == "" {
+		return ""
+	}
+	return " (" + g.root + ")"
+}
+
+func checkRemove(g *commandGuard, c guardCall) *guardHit {
+	switch c.Base() {
+	case "rm", "rmdir", "unlink", "shred":
+		return g.checkTargets(c, positionalArgs(c.Args), "`"+c.Base()+"` would delete")
+	}
+	return nil
+}
+
+func checkFindDelete(g *commandGuard, c guardCall) *guardHit {
+	if c.Base() != "find" {
+		return nil
+	}
+	deletes := slices.Contains(c.Args, "-delete")
+	for i, a := range c.Args {
+		if slices.Contains([]string{"-exec", "-execdir", "-ok", "-okdir"}, a) && i+1 < len(c.Args) &&
+			slices.Contains([]string{"rm", "shred", "unlink"}, filepath.Base(c.Args[i+1])) {
+			deletes = true
+		}
+	}
+	if !deletes {
+		return nil
+	}
+
+	// The starting points come before the expression, after -H, -L, -P,
+	// -D debugopts and -Olevel
+	var roots []string
+	for i := 0; i < len(c.Args); i++ {
+		a := c.Args[i]
+		switch {
+		case a == "-H" || a == "-L" || a == "-P" || strings.HasPrefix(a, "-O"):
+			continue
+		case a == "-D":
+			i++
+			continue
+		}
+		if strings.HasPrefix(a, "-") || a == "(" || a == "!" {
+			break
+		}
+		roots = append(roots, a)
+	}
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	return g.checkTargets(c, roots, "`find` would delete files under")
+}
+
+var (
+	shells       = []string{"sh", "bash", "zsh", "dash", "ksh", "fish"}
+	interpreters = []string{"python", "python3", "perl", "ruby", "node", "php"}
+	downloaders  = []string{"curl", "wget", "fetch"}
+	// downloadSubst matches a download in a command or process substitution,
+	// as in bash -c "$(curl ...)" or bash <(curl ...).
+	downloadSubst = regexp.MustCompile(`[$<]\(\s*(curl|wget|fetch)\b`)
+)
+
+func checkPipeToShell(g *commandGuard, c guardCall) *guardHit {
+	base := c.Base()
+	if !slices.Contains(shells, base) && !slices.Contains(interpreters, base) && base != "source" && base != "." {
+		return nil
+	}
+	for _, a := range c.Args {
+		if m := downloadSubst.FindStringSubmatch(a); m != nil {
+			return &guardHit{reason: fmt.Sprintf("`%s` would run a script downloaded by `%s` without review. Download it to a file and inspect it first.", base, m[1])}
+		}
+	}
+
+	// A shell or interpreter only runs its input when it isn't given a
+	// script
+	if slices.Contains(interpreters, base) {
+		if pos := positionalArgs(c.Args); len(pos) > 0 && pos[0] != "-" {
+			return nil
+		}
+	}
+	if slices.Contains(shells, base) && (slices.Contains(c.Args, "-c") || hasShortFlag(c.Args, 'c')) {
+		return nil
+	}
+	for _, in := range c.input {
+		if slices.Contains(downloaders, in.Base()) {
+			return &guardHit{reason: fmt.Sprintf("Piping `%s` into `%s` runs downloaded code without review. Download the script to a file and inspect it first.", in.Base(), base)}
+		}
+	}
+	return nil
+}
+
+func checkChmod(g *commandGuard, c guardCall) *guardHit {
+	base := c.Base()
+	if base != "chmod" && base != "chown" && base != "chgrp" {
+		return nil
+	}
+	pos := positionalArgs(c.Args, "--reference")
+	if len(pos) < 2 {
+		return nil
+	}
+	if base == "chmod" && worldWritable(pos[0]) {
+		return &guardHit{reason: fmt.Sprintf("`chmod %s` makes files writable by every user. Grant only the permissions needed, e.g. 755 or u+x.", pos[0])}
+	}
+	if hasShortFlag(c.Args, 'R') || slices.Contains(c.Args, "--recursive") {
+		return g.checkTargets(c, pos[1:], "`"+base+" -R` would change")
+	}
+	return nil
+}
+
+// worldWritable reports whether a chmod mode grants write to others, such
+// as 777, 0666 or a+w.
+func worldWritable(mode string) bool {
+	if strings.Trim(mode, "01234567") == "" {
+		return len(mode) >= 3 && strings.ContainsAny(mode[len(mode)-1:], "2367")
+	}
+	for _, clause := range strings.Split(mode, ",") {
+		i := strings.IndexAny(clause, "+=")
+		if i < 0 {
+			continue
+		}
+		who, perms := clause[:i], clause[i+1:]
+		if strings.ContainsAny(who, "ao") && strings.Contains(perms, "w") {
+			return true
+		}
+	}
+	return false
+}
+
+func checkDockerPrune(g *commandGuard, c guardCall) *guardHit {
+	base := c.Base()
+	if base != "docker" && base != "podman" && base != "docker-compose" {
+		return nil
+	}
+	pos := positionalArgs(c.Args, "--context", "-c", "-H", "--host", "--config", "-l", "--log-level", "-f", "--file", "-p", "--project-name")
+	if base == "docker-compose" {
+		pos = append([]string{"compose"}, pos...)
+	}
+	if len(pos) < 2 {
+		return nil
+	}
+	switch {
+	case pos[1] == "prune" && slices.Contains([]string{"system", "volume", "image", "container", "network", "builder", "buildx"}, pos[0]):
+		return &guardHit{reason: fmt.Sprintf("`%s %s prune` permanently deletes Docker data that other projects may use.", base, pos[0])}
+	case pos[0] == "compose" && pos[1] == "down" && (hasShortFlag(c.Args, 'v') || slices.Contains(c.Args, "--volumes")):
+		return &guardHit{reason: "`docker compose down --volumes` permanently deletes the project's volumes, including databases."}
+	}
+	return nil
+}
+
+// sensitiveHomePaths are credential and shell startup files, relative to
+// the home directory.
+var sensitiveHomePaths = []string{
+	".ssh", ".aws", ".gnupg", ".kube", ".docker", ".config/gh", ".azure", ".config/gcloud",
+	".netrc", ".git-credentials", ".npmrc", ".pypirc", ".gitconfig",
+	".bashrc", ".bash_profile", ".profile", ".zshrc", ".zprofile", ".zshenv",
+}
+
+// systemPaths are system directories.
+var systemPaths = []string{"/etc", "/usr", "/bin", "/sbin", "/lib", "/boot", "/System", "/Library"}
+
+// sensitivePath reports whether p is, or is inside, a credential store,
+// shell startup file or system directory.
+func (g *commandGuard) sensitivePath(p string) bool {
+	under := func(dir string) bool {
+		return p == dir || strings.HasPrefix(p, dir+"/")
+	}
+	if g.home != "" {
+		for _, rel := range sensitiveHomePaths {
+			if under(filepath.Join(g.home, rel)) {
+				return true
+			}
+		}
+	}
+	return slices.ContainsFunc(systemPaths, under)
+}
+
+func checkSensitiveWrite(g *commandGuard, c guardCall) *guardHit {
+	for _, t := range writeTargets(c.Command) {
+		// Writes to unresolved paths are too common to question
+		if p, ok := g.resolve(c.dir, t); ok && g.sensitivePath(p) {
+			return &guardHit{reason: fmt.Sprintf("`%s` would write to %s. Credentials, shell startup files and system directories are off limits.", c.Base(), t)}
+		}
+	}
+	return nil
+}
+
+// writeTargets returns the files a command writes to: output redirections
+// and the destinations of common file commands.
+func writeTargets(c shellparse.Command) []string {
+	var targets []string
+	for _, r := range c.Redirects {
+		switch r.Op {
+		case ">", ">>", ">|", "&>", "&>>":
+			targets = append(targets, r.Target)
+		}
+	}
+
+	switch c.Base() {
+	case "tee", "touch", "mkdir":
+		targets = append(targets, positionalArgs(c.Args)...)
+	case "truncate":
+		targets = append(targets, positionalArgs(c.Args, "-s", "--size", "-r", "--reference")...)
+	case "cp", "mv", "install", "ln", "rsync":
+		for i, a := range c.Args {
+			if (a == "-t" || a == "--target-directory") && i+1 < len(c.Args) {
+				return append(targets, c.Args[i+1])
+			}
+			if v, ok := strings.CutPrefix(a, "--target-directory="); ok {
+				return append(targets, v)
+			}
+		}
+		opts := []string{"-S", "--suffix", "-m", "--mode", "-o", "--owner", "-g", "--group"}
+		if pos := positionalArgs(c.Args, opts...); len(pos) >= 2 {
+			targets = append(targets, pos[len(pos)-1])
+		}
+	case "dd":
+		for _, a := range c.Args {
+			if v, ok := strings.CutPrefix(a, "of="); ok {
+				targets = append(targets, v)
+			}
+		}
+	case "sed":
+		if hasShortFlag(c.Args, 'i') || slices.ContainsFunc(c.Args, func(a string) bool { return strings.HasPrefix(a, "--in-place") }) {
+			pos := positionalArgs(c.Args, "-e", "--expression", "-f", "--file")
+			if !slices.Contains(c.Args, "-e") && !slices.Contains(c.Args, "-f") && len(pos) > 0 {
+				pos = pos[1:] // The first is the script
+			}
+			targets = append(targets, pos...)
+		}
+	}
+	return targets
+}
+
+// networkTools send data off the machine.
+var networkTools = []string{"curl", "wget", "nc", "ncat", "netcat", "socat", "scp", "sftp", "rsync", "ftp", "ssh", "telnet"}
+
+func checkExfiltration(g *commandGuard, c guardCall) *guardHit {
+	if !slices.Contains(networkTools, c.Base()) {
+		return nil
+	}
+	for _, f := range uploadedFiles(c.Command) {
+		if g.secretFile(c.dir, f) {
+			return &guardHit{reason: fmt.Sprintf("`%s` would send %s off this machine, and it looks like a credential or secret.", c.Base(), f)}
+		}
+	}
+	// Secrets read earlier in the pipeline, as in cat ~/.ssh/id_rsa | nc ...
+	for _, in := range c.input {
+		for _, f := range readFiles(in) {
+			if g.secretFile(c.dir, f) {
+				return &guardHit{reason: fmt.Sprintf("`%s` would send the output of `%s %s` off this machine, and it looks like a credential or secret.", c.Base(), in.Base(), f)}
+			}
+		}
+	}
+	return nil
+}
+
+// uploadedFiles returns the local files a network command sends.
+func uploadedFiles(c shellparse.Command) []string {
+	var files []string
+	for _, r := range c.Redirects {
+		if r.Op == "<" {
+			files = append(files, r.Target)
+		}
+	}
+
+	switch c.Base() {
+	case "curl":
+		for i := 0; i < len(c.Args); i++ {
+			opt, value := c.Args[i], ""
+			switch {
+			case slices.Contains([]string{"-d", "--data", "--data-binary", "--data-ascii", "--data-urlencode", "--json", "-F", "--form", "-T", "--upload-file"}, opt) && i+1 < len(c.Args):
+				i++
+				value = c.Args[i]
+			case len(opt) > 2 && (strings.HasPrefix(opt, "-d") || strings.HasPrefix(opt, "-F") || strings.HasPrefix(opt, "-T")):
+				opt, value = opt[:2], opt[2:]
+			default:
+				continue
+			}
+			switch opt {
+			case "-T", "--upload-file":
+				files = append(files, value)
+			case "-F", "--form":
+				// name=@file or name=<file, optionally followed by ;type=...
+				if _, v, ok := strings.Cut(value, "="); ok && (strings.HasPrefix(v, "@") || strings.HasPrefix(v, "<")) {
+					f, _, _ := strings.Cut(v[1:], ";")
+					files = append(files, f)
+				}
+			default:
+				// @file, or name@file for --data-urlencode
+				if _, f, ok := strings.Cut(value, "@"); ok && f != "-" {
+					files = append(files, f)
+				}
+			}
+		}
+	case "wget":
+		for i, a := range c.Args {
+			for _, opt := range []string{"--post-file", "--body-file"} {
+				if v, ok := strings.CutPrefix(a, opt+"="); ok {
+					files = append(files, v)
+				} else if a == opt && i+1 < len(c.Args) {
+					files = append(files, c.Args[i+1])
+				}
+			}
+		}
+	default:
+		files = append(files, positionalArgs(c.Args, "-i", "-F", "-P", "-p", "-o", "-e", "-l", "-J")...)
+	}
+	return files
+}
+
+// readFiles returns the files a command in a pipeline may read.
+func readFiles(c shellparse.Command) []string {
+	files := positionalArgs(c.Args)
+	for _, r := range c.Redirects {
+		if r.Op == "<" {
+			files = append(files, r.Target)
+		}
+	}
+	return files
+}
+
+// secretFile reports whether a file looks like it holds credentials: files
+// in credential stores, private keys and .env files.
+func (g *commandGuard) secretFile(dir, word string) bool {
+	p, ok := g.resolve(dir, word)
+	if !ok {
+		p = word
+	}
+	if g.home != "" {
+		for _, rel := range []string{".ssh", ".aws", ".gnupg", ".kube", ".docker/config.json", ".config/gh", ".azure", ".config/gcloud", ".netrc", ".git-credentials", ".npmrc", ".pypirc"} {
+			store := filepath.Join(g.home, rel)
+			if p == store || strings.HasPrefix(p, store+"/") {
+				return !strings.HasSuffix(p, ".pub") && !strings.HasSuffix(p, "/known_hosts")
+			}
+		}
+	}
+	if p == "/etc/shadow" || p == "/etc/sudoers" {
+		return true
+	}
+
+	name := filepath.Base(p)
+	switch {
+	case name == ".env" || strings.HasPrefix(name, ".env."):
+		return !slices.Contains([]string{".env.example", ".env.dist", ".env.sample", ".env.template"}, name)
+	case strings.HasPrefix(name, "id_rsa") || strings.HasPrefix(name, "id_dsa") || strings.HasPrefix(name, "id_ecdsa") || strings.HasPrefix(name, "id_ed25519"):
+		return !strings.HasSuffix(name, ".pub")
+	}
+	return slices.Contains([]string{".pem", ".key", ".p12", ".pfx", ".keystore", ".jks"}, filepath.Ext(name))
+}
+
+// blockDevice matches disk device files.
+var blockDevice = regexp.MustCompile(`^/dev/(sd|hd|vd|xvd|nvme|mmcblk|disk|rdisk|md|mapper/)`)
+
+func checkDiskDestroy(g *commandGuard, c guardCall) *guardHit {
+	base := c.Base()
+	if strings.HasPrefix(base, "mkfs") || base == "wipefs" {
+		return &guardHit{reason: fmt.Sprintf("`%s` would erase a file system.", base)}
+	}
+	targets := writeTargets(c.Command)
+	if base == "shred" {
+		targets = append(targets, positionalArgs(c.Args, "-n", "--iterations", "-s", "--size")...)
+	}
+	for _, t := range targets {
+		if blockDevice.MatchString(t) {
+			return &guardHit{reason: fmt.Sprintf("`%s` would overwrite the disk device %s.", base, t)}
+		}
+	}
+	return nil
+}
+
+func checkSudo(g *commandGuard, c guardCall) *guardHit {
+	if base := c.Base(); base == "sudo" || base == "doas" {
+		return &guardHit{reason: fmt.Sprintf("`%s` runs the command as root, with access beyond the project.", base)}
+	}
+	return nil
+}
+
+// hasShortFlag reports whether a short option is given, alone or combined
+// as in -rf.
+func hasShortFlag(args []string, flag rune) bool {
+	for _, a := range args {
+		if a == "--" {
+			return false
+		}
+		if len(a) > 1 && a[0] == '-' && a[1] != '-' && strings.ContainsRune(a[1:], flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectRoot returns the top-level directory of the git work tree at dir,
+// or dir itself outside a repository.
+func projectRoot(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return dir
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// recordBlockedCommand saves a denied command as an observation, so blocked
+// attempts show up in memory. Best effort: it is skipped without a console.
+func recordBlockedCommand(input *Input, command string, d *Decision) {
+	client := consoleClientFromEnv()
+	sessionID := os.Getenv(config.EnvPrefix + "_SESSION_ID")
+	if client == nil || sessionID == "" {
+		return
+	}
+	postObservation(client, sessionID, projectName(input.Cwd), &capturedObservation{
+		Type:  "decision",
+		Title: "Blocked command: " + truncate(firstLine(command), 80),
+		Text:  d.Reason + "\n\nCommand:\n" + command,
+		Metadata: map[string]any{
+			"source": "command-guard",
+			"rule":   d.Rule,
+			"action": d.Action,
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+)
+
+// loadConfig resolves the configuration for the hook's project directory.
+// A broken config file must not break every tool call, so on error it warns
+// on stderr and falls back to the defaults.
+func loadConfig(input *Input) *config.Config {
+	dir := input.Cwd
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	cfg, err := config.LoadFrom(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "icc: %v (using defaults)\n", err)
+		return config.Defaults()
+	}
+	for _, w := range cfg.Warnings {
+		fmt.Fprintf(os.Stderr, "icc: %s\n", w)
+	}
+	return cfg
+}
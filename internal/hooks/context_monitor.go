@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strconv"
 
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/notify"
 	"github.com/itk-dev/itkdev-claude-code/internal/session"
 )
 
@@ -33,8 +35,9 @@ func contextMonitorHook(input *Input) error {
 		return nil
 	}
 
+	cfg := loadConfig(input)
 	shown := loadShownThresholds(sessionDir)
-	threshold := currentThreshold(pct)
+	threshold := currentThreshold(pct, cfg.Context.Thresholds)
 	if threshold == 0 || shown[threshold] {
 		ExitOK()
 		return nil
@@ -44,11 +47,17 @@ func contextMonitorHook(input *Input) error {
 	shown[threshold] = true
 	saveShownThresholds(sessionDir, shown)
 
-	msg := thresholdMessage(threshold, pct, sessionDir)
+	msg := thresholdMessage(threshold, cfg.Context.Handoff, pct, sessionDir)
 
-	if threshold >= 90 {
-		// At 90%+ the monitor returns a blocking message via stderr (exit 2)
-		// which tells Claude to stop and hand off
+	if threshold >= cfg.Context.Handoff {
+		sendNotification(input, cfg, notify.Notification{
+			Event:   notify.EventHandoff,
+			Title:   config.DisplayName,
+			Message: fmt.Sprintf("Context at %.0f%%, handing off to a new session", pct),
+		})
+
+		// From the handoff threshold on the monitor returns a blocking
+		// message via stderr (exit 2) which tells Claude to stop and hand off
 		BlockWithError(msg)
 		return nil // unreachable after os.Exit(2)
 	}
@@ -94,28 +103,32 @@ func parseContextFromTranscript(transcriptPath string) float64 {
 	return lastPct
 }
 
-func currentThreshold(pct float64) int {
-	thresholds := []int{95, 90, 80, 60, 40}
-	for _, t := range thresholds {
-		if pct >= float64(t) {
-			return t
+// currentThreshold returns the highest of the ascending thresholds that pct
+// has reached, or 0 if none.
+func currentThreshold(pct float64, thresholds []int) int {
+	for i := len(thresholds) - 1; i >= 0; i-- {
+		if pct >= float64(thresholds[i]) {
+			return thresholds[i]
 		}
 	}
 	return 0
 }
 
-func thresholdMessage(threshold int, pct float64, sessionDir string) string {
+// thresholdMessage escalates relative to the handoff threshold: above it is
+// critical, at it a handoff is mandatory, and up to 30 points below it the
+// message asks to prepare for or monitor the handoff.
+func thresholdMessage(threshold, handoff int, pct float64, sessionDir string) string {
 	contFile := filepath.Join(sessionDir, "continuation.md")
 
 	switch {
-	case threshold >= 95:
+	case threshold > handoff:
 		return fmt.Sprintf(
 			"CRITICAL: Context at %.0f%%. IMMEDIATE handoff required.\n"+
 				"Step 1: Write continuation summary to %s\n"+
 				"Step 2: Execute: icc send-clear\n"+
 				"Do both in THIS turn. Do NOT start new work.",
 			pct, contFile)
-	case threshold >= 90:
+	case threshold == handoff:
 		return fmt.Sprintf(
 			"Context at %.0f%%. Mandatory handoff.\n"+
 				"Step 1: Finish current tool call only\n"+
@@ -123,12 +136,12 @@ func thresholdMessage(threshold int, pct float64, sessionDir string) string {
 				"Step 3: Execute: icc send-clear\n"+
 				"Do NOT start new fix cycles.",
 			pct, contFile)
-	case threshold >= 80:
+	case threshold >= handoff-10:
 		return fmt.Sprintf(
 			"Context at %.0f%%. Prepare for handoff. "+
 				"Wrap up current task, avoid starting new complex work.",
 			pct)
-	case threshold >= 60:
+	case threshold >= handoff-30:
 		return fmt.Sprintf("Context at %.0f%%. Monitor your progress.", pct)
 	default:
 		return fmt.Sprintf("Context at %.0f%%.", pct)
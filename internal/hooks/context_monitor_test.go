@@ -3,6 +3,7 @@ package hooks
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/itk-dev/itkdev-claude-code/internal/config"
@@ -28,7 +29,7 @@ func TestCurrentThreshold(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run("", func(t *testing.T) {
-			got := currentThreshold(tt.pct)
+			got := currentThreshold(tt.pct, config.Defaults().Context.Thresholds)
 			if got != tt.want {
 				t.Errorf("currentThreshold(%.0f) = %d, want %d", tt.pct, got, tt.want)
 			}
@@ -39,17 +40,34 @@ func TestCurrentThreshold(t *testing.T) {
 func TestThresholdMessage(t *testing.T) {
 	sessionDir := t.TempDir()
 
-	msg := thresholdMessage(95, 96.5, sessionDir)
+	msg := thresholdMessage(95, 90, 96.5, sessionDir)
 	if msg == "" {
 		t.Error("expected non-empty message for threshold 95")
 	}
 
-	msg = thresholdMessage(80, 82.0, sessionDir)
+	msg = thresholdMessage(80, 90, 82.0, sessionDir)
 	if msg == "" {
 		t.Error("expected non-empty message for threshold 80")
 	}
 }
 
+func TestThresholdMessageCustomHandoff(t *testing.T) {
+	sessionDir := t.TempDir()
+
+	if got := currentThreshold(72, []int{50, 70, 85}); got != 70 {
+		t.Errorf("currentThreshold(72) = %d, want 70", got)
+	}
+	if msg := thresholdMessage(70, 70, 72, sessionDir); !strings.Contains(msg, "Mandatory handoff") {
+		t.Errorf("threshold at handoff should demand a handoff, got %q", msg)
+	}
+	if msg := thresholdMessage(85, 70, 86, sessionDir); !strings.Contains(msg, "CRITICAL") {
+		t.Errorf("threshold above handoff should be critical, got %q", msg)
+	}
+	if msg := thresholdMessage(60, 70, 61, sessionDir); !strings.Contains(msg, "Prepare for handoff") {
+		t.Errorf("threshold 10 below handoff should prepare, got %q", msg)
+	}
+}
+
 func TestContextMonitorRegistered(t *testing.T) {
 	_, ok := registry["context-monitor"]
 	if !ok {
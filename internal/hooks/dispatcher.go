@@ -1,6 +1,11 @@
 package hooks
 
-import "fmt"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
 
 // Hook is a function that handles a specific hook event.
 // It receives the parsed input and is responsible for writing output
@@ -39,3 +44,71 @@ func RegisteredHooks() []string {
 	}
 	return names
 }
+
+// Decision is what a PreToolUse hook decided about a tool call.
+type Decision struct {
+	Rule         string         `json:"rule,omitempty"`
+	Action       string         `json:"action"` // deny, allow, ask or rewrite
+	Reason       string         `json:"reason,omitempty"`
+	UpdatedInput map[string]any `json:"updated_input,omitempty"` // Tool input after a rewrite
+}
+
+// output converts the decision to PreToolUse hook output. A rewrite leaves
+// the permission decision to the normal flow.
+func (d *Decision) output() *Output {
+	spec := &HookSpecificOuput{
+		HookEventName:            "PreToolUse",
+		PermissionDecisionReason: d.Reason,
+	}
+	spec.UpdatedInput = d.UpdatedInput
+	if d.Action != "rewrite" {
+		spec.PermissionDecision = d.Action
+	}
+	return &Output{HookSpecific: spec}
+}
+
+// DryRunFunc evaluates a hook against an input without side effects.
+// It returns nil if the hook would let the call proceed unchanged.
+type DryRunFunc func(input *Input) (*Decision, error)
+
+// dryRuns maps hook names to their dry-run evaluators.
+var dryRuns = map[string]DryRunFunc{}
+
+// RegisterDryRun adds a dry-run evaluator for a hook.
+func RegisterDryRun(name string, f DryRunFunc) {
+	dryRuns[name] = f
+}
+
+// DryRun evaluates the named hook against an input without running it.
+func DryRun(name string, input *Input) (*Decision, error) {
+	f, ok := dryRuns[name]
+	if !ok {
+		return nil, fmt.Errorf("hook %s does not support dry runs (supported: %v)", name, DryRunHooks())
+	}
+	return f(input)
+}
+
+// DryRunHooks returns the sorted names of hooks that support dry runs.
+func DryRunHooks() []string {
+	names := make([]string, 0, len(dryRuns))
+	for name := range dryRuns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseInputs decodes a stream of hook inputs: JSON objects, one after
+// another, as in a JSONL file.
+func ParseInputs(data []byte) ([]*Input, error) {
+	var inputs []*Input
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var in Input
+		if err := dec.Decode(&in); err != nil {
+			return nil, fmt.Errorf("parse hook input %d: %w", len(inputs)+1, err)
+		}
+		inputs = append(inputs, &in)
+	}
+	return inputs, nil
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"time"
 
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
 	"github.com/itk-dev/itkdev-claude-code/internal/hooks/checkers"
 )
 
@@ -22,59 +24,151 @@ func fileCheckerHook(input *Input) error {
 		return nil
 	}
 
-	ext := filepath.Ext(filePath)
-	checker := checkers.ForExtension(ext)
-	if checker == nil {
+	cfg := loadConfig(input).Checker
+	registerCustomCheckers(cfg.Custom, input.Cwd)
+	matched := checkers.ForFile(filePath)
+	if len(matched) == 0 {
 		ExitOK()
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx := checkers.WithToolTimeout(context.Background(), cfg.ToolTimeout)
+	if cfg.Cache {
+		ctx = checkers.WithCache(ctx, checkers.NewCache(checkers.CacheDir(resolveSessionDir())))
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
 	defer cancel()
 
-	result, err := checker.Check(ctx, filePath)
-	if err != nil {
-		// Non-fatal: report as warning, don't block
-		WriteOutput(&Output{
-			SystemMessage: fmt.Sprintf("[%s] checker error: %v", checker.Name(), err),
-		})
-		return nil
-	}
+	// Tool failures are reported without blocking, next to what the
+	// other tools found
+	result, err := checkers.Run(ctx, matched, filePath)
 
-	if len(result.Errors) == 0 && len(result.Warnings) == 0 {
-		if result.Fixed {
-			WriteOutput(&Output{
-				SuppressOutput: true,
-			})
-		} else {
-			ExitOK()
-		}
-		return nil
+	// Problems outside the changed lines predate this edit; they are
+	// counted but don't block
+	preexisting := 0
+	if cfg.ChangedOnly {
+		result, preexisting = splitByChange(result, filePath, input.Cwd, changedLines(input, filePath))
 	}
 
-	// Build feedback message for Claude
-	var msg strings.Builder
-	for _, d := range result.Errors {
-		fmt.Fprintf(&msg, "[%s] ERROR: %s\n", d.Source, d.Message)
+	var msg string
+	if len(result.Errors) > 0 || len(result.Warnings) > 0 {
+		msg = formatDiagnostics(result, input.Cwd)
+	}
+	if preexisting > 0 {
+		msg += preexistingSummary(preexisting) + "\n"
 	}
-	for _, d := range result.Warnings {
-		fmt.Fprintf(&msg, "[%s] WARNING: %s\n", d.Source, d.Message)
+	if err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			msg += "checker error: " + line + "\n"
+		}
 	}
 
-	// PostToolUse: use decision=block to send errors back to Claude
-	if len(result.Errors) > 0 {
+	switch {
+	case len(result.Errors) > 0:
+		// PostToolUse: use decision=block to send errors back to Claude
 		WriteOutput(&Output{
 			Decision: "block",
-			Reason:   msg.String(),
+			Reason:   msg,
 		})
-	} else {
+	case msg != "":
 		WriteOutput(&Output{
-			SystemMessage: msg.String(),
+			SystemMessage: msg,
 		})
+	case result.Fixed:
+		WriteOutput(&Output{
+			SuppressOutput: true,
+		})
+	default:
+		ExitOK()
 	}
 	return nil
 }
 
+// registerCustomCheckers registers the checkers from checker.custom, for
+// the project cwd is in. The config is validated when it's loaded, so a
+// checker that still can't be built is only reported on stderr.
+func registerCustomCheckers(custom []config.CustomChecker, cwd string) {
+	if len(custom) == 0 {
+		return
+	}
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	root := projectRoot(cwd)
+	for _, cc := range custom {
+		c, err := checkers.NewCommandChecker(cc, root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "icc: %v\n", err)
+			continue
+		}
+		checkers.Register(c)
+	}
+}
+
+// preexistingSummary reports problems outside the changed lines.
+func preexistingSummary(n int) string {
+	if n == 1 {
+		return "1 pre-existing problem outside the changed lines was not shown."
+	}
+	return fmt.Sprintf("%d pre-existing problems outside the changed lines were not shown.", n)
+}
+
+// maxDiagnostics caps the diagnostics listed per severity, to keep the
+// feedback compact.
+const maxDiagnostics = 20
+
+// formatDiagnostics renders a result as one line per diagnostic, e.g.
+// "[ruff] ERROR app.py:3:1: `os` imported but unused (F401)". Duplicates
+// are listed once, and paths are shown relative to cwd.
+func formatDiagnostics(result *checkers.Result, cwd string) string {
+	var msg strings.Builder
+	write := func(severity string, diags []checkers.Diagnostic) {
+		seen := map[checkers.Diagnostic]bool{}
+		n := 0
+		for _, d := range diags {
+			if seen[d] {
+				continue
+			}
+			seen[d] = true
+			if n++; n > maxDiagnostics {
+				continue
+			}
+			fmt.Fprintf(&msg, "[%s] %s %s\n", d.Source, severity, formatDiagnostic(d, cwd))
+		}
+		if n > maxDiagnostics {
+			fmt.Fprintf(&msg, "... and %d more %s\n", n-maxDiagnostics, strings.ToLower(severity)+"s")
+		}
+	}
+	write("ERROR", result.Errors)
+	write("WARNING", result.Warnings)
+	return msg.String()
+}
+
+// formatDiagnostic renders "file:line:col: message (rule)", leaving out
+// what the diagnostic doesn't have.
+func formatDiagnostic(d checkers.Diagnostic, cwd string) string {
+	loc := d.File
+	if cwd != "" && filepath.IsAbs(loc) {
+		if rel, err := filepath.Rel(cwd, loc); err == nil && !strings.HasPrefix(rel, "..") {
+			loc = rel
+		}
+	}
+	if d.Line > 0 {
+		loc += ":" + strconv.Itoa(d.Line)
+		if d.Column > 0 {
+			loc += ":" + strconv.Itoa(d.Column)
+		}
+	}
+	s := d.Message
+	if loc != "" {
+		s = loc + ": " + s
+	}
+	if d.Rule != "" {
+		s += " (" + d.Rule + ")"
+	}
+	return s
+}
+
 // extractFilePath gets the file path from the tool input, handling both
 // Write and Edit tool types.
 func extractFilePath(input *Input) string {
@@ -2,7 +2,10 @@ package hooks
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/checkers"
 )
 
 func TestExtractFilePath_Write(t *testing.T) {
@@ -52,3 +55,24 @@ func TestFileCheckerRegistered(t *testing.T) {
 		t.Error("file-checker not registered")
 	}
 }
+
+func TestFormatDiagnostics(t *testing.T) {
+	d := checkers.Diagnostic{File: "/p/app.py", Line: 3, Column: 1, Message: "`os` imported but unused", Source: "ruff", Rule: "F401"}
+	result := &checkers.Result{
+		Errors:   []checkers.Diagnostic{d, d},
+		Warnings: []checkers.Diagnostic{{File: "/p/app.py", Message: "whole-file note", Source: "tool"}},
+	}
+	got := formatDiagnostics(result, "/p")
+	want := "[ruff] ERROR app.py:3:1: `os` imported but unused (F401)\n[tool] WARNING app.py: whole-file note\n"
+	if got != want {
+		t.Errorf("formatDiagnostics() =\n%s\nwant\n%s", got, want)
+	}
+
+	var many checkers.Result
+	for i := range maxDiagnostics + 3 {
+		many.Warnings = append(many.Warnings, checkers.Diagnostic{File: "a.go", Line: i + 1, Message: "x", Source: "lint"})
+	}
+	if got := formatDiagnostics(&many, ""); !strings.HasSuffix(got, "... and 3 more warnings\n") {
+		t.Errorf("formatDiagnostics() should truncate, got %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/notify"
+)
+
+func init() {
+	Register("notify", notifyHook)
+}
+
+// notifyTimeout bounds how long sending to all sinks may take.
+const notifyTimeout = 5 * time.Second
+
+// notifyHook sends a notification when Claude stops or Claude Code needs
+// input. Which events notify, and where, is set in the notify config.
+// Never blocks; delivery errors are reported on stderr.
+func notifyHook(input *Input) error {
+	if n := notificationFor(input); n != nil {
+		sendNotification(input, loadConfig(input), *n)
+	}
+	ExitOK()
+	return nil
+}
+
+// notificationFor maps a hook event to a notification, or nil if the event
+// doesn't notify.
+func notificationFor(input *Input) *notify.Notification {
+	switch input.HookEventName {
+	case "Stop":
+		// A stop hook already made Claude continue; the session isn't idle
+		if input.StopHookActive {
+			return nil
+		}
+		return &notify.Notification{
+			Event:   notify.EventStop,
+			Title:   config.DisplayName,
+			Message: "Claude has finished and is waiting for you",
+		}
+	case "Notification":
+		title := input.Title
+		if title == "" {
+			title = config.DisplayName
+		}
+		msg := input.Message
+		if msg == "" {
+			msg = "Claude needs your attention"
+		}
+		return &notify.Notification{
+			Event:   notify.EventNotification,
+			Title:   title,
+			Message: msg,
+		}
+	default:
+		return nil
+	}
+}
+
+// sendNotification fills in the session and project and sends n to the
+// configured sinks.
+func sendNotification(input *Input, cfg *config.Config, n notify.Notification) {
+	n.SessionID = input.SessionID
+	n.Project = projectName(input.Cwd)
+	if n.Project != "" {
+		n.Title += " — " + n.Project
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := notify.Send(ctx, cfg.Notify, n); err != nil {
+		fmt.Fprintf(os.Stderr, "icc notify: %v\n", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Package notify delivers notifications from hooks to pluggable sinks:
+// desktop notifications, the terminal, webhooks and files. Each sink type
+// registers a factory; the notify config selects and configures sinks.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+)
+
+// Event names, as used in the notify config.
+const (
+	EventStop         = "stop"         // Claude finished responding
+	EventNotification = "notification" // Claude Code needs input or permission
+	EventHandoff      = "handoff"      // Context is full and the session must hand off
+)
+
+// Notification is a single message sent to the sinks.
+type Notification struct {
+	Event     string    `json:"event"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	SessionID string    `json:"session_id,omitempty"`
+	Project   string    `json:"project,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Sink delivers notifications to one backend.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// Factory creates a sink from its config.
+type Factory func(cfg config.NotifySink) (Sink, error)
+
+// registry maps sink types to their factories.
+var registry = map[string]Factory{}
+
+// Register adds a sink factory for a sink type.
+func Register(sinkType string, f Factory) {
+	registry[sinkType] = f
+}
+
+// New creates the sink for a sink config.
+func New(cfg config.NotifySink) (Sink, error) {
+	f, ok := registry[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown notify sink %q", cfg.Type)
+	}
+	return f(cfg)
+}
+
+// Send delivers n to every configured sink that accepts its event. Sinks are
+// tried independently; the errors of all failing sinks are joined.
+func Send(ctx context.Context, cfg config.NotifyConfig, n Notification) error {
+	if !slices.Contains(cfg.Events, n.Event) {
+		return nil
+	}
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+
+	var errs []error
+	for _, sc := range cfg.Sinks {
+		if len(sc.Events) > 0 && !slices.Contains(sc.Events, n.Event) {
+			continue
+		}
+		sink, err := New(sc)
+		if err == nil {
+			err = sink.Send(ctx, n)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sc.Type, err))
+		}
+	}
+	return errors.Join(errs...)
+}
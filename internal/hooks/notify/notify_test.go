@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+)
+
+// readNotifications reads the notifications written by a file sink.
+func readNotifications(t *testing.T, path string) []Notification {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []Notification
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var n Notification
+		if err := json.Unmarshal([]byte(line), &n); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func TestSendFiltersEvents(t *testing.T) {
+	dir := t.TempDir()
+	all := filepath.Join(dir, "all.jsonl")
+	handoffOnly := filepath.Join(dir, "handoff.jsonl")
+	cfg := config.NotifyConfig{
+		Events: []string{EventNotification, EventHandoff},
+		Sinks: []config.NotifySink{
+			{Type: "file", Path: all},
+			{Type: "file", Path: handoffOnly, Events: []string{EventHandoff}},
+		},
+	}
+
+	for _, event := range []string{EventStop, EventNotification, EventHandoff} {
+		if err := Send(context.Background(), cfg, Notification{Event: event, Title: "t", Message: event}); err != nil {
+			t.Fatalf("Send(%s): %v", event, err)
+		}
+	}
+
+	got := readNotifications(t, all)
+	if len(got) != 2 || got[0].Event != EventNotification || got[1].Event != EventHandoff {
+		t.Errorf("all sink got %+v, want notification and handoff", got)
+	}
+	if got[0].Time.IsZero() {
+		t.Error("Send should set the time")
+	}
+	if got := readNotifications(t, handoffOnly); len(got) != 1 || got[0].Event != EventHandoff {
+		t.Errorf("handoff sink got %+v, want only handoff", got)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Notification
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.Event == EventHandoff {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := config.NotifyConfig{
+		Events: []string{EventStop, EventHandoff},
+		Sinks:  []config.NotifySink{{Type: "webhook", URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}}},
+	}
+	if err := Send(context.Background(), cfg, Notification{Event: EventStop, Message: "done", Project: "icc"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Message != "done" || got.Project != "icc" || auth != "Bearer x" {
+		t.Errorf("webhook got %+v with auth %q", got, auth)
+	}
+
+	if err := Send(context.Background(), cfg, Notification{Event: EventHandoff}); err == nil {
+		t.Error("expected error for a failing webhook")
+	}
+}
+
+func TestWebhookSinkRedactsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	// A rejected request, and one that can't connect
+	for _, base := range []string{srv.URL, closed.URL} {
+		sink, err := New(config.NotifySink{Type: "webhook", URL: base + "/services/T000/XXXXSECRET?token=abc"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = sink.Send(context.Background(), Notification{Event: EventStop})
+		if err == nil {
+			t.Fatalf("%s: expected error", base)
+		}
+		if strings.Contains(err.Error(), "XXXXSECRET") || strings.Contains(err.Error(), "abc") {
+			t.Errorf("error %q leaks the URL token", err)
+		}
+	}
+}
+
+func TestTerminalSink(t *testing.T) {
+	tty := filepath.Join(t.TempDir(), "tty")
+	if err := os.WriteFile(tty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sink, err := New(config.NotifySink{Type: "terminal", Path: tty})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(context.Background(), Notification{Title: "icc", Message: "needs\ninput"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	data, _ := os.ReadFile(tty)
+	if want := "\a\x1b]9;icc: needs input\x07"; string(data) != want {
+		t.Errorf("terminal got %q, want %q", data, want)
+	}
+}
+
+func TestSendReportsSinkErrors(t *testing.T) {
+	dir := t.TempDir()
+	ok := filepath.Join(dir, "ok.jsonl")
+	cfg := config.NotifyConfig{
+		Events: []string{EventStop},
+		Sinks: []config.NotifySink{
+			{Type: "carrier-pigeon"},
+			{Type: "file", Path: ok},
+		},
+	}
+
+	err := Send(context.Background(), cfg, Notification{Event: EventStop})
+	if err == nil || !strings.Contains(err.Error(), "carrier-pigeon") {
+		t.Errorf("Send error = %v, want unknown sink error", err)
+	}
+	if got := readNotifications(t, ok); len(got) != 1 {
+		t.Errorf("a failing sink should not stop the others, got %d notifications", len(got))
+	}
+}
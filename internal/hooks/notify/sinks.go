@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+)
+
+func init() {
+	Register("desktop", func(config.NotifySink) (Sink, error) { return desktopSink{}, nil })
+	Register("terminal", func(cfg config.NotifySink) (Sink, error) {
+		path := cfg.Path
+		if path == "" {
+			path = "/dev/tty"
+		}
+		return terminalSink{path: path}, nil
+	})
+	Register("webhook", func(cfg config.NotifySink) (Sink, error) {
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("url is required")
+		}
+		return webhookSink{url: cfg.URL, headers: cfg.Headers}, nil
+	})
+	Register("file", func(cfg config.NotifySink) (Sink, error) {
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("path is required")
+		}
+		return fileSink{path: cfg.Path}, nil
+	})
+}
+
+// desktopSink shows a desktop notification: osascript on macOS, otherwise
+// notify-send, falling back to calling the freedesktop notification service
+// over D-Bus with gdbus.
+type desktopSink struct{}
+
+func (desktopSink) Name() string { return "desktop" }
+
+func (desktopSink) Send(ctx context.Context, n Notification) error {
+	var cmd *exec.Cmd
+	switch {
+	case runtime.GOOS == "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(n.Message), appleScriptString(n.Title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case toolExists("notify-send"):
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name="+config.DisplayName, n.Title, n.Message)
+	case toolExists("gdbus"):
+		cmd = exec.CommandContext(ctx, "gdbus", "call", "--session",
+			"--dest", "org.freedesktop.Notifications",
+			"--object-path", "/org/freedesktop/Notifications",
+			"--method", "org.freedesktop.Notifications.Notify",
+			config.DisplayName, "0", "", n.Title, n.Message, "[]", "{}", "-1")
+	default:
+		return fmt.Errorf("no notifier found (need notify-send or gdbus)")
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// terminalSink rings the terminal bell and sends an OSC 9 notification,
+// which terminals such as iTerm2, kitty and WezTerm show natively. It writes
+// to the terminal device because hook stdout is read by Claude Code.
+type terminalSink struct {
+	path string
+}
+
+func (terminalSink) Name() string { return "terminal" }
+
+func (s terminalSink) Send(_ context.Context, n Notification) error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Control characters would end the escape sequence early
+	msg := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, n.Title+": "+n.Message)
+	_, err = fmt.Fprintf(f, "\a\x1b]9;%s\x07", msg)
+	return err
+}
+
+// webhookSink POSTs the notification as JSON.
+type webhookSink struct {
+	url     string
+	headers map[string]string
+}
+
+func (webhookSink) Name() string { return "webhook" }
+
+func (s webhookSink) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return redactURLError(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return redactURLError(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: %s", config.RedactURL(s.url), resp.Status)
+	}
+	return nil
+}
+
+// redactURLError redacts the URL in an HTTP client error. Slack, ntfy and
+// similar services put the secret in the URL, and errors end up in logs.
+func redactURLError(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		redacted := *uerr
+		redacted.URL = config.RedactURL(uerr.URL)
+		return &redacted
+	}
+	return err
+}
+
+// fileSink appends each notification as a JSON line. Useful for tests and
+// for feeding other tools.
+type fileSink struct {
+	path string
+}
+
+func (fileSink) Name() string { return "file" }
+
+func (s fileSink) Send(_ context.Context, n Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// toolExists checks if a command-line tool is available on PATH.
+func toolExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -0,0 +1,67 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/notify"
+)
+
+func TestNotificationFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		input Input
+		event string
+		msg   string
+	}{
+		{"stop", Input{HookEventName: "Stop"}, notify.EventStop, "Claude has finished and is waiting for you"},
+		{"stop hook active", Input{HookEventName: "Stop", StopHookActive: true}, "", ""},
+		{"notification", Input{HookEventName: "Notification", Message: "Claude needs your permission to use Bash"}, notify.EventNotification, "Claude needs your permission to use Bash"},
+		{"other", Input{HookEventName: "PostToolUse"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := notificationFor(&tt.input)
+			if tt.event == "" {
+				if n != nil {
+					t.Errorf("expected no notification, got %+v", n)
+				}
+				return
+			}
+			if n == nil || n.Event != tt.event || n.Message != tt.msg {
+				t.Errorf("notificationFor() = %+v, want %s %q", n, tt.event, tt.msg)
+			}
+		})
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.jsonl")
+	cfg := config.Defaults()
+	cfg.Notify.Sinks = []config.NotifySink{{Type: "file", Path: path}}
+
+	input := &Input{SessionID: "s1", Cwd: "/home/dev/myproject", HookEventName: "Notification", Message: "Waiting for input"}
+	sendNotification(input, cfg, *notificationFor(input))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n notify.Notification
+	if err := json.Unmarshal(data, &n); err != nil {
+		t.Fatal(err)
+	}
+	if n.SessionID != "s1" || n.Project != "myproject" || !strings.HasSuffix(n.Title, "myproject") {
+		t.Errorf("notification = %+v, want session and project filled in", n)
+	}
+}
+
+func TestNotifyRegistered(t *testing.T) {
+	if _, ok := registry["notify"]; !ok {
+		t.Error("notify not registered")
+	}
+}
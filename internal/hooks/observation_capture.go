@@ -0,0 +1,479 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/shellparse"
+	"github.com/itk-dev/itkdev-claude-code/internal/session"
+)
+
+func init() {
+	Register("observation-capture", observationCaptureHook)
+}
+
+const (
+	// captureMaxPerMinute limits how many observations a session records per minute.
+	captureMaxPerMinute = 10
+
+	// captureMaxPerSession caps the total observations captured for one session.
+	captureMaxPerSession = 200
+
+	// captureDedupWindow suppresses repeats of the same observation key
+	// (e.g. consecutive edits to one file) within this window.
+	captureDedupWindow = 10 * time.Minute
+)
+
+// capturedObservation is an observation derived from a tool call.
+type capturedObservation struct {
+	Type     string
+	Title    string
+	Text     string
+	Metadata map[string]any
+	key      string // dedup key
+}
+
+// bashToolResponse matches the Bash tool_response fields we inspect. The exit
+// code is not always present; exitCode falls back to parsing the output.
+type bashToolResponse struct {
+	Stdout      string `json:"stdout"`
+	Stderr      string `json:"stderr"`
+	Interrupted bool   `json:"interrupted"`
+	ExitCode    *int   `json:"exit_code,omitempty"`
+	ReturnCode  *int   `json:"returnCode,omitempty"`
+}
+
+// observationCaptureHook runs on PostToolUse and records what happened in the
+// session (file changes, commits, test runs, failing commands) as observations
+// tied to ICC_SESSION_ID. Captures are rate limited and deduplicated per session.
+func observationCaptureHook(input *Input) error {
+	client := consoleClientFromEnv()
+	sessionID := os.Getenv(config.EnvPrefix + "_SESSION_ID")
+	if client == nil || sessionID == "" {
+		ExitOK()
+		return nil
+	}
+
+	updateCaptureState(resolveSessionDir(), func(state *captureState) {
+		obs := classifyToolUse(input, state)
+		now := time.Now()
+		if obs != nil && state.allow(obs.key, now) {
+			if err := postObservation(client, sessionID, projectName(input.Cwd), obs); err == nil {
+				state.record(obs.key, now)
+			}
+		}
+	})
+	ExitOK()
+	return nil
+}
+
+// classifyToolUse turns a PostToolUse event into an observation, or nil when
+// the event is not worth recording.
+func classifyToolUse(input *Input, state *captureState) *capturedObservation {
+	switch input.ToolName {
+	case "Write", "Edit", "MultiEdit":
+		return classifyFileChange(input)
+	case "Bash":
+		var bash BashToolInput
+		if err := json.Unmarshal(input.ToolInput, &bash); err != nil || strings.TrimSpace(bash.Command) == "" {
+			return nil
+		}
+		var resp bashToolResponse
+		json.Unmarshal(input.ToolResponse, &resp) //nolint:errcheck
+		return classifyBash(strings.TrimSpace(bash.Command), &resp, state)
+	default:
+		return nil
+	}
+}
+
+func classifyFileChange(input *Input) *capturedObservation {
+	filePath := extractFilePath(input)
+	if filePath == "" {
+		return nil
+	}
+	display := relativeToCwd(filePath, input.Cwd)
+
+	verb := "Edited"
+	if input.ToolName == "Write" {
+		verb = "Wrote"
+	}
+
+	text := fmt.Sprintf("%s %s", verb, display)
+	if input.ToolName == "Edit" {
+		var edit EditToolInput
+		if err := json.Unmarshal(input.ToolInput, &edit); err == nil {
+			text += fmt.Sprintf(" (-%d +%d lines)", countLines(edit.OldString), countLines(edit.NewString))
+		}
+	}
+
+	return &capturedObservation{
+		Type:  "change",
+		Title: verb + " " + filepath.Base(filePath),
+		Text:  text,
+		Metadata: map[string]any{
+			"tool": input.ToolName,
+			"file": filePath,
+		},
+		key: "file:" + filePath,
+	}
+}
+
+// gitCommitRe matches a git commit invocation anywhere in a command line.
+var gitCommitRe = regexp.MustCompile(`(^|[;&|\s])git\s+(-C\s+\S+\s+)?commit\b`)
+
+// commitSummaryRe matches git's "[branch abc1234] message" commit summary line.
+var commitSummaryRe = regexp.MustCompile(`(?m)^\[([^\]\s]+)[^\]]*?\s([0-9a-f]{7,40})\]\s+(.+)$`)
+
+// testCommands are command prefixes recognised as test runs.
+var testCommands = []string{
+	"go test", "npm test", "npm run test", "yarn test", "pnpm test", "bun test",
+	"pytest", "python -m pytest", "uv run pytest", "phpunit", "vendor/bin/phpunit",
+	"composer test", "cargo test", "jest", "npx jest", "vitest", "npx vitest",
+	"make test",
+}
+
+func classifyBash(cmd string, resp *bashToolResponse, state *captureState) *capturedObservation {
+	exitCode := resp.exitCode()
+	short := truncate(firstLine(cmd), 80)
+
+	if gitCommitRe.MatchString(cmd) {
+		m := commitSummaryRe.FindStringSubmatch(resp.Stdout)
+		if m == nil {
+			return nil // commit did not happen (nothing to commit, hook rejected, ...)
+		}
+		return &capturedObservation{
+			Type:  "change",
+			Title: "Committed: " + truncate(m[3], 80),
+			Text:  fmt.Sprintf("Committed %s on branch %s: %s", m[2], m[1], m[3]),
+			Metadata: map[string]any{
+				"tool":    "Bash",
+				"command": cmd,
+				"branch":  m[1],
+				"commit":  m[2],
+			},
+			key: "commit:" + m[2],
+		}
+	}
+
+	if isTestCommand(cmd) {
+		failed := exitCode > 0 || testOutputFailed(resp.Stdout+"\n"+resp.Stderr)
+		wasFailing := state.FailingTests[cmd]
+		switch {
+		case failed:
+			state.FailingTests[cmd] = true
+			return &capturedObservation{
+				Type:  "discovery",
+				Title: "Tests failing: " + short,
+				Text:  fmt.Sprintf("`%s` failed:\n%s", cmd, tail(resp.Stdout+"\n"+resp.Stderr, 600)),
+				Metadata: map[string]any{
+					"tool":      "Bash",
+					"command":   cmd,
+					"exit_code": exitCode,
+				},
+				key: "test-fail:" + cmd,
+			}
+		case wasFailing:
+			delete(state.FailingTests, cmd)
+			return &capturedObservation{
+				Type:  "bugfix",
+				Title: "Tests passing again: " + short,
+				Text:  fmt.Sprintf("`%s` passes after previously failing in this session.", cmd),
+				Metadata: map[string]any{
+					"tool":    "Bash",
+					"command": cmd,
+				},
+				key: "test-fixed:" + cmd,
+			}
+		default:
+			return nil
+		}
+	}
+
+	if exitCode > 0 && !resp.Interrupted {
+		return &capturedObservation{
+			Type:  "discovery",
+			Title: fmt.Sprintf("Command failed (exit %d): %s", exitCode, short),
+			Text:  fmt.Sprintf("`%s` exited with code %d:\n%s", cmd, exitCode, tail(resp.Stderr, 600)),
+			Metadata: map[string]any{
+				"tool":      "Bash",
+				"command":   cmd,
+				"exit_code": exitCode,
+			},
+			key: "cmd-fail:" + cmd,
+		}
+	}
+
+	return nil
+}
+
+// exitCodeRe extracts "Exit code N" as reported in Bash tool error output.
+var exitCodeRe = regexp.MustCompile(`(?i)exit code:?\s+(\d+)`)
+
+// exitCode returns the command's exit code, 0 if it succeeded or is unknown.
+func (r *bashToolResponse) exitCode() int {
+	if r.ExitCode != nil {
+		return *r.ExitCode
+	}
+	if r.ReturnCode != nil {
+		return *r.ReturnCode
+	}
+	if m := exitCodeRe.FindStringSubmatch(r.Stderr); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// isTestCommand reports whether any command in a command line runs tests.
+func isTestCommand(cmd string) bool {
+	var lines []string
+	if cmds, err := shellparse.Parse(cmd); err == nil {
+		for _, c := range cmds {
+			lines = append(lines, strings.Join(append([]string{c.Name}, c.Args...), " "))
+		}
+	} else {
+		lines = []string{strings.TrimSpace(cmd)}
+	}
+
+	for _, trimmed := range lines {
+		for _, prefix := range testCommands {
+			if trimmed == prefix || strings.HasPrefix(trimmed, prefix+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// testFailureMarkers are output fragments that indicate a failed test run
+// when the exit code is unavailable.
+var testFailureMarkers = []string{"\nFAIL", "--- FAIL", "FAILED", "Tests failed", "failures!"}
+
+func testOutputFailed(output string) bool {
+	output = "\n" + output
+	for _, m := range testFailureMarkers {
+		if strings.Contains(output, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// postObservation sends a captured observation to the console's
+// /api/observations endpoint.
+func postObservation(client *session.ConsoleClient, sessionID, project string, obs *capturedObservation) error {
+	meta := map[string]any{"source": "observation-capture"}
+	for k, v := range obs.Metadata {
+		meta[k] = v
+	}
+	metaJSON, _ := json.Marshal(meta)
+
+	resp, err := client.Post("/api/observations", map[string]string{
+		"session_id": sessionID,
+		"type":       obs.Type,
+		"title":      obs.Title,
+		"text":       obs.Text,
+		"project":    project,
+		"metadata":   string(metaJSON),
+	})
+	if err != nil {
+		return fmt.Errorf("post observation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// captureState is the per-session rate-limit and dedup state persisted to
+// {sessionDir}/observation-capture.json.
+type captureState struct {
+	Total        int                  `json:"total"`
+	Recent       []time.Time          `json:"recent"`
+	Seen         map[string]time.Time `json:"seen"`
+	FailingTests map[string]bool      `json:"failing_tests"`
+}
+
+// allow reports whether an observation with the given key may be captured now.
+func (s *captureState) allow(key string, now time.Time) bool {
+	if s.Total >= captureMaxPerSession {
+		return false
+	}
+	if last, ok := s.Seen[key]; ok && now.Sub(last) < captureDedupWindow {
+		return false
+	}
+	recent := 0
+	for _, t := range s.Recent {
+		if now.Sub(t) < time.Minute {
+			recent++
+		}
+	}
+	return recent < captureMaxPerMinute
+}
+
+// record notes a captured observation and prunes expired entries.
+func (s *captureState) record(key string, now time.Time) {
+	s.Total++
+	s.Seen[key] = now
+
+	kept := s.Recent[:0]
+	for _, t := range s.Recent {
+		if now.Sub(t) < time.Minute {
+			kept = append(kept, t)
+		}
+	}
+	s.Recent = append(kept, now)
+
+	for k, t := range s.Seen {
+		if now.Sub(t) >= captureDedupWindow {
+			delete(s.Seen, k)
+		}
+	}
+}
+
+func captureStateFile(sessionDir string) string {
+	return filepath.Join(sessionDir, "observation-capture.json")
+}
+
+// updateCaptureState loads the session's capture state, lets fn change it
+// and saves it. The hook runs asynchronously, so several may run at once;
+// the state file is locked meanwhile, so no update is lost. If the lock
+// can't be taken, the state is updated without it.
+func updateCaptureState(sessionDir string, fn func(*captureState)) {
+	if unlock, err := lockCaptureState(sessionDir); err == nil {
+		defer unlock()
+	}
+	state := loadCaptureState(sessionDir)
+	fn(state)
+	saveCaptureState(sessionDir, state)
+}
+
+// lockCaptureState takes an exclusive lock on the session's capture state
+// and returns the func that releases it.
+func lockCaptureState(sessionDir string) (func(), error) {
+	if err := os.MkdirAll(sessionDir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(captureStateFile(sessionDir)+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	// Closing the file releases the lock, as does exiting
+	return func() { f.Close() }, nil
+}
+
+func loadCaptureState(sessionDir string) *captureState {
+	s := &captureState{}
+	if data, err := os.ReadFile(captureStateFile(sessionDir)); err == nil {
+		json.Unmarshal(data, s) //nolint:errcheck
+	}
+	if s.Seen == nil {
+		s.Seen = map[string]time.Time{}
+	}
+	if s.FailingTests == nil {
+		s.FailingTests = map[string]bool{}
+	}
+	return s
+}
+
+// saveCaptureState writes the state through a temporary file, so a reader
+// never sees a partial one.
+func saveCaptureState(sessionDir string, s *captureState) {
+	os.MkdirAll(sessionDir, 0o755) //nolint:errcheck
+	data, _ := json.Marshal(s)
+	tmp, err := os.CreateTemp(sessionDir, ".observation-capture-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), captureStateFile(sessionDir))
+	}
+	if err != nil {
+		os.Remove(tmp.Name()) //nolint:errcheck
+	}
+}
+
+// projectName derives the project name from the hook's working directory,
+// matching the name icc run registers the session under.
+func projectName(cwd string) string {
+	if cwd == "" {
+		var err error
+		if cwd, err = os.Getwd(); err != nil {
+			return ""
+		}
+	}
+	return filepath.Base(cwd)
+}
+
+// relativeToCwd shortens a path relative to cwd when it lies inside it.
+func relativeToCwd(path, cwd string) string {
+	if cwd == "" {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(s, "\n"), "\n") + 1
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// truncate shortens s to at most n bytes, ending in "...". It cuts at a
+// character boundary, so multibyte characters aren't split.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	i := n - 3
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:i] + "..."
+}
+
+// tail returns the last n bytes of s, trimmed of surrounding whitespace,
+// starting at a character boundary.
+func tail(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	i := len(s) - n
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return "..." + s[i:]
+}
@@ -0,0 +1,249 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/session"
+)
+
+func TestObservationCaptureRegistered(t *testing.T) {
+	_, ok := registry["observation-capture"]
+	if !ok {
+		t.Error("observation-capture not registered")
+	}
+}
+
+func newCaptureState() *captureState {
+	return &captureState{Seen: map[string]time.Time{}, FailingTests: map[string]bool{}}
+}
+
+func TestClassifyFileChange(t *testing.T) {
+	input := &Input{
+		ToolName:  "Edit",
+		Cwd:       "/repo",
+		ToolInput: json.RawMessage(`{"file_path": "/repo/internal/a.go", "old_string": "a", "new_string": "b\nc"}`),
+	}
+	obs := classifyToolUse(input, newCaptureState())
+	if obs == nil {
+		t.Fatal("expected observation for Edit")
+	}
+	if obs.Type != "change" {
+		t.Errorf("Type = %q, want change", obs.Type)
+	}
+	if obs.Text != "Edited internal/a.go (-1 +2 lines)" {
+		t.Errorf("Text = %q", obs.Text)
+	}
+	if obs.key != "file:/repo/internal/a.go" {
+		t.Errorf("key = %q", obs.key)
+	}
+}
+
+func TestClassifyBash(t *testing.T) {
+	exit1 := 1
+	tests := []struct {
+		name      string
+		cmd       string
+		resp      bashToolResponse
+		wantType  string
+		wantTitle string
+	}{
+		{
+			name:      "git commit",
+			cmd:       `git add . && git commit -m "Add parser"`,
+			resp:      bashToolResponse{Stdout: "[feat/parser 1a2b3c4] Add parser\n 1 file changed"},
+			wantType:  "change",
+			wantTitle: "Committed: Add parser",
+		},
+		{
+			name: "git commit with nothing to commit",
+			cmd:  `git commit -m "noop"`,
+			resp: bashToolResponse{Stdout: "nothing to commit, working tree clean"},
+		},
+		{
+			name:      "failing tests",
+			cmd:       "go test ./...",
+			resp:      bashToolResponse{Stdout: "--- FAIL: TestX\nFAIL\n"},
+			wantType:  "discovery",
+			wantTitle: "Tests failing: go test ./...",
+		},
+		{
+			name: "passing tests",
+			cmd:  "go test ./...",
+			resp: bashToolResponse{Stdout: "ok  \tpkg\t0.1s"},
+		},
+		{
+			name:      "failing command",
+			cmd:       "make build",
+			resp:      bashToolResponse{Stderr: "boom", ExitCode: &exit1},
+			wantType:  "discovery",
+			wantTitle: "Command failed (exit 1): make build",
+		},
+		{
+			name: "successful command",
+			cmd:  "ls -la",
+			resp: bashToolResponse{Stdout: "total 0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obs := classifyBash(tt.cmd, &tt.resp, newCaptureState())
+			if tt.wantType == "" {
+				if obs != nil {
+					t.Errorf("expected no observation, got %+v", obs)
+				}
+				return
+			}
+			if obs == nil {
+				t.Fatal("expected observation, got nil")
+			}
+			if obs.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", obs.Type, tt.wantType)
+			}
+			if obs.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", obs.Title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestClassifyBashTestsFixed(t *testing.T) {
+	state := newCaptureState()
+
+	classifyBash("go test ./...", &bashToolResponse{Stdout: "FAIL\n"}, state)
+	obs := classifyBash("go test ./...", &bashToolResponse{Stdout: "ok"}, state)
+	if obs == nil || obs.Type != "bugfix" {
+		t.Fatalf("expected bugfix observation after tests recover, got %+v", obs)
+	}
+	if state.FailingTests["go test ./..."] {
+		t.Error("failing test state should be cleared")
+	}
+}
+
+func TestTruncateMultibyte(t *testing.T) {
+	tests := []struct {
+		name, got, want string
+	}{
+		{"truncate ascii", truncate("abcdefghij", 8), "abcde..."},
+		{"truncate short", truncate("æøå", 6), "æøå"},
+		// "æ" is two bytes; cutting after 5 bytes would split the third
+		{"truncate", truncate("æøåæøå", 8), "æø..."},
+		{"truncate emoji", truncate("ok 🎉🎉", 9), "ok ..."},
+		{"tail ascii", tail("  abcdefghij\n", 4), "...ghij"},
+		{"tail", tail("æøåæøå", 5), "...øå"},
+		{"tail emoji", tail("🎉🎉 done", 7), "... done"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+		if !utf8.ValidString(tt.got) {
+			t.Errorf("%s = %q, not valid UTF-8", tt.name, tt.got)
+		}
+	}
+}
+
+func TestCaptureStateRateLimit(t *testing.T) {
+	s := newCaptureState()
+	now := time.Now()
+
+	for i := 0; i < captureMaxPerMinute; i++ {
+		key := "k" + string(rune('a'+i))
+		if !s.allow(key, now) {
+			t.Fatalf("capture %d should be allowed", i)
+		}
+		s.record(key, now)
+	}
+	if s.allow("another", now) {
+		t.Error("capture beyond per-minute limit should be rejected")
+	}
+	if !s.allow("another", now.Add(2*time.Minute)) {
+		t.Error("capture should be allowed once the minute has passed")
+	}
+}
+
+func TestCaptureStateDedup(t *testing.T) {
+	s := newCaptureState()
+	now := time.Now()
+
+	s.record("file:/a.go", now)
+	if s.allow("file:/a.go", now.Add(time.Minute)) {
+		t.Error("duplicate within window should be rejected")
+	}
+	if !s.allow("file:/a.go", now.Add(captureDedupWindow)) {
+		t.Error("duplicate after window should be allowed")
+	}
+}
+
+func TestCaptureStatePersistence(t *testing.T) {
+	dir := t.TempDir()
+	s := loadCaptureState(dir)
+	s.record("file:/a.go", time.Now())
+	s.FailingTests["go test"] = true
+	saveCaptureState(dir, s)
+
+	got := loadCaptureState(dir)
+	if got.Total != 1 || !got.FailingTests["go test"] {
+		t.Errorf("state not persisted: %+v", got)
+	}
+}
+
+func TestCaptureStateConcurrentUpdates(t *testing.T) {
+	dir := t.TempDir()
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			updateCaptureState(dir, func(s *captureState) {
+				s.Total++
+			})
+		}()
+	}
+	wg.Wait()
+
+	if got := loadCaptureState(dir).Total; got != n {
+		t.Errorf("Total = %d after %d concurrent updates, want %d", got, n, n)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, ".observation-capture-*")); len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestPostObservation(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/observations" {
+			t.Errorf("expected /api/observations path, got %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := session.NewConsoleClient(server.URL)
+	err := postObservation(client, "icc-1-1", "proj", &capturedObservation{
+		Type:     "change",
+		Title:    "Edited a.go",
+		Text:     "Edited a.go",
+		Metadata: map[string]any{"file": "/a.go"},
+	})
+	if err != nil {
+		t.Fatalf("postObservation: %v", err)
+	}
+	if received["session_id"] != "icc-1-1" || received["project"] != "proj" {
+		t.Errorf("unexpected payload: %v", received)
+	}
+	if !strings.Contains(received["metadata"], `"source":"observation-capture"`) {
+		t.Errorf("metadata missing source: %s", received["metadata"])
+	}
+}
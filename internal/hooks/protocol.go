@@ -35,6 +35,10 @@ type Input struct {
 
 	// UserPromptSubmit
 	Prompt string `json:"prompt,omitempty"`
+
+	// Notification
+	Message string `json:"message,omitempty"`
+	Title   string `json:"title,omitempty"`
 }
 
 // WriteToolInput contains fields from a Write tool call.
@@ -51,6 +55,12 @@ type EditToolInput struct {
 	ReplaceAll bool   `json:"replace_all"`
 }
 
+// MultiEditToolInput contains fields from a MultiEdit tool call.
+type MultiEditToolInput struct {
+	FilePath string          `json:"file_path"`
+	Edits    []EditToolInput `json:"edits"`
+}
+
 // BashToolInput contains fields from a Bash tool call.
 type BashToolInput struct {
 	Command     string `json:"command"`
@@ -70,10 +80,11 @@ type Output struct {
 
 // HookSpecificOuput holds event-specific output fields.
 type HookSpecificOuput struct {
-	HookEventName            string `json:"hookEventName"`
-	PermissionDecision       string `json:"permissionDecision,omitempty"`
-	PermissionDecisionReason string `json:"permissionDecisionReason,omitempty"`
-	AdditionalContext        string `json:"additionalContext,omitempty"`
+	HookEventName            string         `json:"hookEventName"`
+	PermissionDecision       string         `json:"permissionDecision,omitempty"`
+	PermissionDecisionReason string         `json:"permissionDecisionReason,omitempty"`
+	AdditionalContext        string         `json:"additionalContext,omitempty"`
+	UpdatedInput             map[string]any `json:"updatedInput,omitempty"`
 }
 
 // ReadInput reads and parses the hook input JSON from stdin.
@@ -0,0 +1,260 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/secrets"
+	"github.com/itk-dev/itkdev-claude-code/internal/hooks/shellparse"
+)
+
+func init() {
+	Register("secret-guard", secretGuardHook)
+	RegisterDryRun("secret-guard", func(input *Input) (*Decision, error) {
+		return newSecretGuard(input).decide(input), nil
+	})
+}
+
+// secretGuardHook blocks Write, Edit and MultiEdit calls that would add
+// secrets to a file, and git commits whose staged changes add secrets.
+func secretGuardHook(input *Input) error {
+	if input.HookEventName != "PreToolUse" {
+		ExitOK()
+		return nil
+	}
+
+	d := newSecretGuard(input).decide(input)
+	if d == nil {
+		ExitOK()
+		return nil
+	}
+	WriteOutput(d.output())
+	return nil
+}
+
+// secretGuard scans tool calls for secrets.
+type secretGuard struct {
+	root      string // Project root; file paths are reported relative to it
+	allowlist string // Allowlist file name, for messages
+	scanner   *secrets.Scanner
+}
+
+// newSecretGuard returns the guard for a hook input, with the project's
+// allowlist. An unreadable or invalid allowlist is reported on stderr and
+// whatever could be parsed is used.
+func newSecretGuard(input *Input) *secretGuard {
+	cwd := input.Cwd
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	cfg := loadConfig(input).SecretGuard
+	root := projectRoot(cwd)
+
+	var allow *secrets.Allowlist
+	if cfg.Allowlist != "" {
+		file := cfg.Allowlist
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(root, file)
+		}
+		var err error
+		if allow, err = secrets.LoadAllowlist(file); err != nil {
+			fmt.Fprintf(os.Stderr, "icc: %v\n", err)
+		}
+	}
+	return &secretGuard{
+		root:      root,
+		allowlist: cfg.Allowlist,
+		scanner:   secrets.New(cfg, allow),
+	}
+}
+
+// decide returns a deny decision if the tool call adds secrets, or nil.
+func (g *secretGuard) decide(input *Input) *Decision {
+	var findings []secrets.Finding
+	commit := false
+	switch input.ToolName {
+	case "Write":
+		var w WriteToolInput
+		if json.Unmarshal(input.ToolInput, &w) != nil {
+			return nil
+		}
+		file := g.relative(w.FilePath)
+		// Secrets that are already in the file aren't new
+		old, _ := os.ReadFile(w.FilePath)
+		findings = secrets.Introduced(g.scanner.Scan(file, w.Content), g.scanner.Scan(file, string(old)))
+	case "Edit":
+		var e EditToolInput
+		if json.Unmarshal(input.ToolInput, &e) != nil {
+			return nil
+		}
+		findings = g.scanEdits(e.FilePath, []EditToolInput{e})
+	case "MultiEdit":
+		var m MultiEditToolInput
+		if json.Unmarshal(input.ToolInput, &m) != nil {
+			return nil
+		}
+		findings = g.scanEdits(m.FilePath, m.Edits)
+	case "Bash":
+		var bash BashToolInput
+		if json.Unmarshal(input.ToolInput, &bash) != nil {
+			return nil
+		}
+		var err error
+		if findings, err = g.scanCommits(bash.Command, input.Cwd); err != nil {
+			if !strings.Contains(bash.Command, "commit") {
+				return nil
+			}
+			return unparsedDecision("secrets in commits", err)
+		}
+		commit = true
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+	return &Decision{Rule: findings[0].Rule, Action: "deny", Reason: g.message(findings, commit)}
+}
+
+// scanEdits returns the secrets edits add. Line numbers refer to the edit,
+// not the file, so they are dropped.
+func (g *secretGuard) scanEdits(path string, edits []EditToolInput) []secrets.Finding {
+	file := g.relative(path)
+	var findings []secrets.Finding
+	for _, e := range edits {
+		for _, f := range secrets.Introduced(g.scanner.Scan(file, e.NewString), g.scanner.Scan(file, e.OldString)) {
+			f.Line = 0
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// scanCommits returns the secrets in the changes of each git commit in a
+// Bash command: the staged changes, and what git add commands before it
+// in the command will stage, which the index doesn't hold yet. It returns
+// an error for commands that don't parse.
+func (g *secretGuard) scanCommits(command, cwd string) ([]secrets.Finding, error) {
+	cmds, err := shellparse.Parse(command)
+	if err != nil {
+		return nil, err
+	}
+	var findings []secrets.Finding
+	gits := shellparse.GitCommands(cmds)
+	for i, git := range gits {
+		if git.Subcommand != "commit" {
+			continue
+		}
+		if diff, err := stagedDiff(git.WorkDir(cwd), git.HasFlag("-a", "--all")); err == nil {
+			findings = append(findings, g.scanner.ScanDiff(diff)...)
+		}
+		for _, add := range gits[:i] {
+			if add.Subcommand == "add" {
+				findings = append(findings, g.scanAdd(add, cwd)...)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// maxScannedFile is the size above which new files aren't scanned; they
+// are unlikely to be hand-written config.
+const maxScannedFile = 1 << 20
+
+// scanAdd returns the secrets a git add will stage: the unstaged changes
+// to tracked files in its pathspecs and, unless it is add -u, the new
+// files git doesn't ignore.
+func (g *secretGuard) scanAdd(add shellparse.Git, cwd string) []secrets.Finding {
+	if add.HasFlag("-n", "--dry-run") {
+		return nil
+	}
+	dir := add.WorkDir(cwd)
+	paths := positionalArgs(add.SubArgs, "--chmod", "--pathspec-from-file")
+	if len(paths) == 0 {
+		if !add.HasFlag("-A", "--all", "-u", "--update") {
+			return nil
+		}
+		paths = []string{":/"} // The whole work tree
+	}
+
+	var findings []secrets.Finding
+	if diff, err := gitOutput(dir, append([]string{"diff", "--no-color", "--no-ext-diff", "-U0", "--"}, paths...)...); err == nil {
+		findings = g.scanner.ScanDiff(diff)
+	}
+	if add.HasFlag("-u", "--update") {
+		return findings
+	}
+
+	args := []string{"ls-files", "-z", "--others"}
+	if !add.HasFlag("-f", "--force") {
+		args = append(args, "--exclude-standard")
+	}
+	out, err := gitOutput(dir, append(append(args, "--"), paths...)...)
+	if err != nil {
+		return findings
+	}
+	for _, name := range strings.Split(out, "\x00") {
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil || len(data) > maxScannedFile || bytes.IndexByte(data, 0) >= 0 {
+			continue // Unreadable, large or binary
+		}
+		findings = append(findings, g.scanner.Scan(g.relative(path), string(data))...)
+	}
+	return findings
+}
+
+// relative returns path relative to the project root, if it is inside it.
+func (g *secretGuard) relative(path string) string {
+	if rel, err := filepath.Rel(g.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// message lists the findings, redacted, and how to proceed.
+func (g *secretGuard) message(findings []secrets.Finding, commit bool) string {
+	var sb strings.Builder
+	if commit {
+		sb.WriteString("Blocked: the changes being committed contain secrets:\n")
+	} else {
+		sb.WriteString("Blocked: this would write secrets to disk:\n")
+	}
+	for _, f := range findings {
+		sb.WriteString("  - " + f.String() + "\n")
+	}
+	sb.WriteString("\nReference secrets through environment variables instead of writing them into files.")
+	if commit {
+		sb.WriteString(" Remove them from the staged files (or unstage them with `git restore --staged <file>`) before committing.")
+	}
+	if g.allowlist != "" {
+		fmt.Fprintf(&sb, " If this is a false positive, add the value, or path:<glob> for the file, to %s.", g.allowlist)
+	}
+	return sb.String()
+}
+
+// stagedDiff returns the changes a commit in dir would record: the index,
+// or all tracked changes for commit -a.
+func stagedDiff(dir string, all bool) (string, error) {
+	if all {
+		return gitOutput(dir, "diff", "HEAD", "--no-color", "--no-ext-diff", "-U0")
+	}
+	return gitOutput(dir, "diff", "--cached", "--no-color", "--no-ext-diff", "-U0")
+}
+
+// gitOutput runs git in dir and returns its output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
@@ -2,8 +2,10 @@ package hooks
 
 import (
 	"os"
+	"strconv"
 
 	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/session"
 )
 
 // resolveSessionDir returns the session directory to use for local state files.
@@ -16,3 +18,17 @@ func resolveSessionDir() string {
 	}
 	return config.SessionDir(sessionID)
 }
+
+// consoleClientFromEnv returns a console client for the port in ICC_PORT.
+// Returns nil when not running in a managed icc session or the port is invalid.
+func consoleClientFromEnv() *session.ConsoleClient {
+	portStr := os.Getenv(config.EnvPrefix + "_PORT")
+	if portStr == "" {
+		return nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil
+	}
+	return session.DefaultConsoleClient(port)
+}
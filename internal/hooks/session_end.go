@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"strconv"
 
 	"github.com/itk-dev/itkdev-claude-code/internal/config"
 	"github.com/itk-dev/itkdev-claude-code/internal/session"
@@ -15,25 +14,24 @@ func init() {
 }
 
 // sessionEndHook posts a session summary to the console server when Claude Code exits.
+// The summary is built from the session's observations, plans, tasks, touched
+// files, and continuation notes.
 func sessionEndHook(input *Input) error {
-	// Read ICC_PORT from env
-	portStr := os.Getenv(config.EnvPrefix + "_PORT")
-	if portStr == "" {
+	client := consoleClientFromEnv()
+	if client == nil {
 		// Not running in a managed icc session - exit silently
 		ExitOK()
 		return nil // unreachable
 	}
 
-	port, err := strconv.Atoi(portStr)
-	if err != nil {
-		// Invalid port - exit silently
-		ExitOK()
-		return nil // unreachable
+	// Summaries are keyed by the icc session so they line up with observations.
+	sessionID := os.Getenv(config.EnvPrefix + "_SESSION_ID")
+	if sessionID == "" {
+		sessionID = input.SessionID
 	}
 
-	// Post summary to console
-	client := session.DefaultConsoleClient(port)
-	_ = postSummary(client, input.SessionID) // Ignore errors - never block shutdown
+	in := gatherSummaryInputs(client, sessionID, resolveSessionDir(), input.TranscriptPath, input.Cwd)
+	_ = postSummary(client, buildSessionSummary(sessionID, in)) // Ignore errors - never block shutdown
 
 	// Exit cleanly
 	ExitOK()
@@ -41,13 +39,8 @@ func sessionEndHook(input *Input) error {
 }
 
 // postSummary posts a session summary to the console's /api/summaries endpoint.
-func postSummary(client *session.ConsoleClient, sessionID string) error {
-	payload := map[string]string{
-		"session_id": sessionID,
-		"text":       "Session " + sessionID + " ended",
-	}
-
-	resp, err := client.Post("/api/summaries", payload)
+func postSummary(client *session.ConsoleClient, summary *sessionSummary) error {
+	resp, err := client.Post("/api/summaries", summary)
 	if err != nil {
 		return fmt.Errorf("post summary: %w", err)
 	}
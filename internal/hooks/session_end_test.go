@@ -46,7 +46,7 @@ func TestPostSummary(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var receivedPayload map[string]string
+			var receivedPayload sessionSummary
 
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				// Verify request
@@ -73,19 +73,22 @@ func TestPostSummary(t *testing.T) {
 
 			// Test postSummary
 			client := session.NewConsoleClient(server.URL)
-			err := postSummary(client, tt.sessionID)
+			summary := buildSessionSummary(tt.sessionID, summaryInputs{Files: []string{"main.go"}})
+			err := postSummary(client, summary)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("postSummary() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
 			if !tt.wantErr {
 				// Verify payload structure
-				if receivedPayload["session_id"] != tt.sessionID {
-					t.Errorf("expected session_id=%s, got %s", tt.sessionID, receivedPayload["session_id"])
+				if receivedPayload.SessionID != tt.sessionID {
+					t.Errorf("expected session_id=%s, got %s", tt.sessionID, receivedPayload.SessionID)
 				}
-				expectedText := "Session " + tt.sessionID + " ended"
-				if receivedPayload["text"] != expectedText {
-					t.Errorf("expected text=%q, got %q", expectedText, receivedPayload["text"])
+				if receivedPayload.Text != summary.Text {
+					t.Errorf("expected text=%q, got %q", summary.Text, receivedPayload.Text)
+				}
+				if len(receivedPayload.Sections) != 1 || receivedPayload.Sections[0].Title != "Files Touched" {
+					t.Errorf("unexpected sections: %+v", receivedPayload.Sections)
 				}
 			}
 		})
@@ -98,7 +101,7 @@ func TestPostSummaryWithUnreachableServer(t *testing.T) {
 	server.Close()
 
 	client := session.NewConsoleClient(server.URL)
-	err := postSummary(client, "test-session")
+	err := postSummary(client, buildSessionSummary("test-session", summaryInputs{}))
 	if err == nil {
 		t.Error("expected error when server is unreachable")
 	}
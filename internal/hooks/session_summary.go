@@ -0,0 +1,288 @@
+package hooks
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/session"
+)
+
+// sessionSummary is the structured summary posted to /api/summaries at
+// session end.
+type sessionSummary struct {
+	SessionID string           `json:"session_id"`
+	Text      string           `json:"text"`
+	Sections  []summarySection `json:"sections"`
+}
+
+// summarySection mirrors db.SummarySection on the wire.
+type summarySection struct {
+	Title string   `json:"title"`
+	Items []string `json:"items"`
+}
+
+// summaryObservation holds the observation fields used in summaries.
+type summaryObservation struct {
+	ID    int64
+	Type  string
+	Title string
+}
+
+// summaryPlan holds the plan fields used in summaries.
+type summaryPlan struct {
+	Path      string
+	SessionID string
+	Status    string
+}
+
+// maxContinuationChars limits how much of continuation.md goes into a summary.
+const maxContinuationChars = 1500
+
+// summaryInputs collects everything a session summary is built from.
+type summaryInputs struct {
+	Observations []summaryObservation
+	Plans        []summaryPlan
+	Tasks        taskSummary
+	Files        []string
+	Continuation string
+}
+
+// gatherSummaryInputs collects summary inputs from the console server, the
+// session directory, and the transcript. Every source is optional; failures
+// simply leave that part of the summary empty.
+func gatherSummaryInputs(client *session.ConsoleClient, sessionID, sessionDir, transcriptPath, cwd string) summaryInputs {
+	in := summaryInputs{
+		Tasks: loadTaskSummary(sessionDir),
+		Files: filesFromTranscript(transcriptPath, cwd),
+	}
+	if client != nil {
+		in.Observations, _ = fetchSessionObservations(client, sessionID)
+		in.Plans, _ = fetchSessionPlans(client, sessionID)
+	}
+	if data, err := os.ReadFile(filepath.Join(sessionDir, "continuation.md")); err == nil {
+		in.Continuation = strings.TrimSpace(string(data))
+	}
+	return in
+}
+
+// buildSessionSummary renders summary inputs into a headline, a markdown
+// body, and structured sections.
+func buildSessionSummary(sessionID string, in summaryInputs) *sessionSummary {
+	var sections []summarySection
+	var headline []string
+
+	if len(in.Files) > 0 {
+		headline = append(headline, pluralize(len(in.Files), "file", "files")+" touched")
+		sections = append(sections, summarySection{Title: "Files Touched", Items: in.Files})
+	}
+
+	if len(in.Observations) > 0 {
+		counts := map[string]int{}
+		var items []string
+		for _, o := range in.Observations {
+			counts[o.Type]++
+			items = append(items, fmt.Sprintf("[%s] %s", o.Type, o.Title))
+		}
+		headline = append(headline, pluralize(len(in.Observations), "observation", "observations")+" ("+formatTypeCounts(counts)+")")
+		sections = append(sections, summarySection{Title: "Observations", Items: items})
+	}
+
+	if len(in.Plans) > 0 {
+		var items []string
+		for _, p := range in.Plans {
+			items = append(items, fmt.Sprintf("%s: %s", p.Path, p.Status))
+			headline = append(headline, "plan "+filepath.Base(p.Path)+" "+p.Status)
+		}
+		sections = append(sections, summarySection{Title: "Plans", Items: items})
+	}
+
+	if in.Tasks.Total > 0 {
+		tasks := fmt.Sprintf("%d/%d completed", in.Tasks.Completed, in.Tasks.Total)
+		headline = append(headline, "tasks "+tasks)
+		sections = append(sections, summarySection{Title: "Tasks", Items: []string{tasks}})
+	}
+
+	if in.Continuation != "" {
+		text := truncate(in.Continuation, maxContinuationChars)
+		headline = append(headline, "handed off via continuation")
+		sections = append(sections, summarySection{Title: "Continuation", Items: []string{text}})
+	}
+
+	title := "Session ended with no recorded activity"
+	if len(headline) > 0 {
+		title = strings.Join(headline, ", ")
+	}
+
+	var body strings.Builder
+	body.WriteString(title)
+	for _, sec := range sections {
+		fmt.Fprintf(&body, "\n\n## %s\n", sec.Title)
+		for _, item := range sec.Items {
+			if sec.Title == "Continuation" {
+				body.WriteString(item + "\n")
+				continue
+			}
+			body.WriteString("- " + item + "\n")
+		}
+	}
+
+	if sections == nil {
+		sections = []summarySection{}
+	}
+	return &sessionSummary{
+		SessionID: sessionID,
+		Text:      strings.TrimRight(body.String(), "\n"),
+		Sections:  sections,
+	}
+}
+
+// fetchSessionObservations loads the observations recorded for a session.
+func fetchSessionObservations(client *session.ConsoleClient, sessionID string) ([]summaryObservation, error) {
+	resp, err := client.Get(fmt.Sprintf("/api/sessions/%s/observations?limit=100", url.PathEscape(sessionID)))
+	if err != nil {
+		return nil, fmt.Errorf("get observations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var obs []summaryObservation
+	if err := json.NewDecoder(resp.Body).Decode(&obs); err != nil {
+		return nil, fmt.Errorf("decode observations: %w", err)
+	}
+	// The API returns most recent first; summaries read chronologically.
+	sort.Slice(obs, func(i, j int) bool { return obs[i].ID < obs[j].ID })
+	return obs, nil
+}
+
+// fetchSessionPlans loads the plans registered by a session.
+func fetchSessionPlans(client *session.ConsoleClient, sessionID string) ([]summaryPlan, error) {
+	resp, err := client.Get("/api/plans?limit=100")
+	if err != nil {
+		return nil, fmt.Errorf("get plans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var all []summaryPlan
+	if err := json.NewDecoder(resp.Body).Decode(&all); err != nil {
+		return nil, fmt.Errorf("decode plans: %w", err)
+	}
+
+	var plans []summaryPlan
+	for _, p := range all {
+		if p.SessionID == sessionID {
+			plans = append(plans, p)
+		}
+	}
+	return plans, nil
+}
+
+// transcriptEntry matches the parts of a transcript JSONL line that carry
+// tool calls.
+type transcriptEntry struct {
+	Message struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+type transcriptContent struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Input struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	} `json:"input"`
+}
+
+// fileEditingTools are the tools whose file paths count as touched files.
+var fileEditingTools = map[string]bool{
+	"Write": true, "Edit": true, "MultiEdit": true, "NotebookEdit": true,
+}
+
+// filesFromTranscript returns the files written or edited during the session,
+// in first-touched order, relative to cwd where possible.
+func filesFromTranscript(transcriptPath, cwd string) []string {
+	if transcriptPath == "" {
+		return nil
+	}
+	f, err := os.Open(transcriptPath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	seen := map[string]bool{}
+	var files []string
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !strings.Contains(string(line), `"tool_use"`) {
+			continue
+		}
+		var entry transcriptEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		var contents []transcriptContent
+		if err := json.Unmarshal(entry.Message.Content, &contents); err != nil {
+			continue
+		}
+		for _, c := range contents {
+			if c.Type != "tool_use" || !fileEditingTools[c.Name] {
+				continue
+			}
+			path := c.Input.FilePath
+			if path == "" {
+				path = c.Input.NotebookPath
+			}
+			if path == "" || seen[path] {
+				continue
+			}
+			seen[path] = true
+			files = append(files, relativeToCwd(path, cwd))
+		}
+	}
+	return files
+}
+
+// formatTypeCounts renders observation counts by type, e.g. "2 change, 1 bugfix".
+func formatTypeCounts(counts map[string]int) string {
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = fmt.Sprintf("%d %s", counts[t], t)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
@@ -0,0 +1,133 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/session"
+)
+
+func TestBuildSessionSummary(t *testing.T) {
+	in := summaryInputs{
+		Files: []string{"internal/a.go", "README.md"},
+		Observations: []summaryObservation{
+			{ID: 1, Type: "change", Title: "Edited a.go"},
+			{ID: 2, Type: "bugfix", Title: "Tests passing again"},
+			{ID: 3, Type: "change", Title: "Committed: Add parser"},
+		},
+		Plans:        []summaryPlan{{Path: "docs/plans/parser.md", Status: "VERIFIED"}},
+		Tasks:        taskSummary{Completed: 3, Total: 4},
+		Continuation: "Next: wire the parser into the CLI.",
+	}
+
+	got := buildSessionSummary("icc-1", in)
+
+	wantHeadline := "2 files touched, 3 observations (2 change, 1 bugfix), plan parser.md VERIFIED, tasks 3/4 completed, handed off via continuation"
+	if headline := strings.SplitN(got.Text, "\n", 2)[0]; headline != wantHeadline {
+		t.Errorf("headline = %q\nwant       %q", headline, wantHeadline)
+	}
+
+	var titles []string
+	for _, sec := range got.Sections {
+		titles = append(titles, sec.Title)
+	}
+	if strings.Join(titles, ",") != "Files Touched,Observations,Plans,Tasks,Continuation" {
+		t.Errorf("section titles = %v", titles)
+	}
+	if !strings.Contains(got.Text, "- [bugfix] Tests passing again") {
+		t.Errorf("text missing observation item:\n%s", got.Text)
+	}
+	if !strings.Contains(got.Text, "Next: wire the parser into the CLI.") {
+		t.Errorf("text missing continuation:\n%s", got.Text)
+	}
+}
+
+func TestBuildSessionSummaryEmpty(t *testing.T) {
+	got := buildSessionSummary("icc-1", summaryInputs{})
+	if got.Text != "Session ended with no recorded activity" {
+		t.Errorf("Text = %q", got.Text)
+	}
+	if got.Sections == nil || len(got.Sections) != 0 {
+		t.Errorf("Sections = %#v, want empty slice", got.Sections)
+	}
+}
+
+func TestBuildSessionSummaryLongContinuation(t *testing.T) {
+	// A multi-byte character straddles the limit
+	text := strings.Repeat("a", maxContinuationChars-1) + strings.Repeat("æ", 10)
+	got := buildSessionSummary("icc-1", summaryInputs{Continuation: text})
+
+	cont := got.Sections[len(got.Sections)-1].Items[0]
+	if !utf8.ValidString(cont) || !strings.HasSuffix(cont, "a...") || len(cont) > maxContinuationChars {
+		t.Errorf("continuation = %q (%d bytes), want it cut before the first æ", cont[len(cont)-10:], len(cont))
+	}
+	if !utf8.ValidString(got.Text) {
+		t.Error("summary text is not valid UTF-8")
+	}
+}
+
+func TestFilesFromTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	lines := []string{
+		`{"type":"user","message":{"role":"user","content":"please fix"}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"ok"},{"type":"tool_use","name":"Edit","input":{"file_path":"/repo/a.go"}}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Read","input":{"file_path":"/repo/b.go"}}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Write","input":{"file_path":"/repo/docs/c.md"}}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Edit","input":{"file_path":"/repo/a.go"}}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"NotebookEdit","input":{"notebook_path":"/other/n.ipynb"}}]}}`,
+		`not json "tool_use"`,
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := filesFromTranscript(path, "/repo")
+	want := []string{"a.go", "docs/c.md", "/other/n.ipynb"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+
+	if files := filesFromTranscript(filepath.Join(t.TempDir(), "missing.jsonl"), "/repo"); files != nil {
+		t.Errorf("expected nil for missing transcript, got %v", files)
+	}
+}
+
+func TestGatherSummaryInputs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/sessions/icc-1/observations":
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"ID": 2, "Type": "bugfix", "Title": "second"},
+				{"ID": 1, "Type": "change", "Title": "first"},
+			})
+		case "/api/plans":
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"Path": "mine.md", "SessionID": "icc-1", "Status": "PENDING"},
+				{"Path": "other.md", "SessionID": "icc-2", "Status": "COMPLETE"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "continuation.md"), []byte("  carry on  \n"), 0o644)
+
+	in := gatherSummaryInputs(session.NewConsoleClient(server.URL), "icc-1", dir, "", "")
+	if len(in.Observations) != 2 || in.Observations[0].Title != "first" {
+		t.Errorf("observations = %+v, want chronological order", in.Observations)
+	}
+	if len(in.Plans) != 1 || in.Plans[0].Path != "mine.md" {
+		t.Errorf("plans = %+v, want only this session's plan", in.Plans)
+	}
+	if in.Continuation != "carry on" {
+		t.Errorf("continuation = %q", in.Continuation)
+	}
+}
@@ -0,0 +1,227 @@
+package shellparse
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Git is a git invocation with its global options resolved.
+type Git struct {
+	Command
+	Dir        string   // Working directory from -C, relative to the caller's; "" if unset
+	Config     []string // -c name=value settings
+	Subcommand string   // e.g. "push"; "" for a bare "git" or "git --version"
+	SubArgs    []string // Arguments after the subcommand
+	// DynamicDir is set when -C, --git-dir or --work-tree is an expansion,
+	// so the repository is only known when the command runs.
+	DynamicDir bool
+}
+
+// gitGlobalWithValue lists git's global options that take a separate value.
+var gitGlobalWithValue = map[string]bool{
+	"-C": true, "-c": true, "--git-dir": true, "--work-tree": true,
+	"--namespace": true, "--config-env": true,
+}
+
+// GitCommands returns the git invocations among cmds.
+func GitCommands(cmds []Command) []Git {
+	var out []Git
+	for _, c := range cmds {
+		if c.Base() != "git" {
+			continue
+		}
+		g := Git{Command: c}
+		args := c.Args
+	global:
+		for len(args) > 0 {
+			a := args[0]
+			switch {
+			case a == "-C" && len(args) > 1:
+				g.Dir = joinDir(g.Dir, args[1])
+				g.DynamicDir = g.DynamicDir || c.IsDynamic(args[1])
+				args = args[2:]
+			case a == "-c" && len(args) > 1:
+				g.Config = append(g.Config, args[1])
+				args = args[2:]
+			case gitGlobalWithValue[a] && len(args) > 1:
+				if a == "--git-dir" || a == "--work-tree" {
+					g.DynamicDir = g.DynamicDir || c.IsDynamic(args[1])
+				}
+				args = args[2:]
+			case strings.HasPrefix(a, "-"):
+				if strings.HasPrefix(a, "--git-dir=") || strings.HasPrefix(a, "--work-tree=") {
+					g.DynamicDir = g.DynamicDir || c.IsDynamic(a)
+				}
+				args = args[1:]
+			default:
+				break global
+			}
+		}
+		if len(args) > 0 {
+			g.Subcommand, g.SubArgs = args[0], args[1:]
+		}
+		out = append(out, g)
+	}
+	return out
+}
+
+// joinDir applies a -C directory on top of an earlier one.
+func joinDir(base, dir string) string {
+	if base == "" || filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(base, dir)
+}
+
+// WorkDir returns the directory git runs in, given the caller's directory.
+func (g Git) WorkDir(cwd string) string {
+	if g.Dir == "" {
+		return cwd
+	}
+	if filepath.IsAbs(g.Dir) || cwd == "" {
+		return g.Dir
+	}
+	return filepath.Join(cwd, g.Dir)
+}
+
+// Flags returns the options among the subcommand arguments, up to "--".
+func (g Git) Flags() []string {
+	var flags []string
+	for _, a := range g.SubArgs {
+		if a == "--" {
+			break
+		}
+		if strings.HasPrefix(a, "-") && a != "-" {
+			flags = append(flags, a)
+		}
+	}
+	return flags
+}
+
+// HasFlag reports whether any of the given options was passed to the
+// subcommand. Long options match with or without "=value"; short options
+// also match inside combined flags such as "-fu".
+func (g Git) HasFlag(names ...string) bool {
+	for _, f := range g.Flags() {
+		for _, name := range names {
+			if f == name || strings.HasPrefix(f, name+"=") {
+				return true
+			}
+			if len(name) == 2 && name[0] == '-' && !strings.HasPrefix(f, "--") && strings.ContainsRune(f[1:], rune(name[1])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Refspec is one refspec of a push, e.g. "+HEAD:refs/heads/main".
+type Refspec struct {
+	Src   string // Empty for a deletion (":branch")
+	Dst   string // Equal to Src when the refspec has no colon
+	Force bool   // Leading "+"
+}
+
+// Push holds the resolved options and refspecs of a git push.
+type Push struct {
+	Remote   string
+	Refspecs []Refspec
+	Force    bool // --force, --force-with-lease, --force-if-includes or a "+" refspec
+	Delete   bool // --delete: the refspecs name branches to delete
+	All      bool // --all or --branches: every local branch
+	Mirror   bool
+	Tags     bool // --tags, with no branch refspecs implied
+}
+
+// pushOptsWithValue lists git push options that take a separate value.
+var pushOptsWithValue = map[string]bool{
+	"-o": true, "--push-option": true, "--repo": true,
+	"--receive-pack": true, "--exec": true,
+}
+
+// Push resolves the arguments of a push. It returns false if g is not a push.
+func (g Git) Push() (Push, bool) {
+	if g.Subcommand != "push" {
+		return Push{}, false
+	}
+
+	var p Push
+	var positional []string
+	args := g.SubArgs
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+		case pushOptsWithValue[a]:
+			if a == "--repo" && i+1 < len(args) {
+				p.Remote = args[i+1]
+			}
+			i++
+		case strings.HasPrefix(a, "--repo="):
+			p.Remote = strings.TrimPrefix(a, "--repo=")
+		case strings.HasPrefix(a, "-") && a != "-":
+		default:
+			positional = append(positional, a)
+		}
+	}
+
+	p.Force = g.HasFlag("-f", "--force", "--force-with-lease", "--force-if-includes")
+	p.Delete = g.HasFlag("-d", "--delete")
+	p.All = g.HasFlag("--all", "--branches")
+	p.Mirror = g.HasFlag("--mirror")
+	p.Tags = g.HasFlag("--tags")
+
+	if len(positional) > 0 && p.Remote == "" {
+		p.Remote, positional = positional[0], positional[1:]
+	}
+	for _, spec := range positional {
+		r := Refspec{}
+		if strings.HasPrefix(spec, "+") {
+			r.Force = true
+			p.Force = true
+			spec = spec[1:]
+		}
+		if src, dst, ok := strings.Cut(spec, ":"); ok {
+			r.Src, r.Dst = src, dst
+		} else {
+			r.Src, r.Dst = spec, spec
+		}
+		if p.Delete {
+			r.Src = ""
+		}
+		p.Refspecs = append(p.Refspecs, r)
+	}
+	return p, true
+}
+
+// Branches returns the remote branches a push updates or deletes. current
+// is the checked-out branch, used for "HEAD" and for a push without
+// refspecs. A push of every branch (--all, --mirror) returns "*".
+func (p Push) Branches(current string) []string {
+	if p.All || p.Mirror {
+		return []string{"*"}
+	}
+	if len(p.Refspecs) == 0 {
+		if p.Tags || current == "" {
+			return nil
+		}
+		return []string{current}
+	}
+
+	var branches []string
+	for _, r := range p.Refspecs {
+		dst := r.Dst
+		if dst == "HEAD" || (dst == "" && r.Src == "HEAD") {
+			dst = current
+		}
+		if strings.HasPrefix(dst, "refs/") && !strings.HasPrefix(dst, "refs/heads/") {
+			continue // Tags and other refs
+		}
+		if dst = strings.TrimPrefix(dst, "refs/heads/"); dst != "" {
+			branches = append(branches, dst)
+		}
+	}
+	return branches
+}
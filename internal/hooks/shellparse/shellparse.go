@@ -0,0 +1,351 @@
+// Package shellparse extracts the commands a shell script would run, for
+// hooks that inspect Bash tool calls. It uses a real POSIX shell parser, so
+// commands inside pipelines, ||, subshells, command substitutions,
+// "bash -c" scripts and wrappers such as env or sudo are all found.
+package shellparse
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Command is one simple command in a script.
+type Command struct {
+	Name string   // Program name as written, e.g. "git" or "/usr/bin/rm"
+	Args []string // Arguments after the name, with quotes removed
+	// Env holds variable assignments before the command, from the shell
+	// ("X=1 cmd") or from env ("env X=1 cmd").
+	Env map[string]string
+	// Dynamic is true if any word contains an expansion that can't be
+	// resolved statically, such as $VAR or $(cmd). Such words keep their
+	// source text.
+	Dynamic bool
+	// Redirects are the command's file redirections, e.g. "> out.txt".
+	Redirects []Redirect
+	// Pipeline identifies the pipeline the command is part of, numbered
+	// from 1 in source order; 0 if it isn't piped. Stage is its position in
+	// that pipeline, from 0.
+	Pipeline int
+	Stage    int
+	// Wrappers lists the wrapper commands this command was unwrapped from,
+	// outermost first, e.g. ["sudo", "env"].
+	Wrappers []string
+}
+
+// Redirect is a file redirection.
+type Redirect struct {
+	Op     string // ">", ">>", "<", "&>", ...
+	Target string // File name, or the source text if it is dynamic
+}
+
+// Base returns the program name without its directory.
+func (c Command) Base() string {
+	return filepath.Base(c.Name)
+}
+
+// IsDynamic reports whether a word of the command may contain an
+// expansion, whose value is only known when the command runs.
+func (c Command) IsDynamic(word string) bool {
+	return c.Dynamic && strings.ContainsAny(word, "$`")
+}
+
+// Parse returns every simple command in script, in source order. Commands
+// hidden behind wrappers (env, sudo, command, exec, nohup, time, nice,
+// timeout, xargs) are returned unwrapped, and the scripts of "bash -c",
+// "sh -c" and eval are parsed recursively.
+func Parse(script string) ([]Command, error) {
+	return (&parser{}).parse(script, 0)
+}
+
+// Piped returns the commands in the same pipeline as cmds[i] that come
+// before it, i.e. whose output it reads.
+func Piped(cmds []Command, i int) []Command {
+	var before []Command
+	for _, c := range cmds {
+		if cmds[i].Pipeline != 0 && c.Pipeline == cmds[i].Pipeline && c.Stage < cmds[i].Stage {
+			before = append(before, c)
+		}
+	}
+	return before
+}
+
+// maxDepth bounds recursion into nested "bash -c" scripts.
+const maxDepth = 5
+
+// parser numbers pipelines across nested scripts.
+type parser struct {
+	pipelines int
+}
+
+// pipePos is a statement's place in a pipeline.
+type pipePos struct {
+	pipeline, stage int
+}
+
+func (ps *parser) parse(script string, depth int) ([]Command, error) {
+	file, err := syntax.NewParser().Parse(strings.NewReader(script), "")
+	if err != nil {
+		return nil, fmt.Errorf("parse shell: %w", err)
+	}
+
+	pipes := map[*syntax.Stmt]pipePos{}
+	var cmds []Command
+	var walkErr error
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if walkErr != nil {
+			return false
+		}
+		switch n := node.(type) {
+		case *syntax.BinaryCmd:
+			// The outermost pipe of a chain is visited first
+			if isPipe(n) {
+				if _, nested := pipes[n.X]; !nested {
+					ps.pipelines++
+					for i, st := range pipeStages(n) {
+						pipes[st] = pipePos{ps.pipelines, i}
+					}
+				}
+			}
+		case *syntax.Stmt:
+			call, ok := n.Cmd.(*syntax.CallExpr)
+			if !ok {
+				return true
+			}
+			cmd, ok := command(call, n.Redirs)
+			if !ok {
+				// Only assignments; nested substitutions are still walked
+				return true
+			}
+			if pos, ok := pipes[n]; ok {
+				cmd.Pipeline, cmd.Stage = pos.pipeline, pos.stage
+			}
+			nested, err := ps.expand(cmd, depth)
+			if err != nil {
+				walkErr = err
+				return false
+			}
+			cmds = append(cmds, nested...)
+		}
+		// Keep walking: arguments may contain command substitutions
+		return true
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return cmds, nil
+}
+
+func isPipe(b *syntax.BinaryCmd) bool {
+	return b.Op == syntax.Pipe || b.Op == syntax.PipeAll
+}
+
+// pipeStages flattens a left-nested chain of pipes into its statements.
+func pipeStages(b *syntax.BinaryCmd) []*syntax.Stmt {
+	var stages []*syntax.Stmt
+	if x, ok := b.X.Cmd.(*syntax.BinaryCmd); ok && isPipe(x) && len(b.X.Redirs) == 0 {
+		stages = pipeStages(x)
+	} else {
+		stages = []*syntax.Stmt{b.X}
+	}
+	return append(stages, b.Y)
+}
+
+// command converts a call and its redirections. It returns false for a
+// call with only assignments.
+func command(call *syntax.CallExpr, redirs []*syntax.Redirect) (Command, bool) {
+	cmd := Command{Env: map[string]string{}}
+	for _, a := range call.Assigns {
+		if a.Name == nil {
+			continue
+		}
+		v, ok := wordString(a.Value)
+		cmd.Env[a.Name.Value] = v
+		cmd.Dynamic = cmd.Dynamic || !ok
+	}
+	var words []string
+	for _, w := range call.Args {
+		s, ok := wordString(w)
+		words = append(words, s)
+		cmd.Dynamic = cmd.Dynamic || !ok
+	}
+	if len(words) == 0 {
+		return Command{}, false
+	}
+	cmd.Name, cmd.Args = words[0], words[1:]
+
+	for _, r := range redirs {
+		if r.Op == syntax.Hdoc || r.Op == syntax.DashHdoc {
+			continue
+		}
+		target, ok := wordString(r.Word)
+		cmd.Dynamic = cmd.Dynamic || !ok
+		cmd.Redirects = append(cmd.Redirects, Redirect{Op: r.Op.String(), Target: target})
+	}
+	return cmd, true
+}
+
+// expand unwraps wrapper commands and parses nested scripts. It returns the
+// wrapper itself followed by what it runs.
+func (ps *parser) expand(cmd Command, depth int) ([]Command, error) {
+	cmds := []Command{cmd}
+	if depth >= maxDepth {
+		return cmds, nil
+	}
+
+	switch cmd.Base() {
+	case "bash", "sh", "zsh", "dash", "ksh":
+		for i, a := range cmd.Args {
+			if a == "-c" || (strings.HasPrefix(a, "-") && !strings.HasPrefix(a, "--") && strings.Contains(a, "c")) {
+				if i+1 < len(cmd.Args) {
+					nested, err := ps.parse(cmd.Args[i+1], depth+1)
+					if err != nil {
+						return nil, err
+					}
+					return append(cmds, nested...), nil
+				}
+			}
+			if !strings.HasPrefix(a, "-") {
+				break
+			}
+		}
+	case "eval":
+		nested, err := ps.parse(strings.Join(cmd.Args, " "), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return append(cmds, nested...), nil
+	default:
+		if inner, ok := unwrap(cmd); ok {
+			nested, err := ps.expand(inner, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			return append(cmds, nested...), nil
+		}
+	}
+	return cmds, nil
+}
+
+// wrapperOptsWithValue lists, per wrapper, the options that take a
+// separate value.
+var wrapperOptsWithValue = map[string][]string{
+	"env":     {"-u", "--unset", "-C", "--chdir", "-S", "--split-string"},
+	"sudo":    {"-u", "--user", "-g", "--group", "-C", "--close-from", "-D", "--chdir", "-h", "--host", "-p", "--prompt", "-r", "--role", "-t", "--type", "-T", "--command-timeout", "-U", "--other-user"},
+	"nice":    {"-n", "--adjustment"},
+	"timeout": {"-k", "--kill-after", "-s", "--signal"},
+	"xargs":   {"-a", "--arg-file", "-d", "--delimiter", "-E", "-e", "-I", "-i", "-L", "-l", "-n", "--max-args", "-P", "--max-procs", "-s", "--max-chars"},
+	"command": {},
+	"exec":    {"-a"},
+	"nohup":   {},
+	"time":    {"-f", "--format", "-o", "--output"},
+}
+
+// unwrap returns the command a wrapper runs.
+func unwrap(cmd Command) (Command, bool) {
+	withValue, ok := wrapperOptsWithValue[cmd.Base()]
+	if !ok {
+		return Command{}, false
+	}
+
+	inner := Command{
+		Env:       map[string]string{},
+		Dynamic:   cmd.Dynamic,
+		Redirects: cmd.Redirects,
+		Pipeline:  cmd.Pipeline,
+		Stage:     cmd.Stage,
+		Wrappers:  append(slices.Clone(cmd.Wrappers), cmd.Base()),
+	}
+	for k, v := range cmd.Env {
+		inner.Env[k] = v
+	}
+
+	args := cmd.Args
+	for len(args) > 0 {
+		a := args[0]
+		switch {
+		case a == "--":
+			args = args[1:]
+		case strings.HasPrefix(a, "-") && a != "-":
+			args = args[1:]
+			for _, opt := range withValue {
+				if a == opt && len(args) > 0 {
+					args = args[1:]
+					break
+				}
+			}
+			continue
+		case cmd.Base() == "env" && strings.Contains(a, "="):
+			k, v, _ := strings.Cut(a, "=")
+			inner.Env[k] = v
+			args = args[1:]
+			continue
+		case cmd.Base() == "timeout":
+			// The duration comes before the command
+			args = args[1:]
+		}
+		break
+	}
+	if len(args) == 0 {
+		return Command{}, false
+	}
+	inner.Name, inner.Args = args[0], args[1:]
+	return inner, true
+}
+
+// wordString returns a word with quotes removed. The second result is false
+// if the word contains expansions; those parts keep their source text.
+func wordString(w *syntax.Word) (string, bool) {
+	if w == nil {
+		return "", true
+	}
+	var sb strings.Builder
+	static := partsString(&sb, w.Parts, false)
+	return sb.String(), static
+}
+
+func partsString(sb *strings.Builder, parts []syntax.WordPart, quoted bool) bool {
+	static := true
+	for _, part := range parts {
+		switch p := part.(type) {
+		case *syntax.Lit:
+			sb.WriteString(unescape(p.Value, quoted))
+		case *syntax.SglQuoted:
+			sb.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			static = partsString(sb, p.Parts, true) && static
+		default:
+			var src strings.Builder
+			syntax.NewPrinter().Print(&src, part)
+			sb.WriteString(src.String())
+			static = false
+		}
+	}
+	return static
+}
+
+// unescape removes backslash escapes. Inside double quotes a backslash only
+// escapes $, `, ", \ and newline.
+func unescape(s string, quoted bool) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			next := s[i+1]
+			if !quoted || strings.IndexByte("$`\"\\\n", next) >= 0 {
+				if next != '\n' {
+					sb.WriteByte(next)
+				}
+				i++
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
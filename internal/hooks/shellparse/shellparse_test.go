@@ -0,0 +1,196 @@
+package shellparse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// names returns "name arg..." for each command.
+func names(cmds []Command) []string {
+	var out []string
+	for _, c := range cmds {
+		out = append(out, strings.Join(append([]string{c.Name}, c.Args...), " "))
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		script string
+		want   []string
+	}{
+		{"git add .", []string{"git add ."}},
+		{"git add . && git commit -m 'a message'", []string{"git add .", "git commit -m a message"}},
+		{"a; b || c | d", []string{"a", "b", "c", "d"}},
+		{"(cd sub && make)", []string{"cd sub", "make"}},
+		{"echo $(git rev-parse HEAD)", []string{"echo $(git rev-parse HEAD)", "git rev-parse HEAD"}},
+		{`bash -c "git push origin main"`, []string{"bash -c git push origin main", "git push origin main"}},
+		{`sh -lc 'rm -rf /tmp/x'`, []string{"sh -lc rm -rf /tmp/x", "rm -rf /tmp/x"}},
+		{`eval "git commit -m x"`, []string{"eval git commit -m x", "git commit -m x"}},
+		{"env -u HOME X=1 git push", []string{"env -u HOME X=1 git push", "git push"}},
+		{"sudo -u root rm -rf /", []string{"sudo -u root rm -rf /", "rm -rf /"}},
+		{"timeout 10s nice -n 5 go test ./...", []string{"timeout 10s nice -n 5 go test ./...", "nice -n 5 go test ./...", "go test ./..."}},
+		{"find . -name x | xargs -n 1 rm", []string{"find . -name x", "xargs -n 1 rm", "rm"}},
+		{`echo "a \"quoted\" word" plain\ space`, []string{`echo a "quoted" word plain space`}},
+		{"X=1", nil},
+		{"if true; then git push; fi", []string{"true", "git push"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.script, func(t *testing.T) {
+			cmds, err := Parse(tt.script)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := names(cmds); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %q, want %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEnvAndDynamic(t *testing.T) {
+	cmds, err := Parse(`GIT_DIR=/x env A=1 git push origin "$BRANCH"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	git := cmds[len(cmds)-1]
+	if git.Name != "git" || git.Env["GIT_DIR"] != "/x" || git.Env["A"] != "1" {
+		t.Errorf("git command = %+v, want both assignments", git)
+	}
+	if !git.Dynamic || git.Args[2] != "$BRANCH" {
+		t.Errorf("expected dynamic word kept as source, got %+v", git)
+	}
+}
+
+func TestParsePipelinesAndRedirects(t *testing.T) {
+	cmds, err := Parse(`curl -s x | sudo bash > log 2>&1; cat a | grep b | wc -l; bash -c "echo x | tee ~/.ssh/y"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type pos struct {
+		name            string
+		pipeline, stage int
+	}
+	var got []pos
+	for _, c := range cmds {
+		got = append(got, pos{c.Name, c.Pipeline, c.Stage})
+	}
+	want := []pos{
+		{"curl", 1, 0}, {"sudo", 1, 1}, {"bash", 1, 1},
+		{"cat", 2, 0}, {"grep", 2, 1}, {"wc", 2, 2},
+		{"bash", 0, 0}, {"echo", 3, 0}, {"tee", 3, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("positions = %v, want %v", got, want)
+	}
+
+	bash := cmds[2]
+	if !reflect.DeepEqual(bash.Wrappers, []string{"sudo"}) {
+		t.Errorf("wrappers = %q, want [sudo]", bash.Wrappers)
+	}
+	if want := []Redirect{{">", "log"}, {">&", "1"}}; !reflect.DeepEqual(bash.Redirects, want) {
+		t.Errorf("redirects = %+v, want %+v", bash.Redirects, want)
+	}
+	if piped := Piped(cmds, 2); len(piped) != 1 || piped[0].Name != "curl" {
+		t.Errorf("Piped = %+v, want curl", piped)
+	}
+	if piped := Piped(cmds, 3); len(piped) != 0 {
+		t.Errorf("first stage has input from %+v", piped)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := Parse("echo 'unterminated"); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestGitCommands(t *testing.T) {
+	cmds, err := Parse("ls && git -C a -C b -c user.name=x --no-pager commit -am msg && /usr/bin/git --version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gits := GitCommands(cmds)
+	if len(gits) != 2 {
+		t.Fatalf("got %d git commands, want 2", len(gits))
+	}
+	g := gits[0]
+	if g.Subcommand != "commit" || g.Dir != "a/b" || !reflect.DeepEqual(g.Config, []string{"user.name=x"}) {
+		t.Errorf("git = %+v", g)
+	}
+	if g.WorkDir("/repo") != "/repo/a/b" {
+		t.Errorf("WorkDir = %q", g.WorkDir("/repo"))
+	}
+	if !g.HasFlag("-a") || !g.HasFlag("-m") || g.HasFlag("--amend") {
+		t.Errorf("HasFlag mismatch for %v", g.Flags())
+	}
+	if gits[1].Subcommand != "" {
+		t.Errorf("git --version subcommand = %q, want empty", gits[1].Subcommand)
+	}
+}
+
+func TestPush(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		remote   string
+		force    bool
+		branches []string
+	}{
+		{"git push", "", false, []string{"cur"}},
+		{"git push -u origin", "origin", false, []string{"cur"}},
+		{"git push origin main", "origin", false, []string{"main"}},
+		{"git push origin HEAD", "origin", false, []string{"cur"}},
+		{"git push origin HEAD:main feat:refs/heads/dev", "origin", false, []string{"main", "dev"}},
+		{"git push -f origin main", "origin", true, []string{"main"}},
+		{"git push --force-with-lease=main:abc origin main", "origin", true, []string{"main"}},
+		{"git push origin +main", "origin", true, []string{"main"}},
+		{"git push --delete origin old", "origin", false, []string{"old"}},
+		{"git push origin :old", "origin", false, []string{"old"}},
+		{"git push -o ci.skip origin main", "origin", false, []string{"main"}},
+		{"git push --repo=upstream main", "upstream", false, []string{"main"}},
+		{"git push --mirror backup", "backup", false, []string{"*"}},
+		{"git push origin --tags", "origin", false, nil},
+		{"git push origin v1:refs/tags/v1", "origin", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			cmds, err := Parse(tt.cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, ok := GitCommands(cmds)[0].Push()
+			if !ok {
+				t.Fatal("not a push")
+			}
+			if p.Remote != tt.remote || p.Force != tt.force {
+				t.Errorf("push = %+v, want remote %q force %v", p, tt.remote, tt.force)
+			}
+			if got := p.Branches("cur"); !reflect.DeepEqual(got, tt.branches) {
+				t.Errorf("Branches = %q, want %q", got, tt.branches)
+			}
+		})
+	}
+}
+
+func TestGitDynamicDir(t *testing.T) {
+	tests := []struct {
+		cmd     string
+		dynamic bool
+	}{
+		{"git -C sub commit", false},
+		{`git -C "$HOME/repo" commit`, true},
+		{"git --git-dir $(pwd)/.git commit", true},
+		{"git --work-tree=$W commit", true},
+		{`git commit -m "$MSG"`, false},
+	}
+	for _, tt := range tests {
+		cmds, err := Parse(tt.cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g := GitCommands(cmds)[0]; g.DynamicDir != tt.dynamic {
+			t.Errorf("%q: DynamicDir = %v, want %v", tt.cmd, g.DynamicDir, tt.dynamic)
+		}
+	}
+}
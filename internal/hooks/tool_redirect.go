@@ -1,43 +1,264 @@
 package hooks
 
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+)
+
 func init() {
 	Register("tool-redirect", toolRedirectHook)
+	RegisterDryRun("tool-redirect", func(input *Input) (*Decision, error) {
+		return toolRedirectDecision(input, loadConfig(input).ToolRedirect)
+	})
 }
 
-// toolRedirectHook runs on PreToolUse to block certain built-in tools and
-// redirect Claude to preferred alternatives.
+// builtinRedirectRules block built-in tools that have preferred alternatives.
+// They apply after the configured rules, so a project can override them.
+var builtinRedirectRules = []config.RedirectRule{
+	{
+		Name:   "mcp-web-search",
+		Tool:   "WebSearch",
+		Action: "deny",
+		Reason: "Use MCP web-search tools instead of built-in WebSearch. Try: mcp-cli web-search/search",
+	},
+	{
+		Name:   "mcp-web-fetch",
+		Tool:   "WebFetch",
+		Action: "deny",
+		Reason: "Use MCP web-fetch tools instead of built-in WebFetch. Try: mcp-cli web-fetch/fetch_url",
+	},
+	{
+		Name:   "no-plan-mode",
+		Tool:   "EnterPlanMode|ExitPlanMode",
+		Action: "deny",
+		Reason: "Built-in plan mode is disabled. Do not call EnterPlanMode or ExitPlanMode. Continue working directly without these tools.",
+	},
+}
+
+// toolRedirectHook runs on PreToolUse and applies the tool-redirect rules:
+// the first rule matching the tool call denies, allows, asks about or
+// rewrites it. Calls that match no rule proceed as usual.
 func toolRedirectHook(input *Input) error {
-	switch input.ToolName {
-	case "WebSearch":
-		WriteOutput(&Output{
-			HookSpecific: &HookSpecificOuput{
-				HookEventName:            "PreToolUse",
-				PermissionDecision:       "deny",
-				PermissionDecisionReason: "Use MCP web-search tools instead of built-in WebSearch. Try: mcp-cli web-search/search",
-			},
-		})
-
-	case "WebFetch":
-		WriteOutput(&Output{
-			HookSpecific: &HookSpecificOuput{
-				HookEventName:            "PreToolUse",
-				PermissionDecision:       "deny",
-				PermissionDecisionReason: "Use MCP web-fetch tools instead of built-in WebFetch. Try: mcp-cli web-fetch/fetch_url",
-			},
-		})
-
-	case "EnterPlanMode", "ExitPlanMode":
-		WriteOutput(&Output{
-			HookSpecific: &HookSpecificOuput{
-				HookEventName:            "PreToolUse",
-				PermissionDecision:       "deny",
-				PermissionDecisionReason: "Built-in plan mode is disabled. Do not call EnterPlanMode or ExitPlanMode. Continue working directly without these tools.",
-			},
-		})
-
-	default:
+	d, err := toolRedirectDecision(input, loadConfig(input).ToolRedirect)
+	if err != nil {
+		return err
+	}
+	if d == nil {
 		ExitOK()
+		return nil
+	}
+	WriteOutput(d.output())
+	return nil
+}
+
+// toolRedirectDecision returns the decision of the first matching rule, or
+// nil if none matches. Allow and rewrite rules from the project config ask
+// instead, so a repository can't grant itself permissions or quietly change
+// what a tool call does.
+func toolRedirectDecision(input *Input, cfg config.ToolRedirect) (*Decision, error) {
+	rules := cfg.Rules
+	if cfg.Builtin {
+		rules = append(rules[:len(rules):len(rules)], builtinRedirectRules...)
+	}
+
+	var toolInput any
+	if len(input.ToolInput) > 0 {
+		if err := json.Unmarshal(input.ToolInput, &toolInput); err != nil {
+			return nil, fmt.Errorf("parse tool input: %w", err)
+		}
+	}
+
+	for _, rule := range rules {
+		ok, err := ruleMatches(rule, input.ToolName, toolInput)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		d := &Decision{Rule: rule.Name, Action: rule.Action, Reason: rule.Reason}
+		if rule.Action == "rewrite" {
+			updated, err := applyRewrites(toolInput, rule.Rewrite)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+			}
+			d.UpdatedInput = updated
+		}
+		if rule.Project {
+			switch rule.Action {
+			case "allow":
+				d.Action = "ask"
+				d.Reason = strings.TrimSpace(rule.Reason + " (Allow rules from the project config ask first; only the global config can allow.)")
+			case "rewrite":
+				// Show the rewritten call in the prompt
+				d.Action = "ask"
+				d.Reason = strings.TrimSpace(rule.Reason + " (Rewrites from the project config ask first; only the global config can rewrite silently.)")
+			}
+		}
+		return d, nil
+	}
+	return nil, nil
+}
+
+// ruleMatches reports whether a rule applies to a tool call.
+func ruleMatches(rule config.RedirectRule, toolName string, toolInput any) (bool, error) {
+	if rule.Tool != "" {
+		re, err := regexp.Compile("^(?:" + rule.Tool + ")$")
+		if err != nil {
+			return false, err
+		}
+		if !re.MatchString(toolName) {
+			return false, nil
+		}
+	}
+
+	for _, p := range rule.When {
+		v, found := lookupPath(toolInput, p.Path)
+		if p.Exists != nil && *p.Exists != found {
+			return false, nil
+		}
+		if p.Equals == nil && p.Contains == "" && p.Matches == "" {
+			continue
+		}
+		if !found {
+			return false, nil
+		}
+
+		s := pathString(v)
+		if p.Equals != nil && s != *p.Equals {
+			return false, nil
+		}
+		if p.Contains != "" && !strings.Contains(s, p.Contains) {
+			return false, nil
+		}
+		if p.Matches != "" {
+			re, err := regexp.Compile(p.Matches)
+			if err != nil {
+				return false, err
+			}
+			if !re.MatchString(s) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// applyRewrites applies the rewrites to a copy of the tool input and returns
+// the result as JSON.
+func applyRewrites(toolInput any, rewrites []config.RedirectRewrite) (map[string]any, error) {
+	// Round-trip through JSON for a deep copy
+	data, err := json.Marshal(toolInput)
+	if err != nil {
+		return nil, err
+	}
+	var updated map[string]any
+	if err := json.Unmarshal(data, &updated); err != nil || updated == nil {
+		return nil, fmt.Errorf("tool input is not an object")
 	}
 
+	for _, rw := range rewrites {
+		old, _ := lookupPath(updated, rw.Path)
+		value := rw.Replace
+		if rw.Pattern != "" {
+			re, err := regexp.Compile(rw.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			value = re.ReplaceAllString(pathString(old), rw.Replace)
+		}
+		if err := setPath(updated, rw.Path, value); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// pathSegments splits a JSON path like "$.edits[0].old_string" into keys
+// and array indices.
+func pathSegments(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.ReplaceAll(path, "[", ".[")
+	var segs []string
+	for _, s := range strings.Split(path, ".") {
+		if s != "" {
+			segs = append(segs, s)
+		}
+	}
+	return segs
+}
+
+// lookupPath resolves a JSON path in decoded JSON.
+func lookupPath(v any, path string) (any, bool) {
+	for _, seg := range pathSegments(path) {
+		switch node := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = node[seg]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(strings.Trim(seg, "[]"))
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// setPath sets a JSON path in decoded JSON. Missing object keys are created;
+// array indices must exist.
+func setPath(root map[string]any, path, value string) error {
+	segs := pathSegments(path)
+	if len(segs) == 0 {
+		return fmt.Errorf("empty path")
+	}
+	var v any = root
+	for i, seg := range segs {
+		last := i == len(segs)-1
+		switch node := v.(type) {
+		case map[string]any:
+			if last {
+				node[seg] = value
+				return nil
+			}
+			if _, ok := node[seg]; !ok {
+				node[seg] = map[string]any{}
+			}
+			v = node[seg]
+		case []any:
+			idx, err := strconv.Atoi(strings.Trim(seg, "[]"))
+			if err != nil || idx < 0 || idx >= len(node) {
+				return fmt.Errorf("path %q: no element %s", path, seg)
+			}
+			if last {
+				node[idx] = value
+				return nil
+			}
+			v = node[idx]
+		default:
+			return fmt.Errorf("path %q: %s is not an object or array", path, strings.Join(segs[:i], "."))
+		}
+	}
 	return nil
 }
+
+// pathString formats a JSON value for comparison: strings as-is, everything
+// else as JSON.
+func pathString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -157,7 +157,7 @@ func hooksConfig(binPath string) map[string]any {
 	return map[string]any{
 		"PreToolUse": []map[string]any{
 			{
-				"matcher": "Bash|WebSearch|WebFetch|Grep|Task|EnterPlanMode|ExitPlanMode",
+				"matcher": "*",
 				"hooks": []map[string]any{
 					{
 						"type":    "command",
@@ -174,6 +174,21 @@ func hooksConfig(binPath string) map[string]any {
 						"command": binPath + " hook branch-guard",
 						"timeout": 15,
 					},
+					{
+						"type":    "command",
+						"command": binPath + " hook command-guard",
+						"timeout": 15,
+					},
+				},
+			},
+			{
+				"matcher": "Write|Edit|MultiEdit|Bash",
+				"hooks": []map[string]any{
+					{
+						"type":    "command",
+						"command": binPath + " hook secret-guard",
+						"timeout": 15,
+					},
 				},
 			},
 		},
@@ -232,6 +247,17 @@ func hooksConfig(binPath string) map[string]any {
 					},
 				},
 			},
+			{
+				"matcher": "Write|Edit|MultiEdit|Bash",
+				"hooks": []map[string]any{
+					{
+						"type":    "command",
+						"command": binPath + " hook observation-capture",
+						"async":   true,
+						"timeout": 15,
+					},
+				},
+			},
 			{
 				"matcher": "TaskCreate|TaskUpdate|TodoWrite",
 				"hooks": []map[string]any{
@@ -254,6 +280,28 @@ func hooksConfig(binPath string) map[string]any {
 					},
 				},
 			},
+			{
+				"hooks": []map[string]any{
+					{
+						"type":    "command",
+						"command": binPath + " hook notify",
+						"async":   true,
+						"timeout": 15,
+					},
+				},
+			},
+		},
+		"Notification": []map[string]any{
+			{
+				"hooks": []map[string]any{
+					{
+						"type":    "command",
+						"command": binPath + " hook notify",
+						"async":   true,
+						"timeout": 15,
+					},
+				},
+			},
 		},
 		"SessionStart": []map[string]any{
 			{
@@ -0,0 +1,163 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+)
+
+// TFIDFModel is the model name recorded for embeddings produced by the
+// built-in TF-IDF embedder.
+const TFIDFModel = "tfidf"
+
+// Embedder converts text into vectors for semantic search. Returned vectors
+// must be L2-normalized so that their dot product is the cosine similarity.
+type Embedder interface {
+	// Model identifies the provider and model. It is stored with every
+	// embedding; changing it causes the index to be rebuilt.
+	Model() string
+	// Embed returns one vector per input text, in order.
+	Embed(ctx context.Context, texts []string) ([]Vector, error)
+}
+
+// NewEmbedder creates the embedder selected by cfg.
+func NewEmbedder(cfg config.EmbeddingConfig) (Embedder, error) {
+	switch cfg.Provider {
+	case "", TFIDFModel:
+		return NewTFIDFEmbedder(), nil
+	case "openai":
+		if cfg.URL == "" {
+			cfg.URL = "https://api.openai.com"
+		}
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("embedding provider %q requires a model", cfg.Provider)
+		}
+		return NewOpenAIEmbedder(cfg.URL, cfg.Model, cfg.APIKey), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", cfg.Provider)
+	}
+}
+
+// TFIDFEmbedder embeds text as sparse TF-IDF vectors over a corpus
+// vocabulary. It is the default embedder. Its weights depend on the indexed
+// corpus, so VectorStore keeps the vocabulary in sync with observations.
+type TFIDFEmbedder struct {
+	vocab *Vocabulary
+}
+
+// NewTFIDFEmbedder creates a TF-IDF embedder with an empty vocabulary.
+func NewTFIDFEmbedder() *TFIDFEmbedder {
+	return &TFIDFEmbedder{vocab: newEmptyVocabulary()}
+}
+
+// Model returns TFIDFModel.
+func (e *TFIDFEmbedder) Model() string {
+	return TFIDFModel
+}
+
+// Embed returns sparse TF-IDF vectors for the given texts.
+func (e *TFIDFEmbedder) Embed(_ context.Context, texts []string) ([]Vector, error) {
+	vecs := make([]Vector, len(texts))
+	for i, text := range texts {
+		vecs[i] = e.vocab.EmbedSparse(text)
+	}
+	return vecs, nil
+}
+
+// OpenAIEmbedder calls an OpenAI-compatible /v1/embeddings endpoint, such as
+// the OpenAI API or a local Ollama or llama.cpp server.
+type OpenAIEmbedder struct {
+	baseURL string
+	model   string
+	apiKey  string
+	http    *http.Client
+}
+
+// NewOpenAIEmbedder creates an embedder for the API at baseURL. The base URL
+// may include or omit the trailing /v1.
+func NewOpenAIEmbedder(baseURL, model, apiKey string) *OpenAIEmbedder {
+	baseURL = strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1")
+	return &OpenAIEmbedder{
+		baseURL: baseURL,
+		model:   model,
+		apiKey:  apiKey,
+		http: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// Model returns "openai:" followed by the model name.
+func (e *OpenAIEmbedder) Model() string {
+	return "openai:" + e.model
+}
+
+// Embed requests embeddings for the given texts in a single call.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([]Vector, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"model": e.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/v1/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request embeddings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embeddings endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode embeddings: %w", err)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings endpoint returned %d vectors for %d inputs", len(result.Data), len(texts))
+	}
+
+	vecs := make([]Vector, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		for _, v := range d.Embedding {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("embedding %d contains invalid values", d.Index)
+			}
+		}
+		vecs[d.Index] = newDenseVector(d.Embedding)
+	}
+	return vecs, nil
+}
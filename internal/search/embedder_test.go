@@ -0,0 +1,423 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
+	"github.com/itk-dev/itkdev-claude-code/internal/db"
+)
+
+// keywordEmbedder is a dense test embedder with one dimension per keyword.
+type keywordEmbedder struct {
+	model    string
+	keywords []string
+	calls    int
+}
+
+func (e *keywordEmbedder) Model() string { return e.model }
+
+func (e *keywordEmbedder) Embed(_ context.Context, texts []string) ([]Vector, error) {
+	e.calls++
+	vecs := make([]Vector, len(texts))
+	for i, text := range texts {
+		values := make([]float64, len(e.keywords))
+		for j, kw := range e.keywords {
+			if strings.Contains(strings.ToLower(text), kw) {
+				values[j] = 1
+			}
+		}
+		vecs[i] = newDenseVector(values)
+	}
+	return vecs, nil
+}
+
+// waitRebuilt waits for the background rebuild of a store opened with a new
+// model.
+func waitRebuilt(t *testing.T, store *VectorStore) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for store.rebuilding.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("index rebuild didn't finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitQueue waits until the store's background queue is empty.
+func waitQueue(t *testing.T, store *VectorStore) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		store.queueMu.Lock()
+		draining := store.draining
+		store.queueMu.Unlock()
+		if !draining {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background indexing didn't finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNewEmbedder(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.EmbeddingConfig
+		wantModel string
+		wantErr   bool
+	}{
+		{"default", config.EmbeddingConfig{}, TFIDFModel, false},
+		{"tfidf", config.EmbeddingConfig{Provider: "tfidf"}, TFIDFModel, false},
+		{"openai", config.EmbeddingConfig{Provider: "openai", URL: "http://localhost:11434", Model: "nomic-embed-text"}, "openai:nomic-embed-text", false},
+		{"openai without model", config.EmbeddingConfig{Provider: "openai"}, "", true},
+		{"unknown", config.EmbeddingConfig{Provider: "word2vec"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEmbedder(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewEmbedder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && e.Model() != tt.wantModel {
+				t.Errorf("Model() = %q, want %q", e.Model(), tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	var received struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("path = %s, want /v1/embeddings", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		// Return out of order to check results are placed by index
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,2]},{"index":0,"embedding":[3,4]}]}`))
+	}))
+	defer server.Close()
+
+	e := NewOpenAIEmbedder(server.URL+"/v1/", "nomic-embed-text", "secret")
+	vecs, err := e.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+
+	if received.Model != "nomic-embed-text" || len(received.Input) != 2 {
+		t.Errorf("unexpected request: %+v", received)
+	}
+	if len(vecs) != 2 {
+		t.Fatalf("got %d vectors, want 2", len(vecs))
+	}
+	if math.Abs(float64(vecs[0].Values[0])-0.6) > 1e-6 || math.Abs(float64(vecs[0].Values[1])-0.8) > 1e-6 {
+		t.Errorf("vecs[0] = %v, want normalized [0.6 0.8]", vecs[0].Values)
+	}
+	if vecs[1].Values[1] != 1 {
+		t.Errorf("vecs[1] = %v, want [0 1]", vecs[1].Values)
+	}
+	if vecs[0].Dimension() != 2 {
+		t.Errorf("Dimension() = %d, want 2", vecs[0].Dimension())
+	}
+}
+
+func TestOpenAIEmbedderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := NewOpenAIEmbedder(server.URL, "missing", "").Embed(context.Background(), []string{"x"})
+	if err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("expected error with response body, got %v", err)
+	}
+}
+
+func TestVectorStoreWithEmbedder(t *testing.T) {
+	database := testDB(t)
+	for _, text := range []string{"login failure on auth", "database migration", "css layout"} {
+		if _, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "t", Text: text}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	emb := &keywordEmbedder{model: "test:v1", keywords: []string{"auth", "login", "database", "css"}}
+	store, err := NewVectorStoreWithEmbedder(database, emb)
+	if err != nil {
+		t.Fatalf("NewVectorStoreWithEmbedder: %v", err)
+	}
+	waitRebuilt(t, store)
+
+	results, err := store.Search(context.Background(), "auth", 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || !strings.Contains(results[0].Text, "login") {
+		t.Errorf("expected the auth observation, got %+v", results)
+	}
+
+	var model string
+	var dimension int
+	database.Conn().QueryRow(`SELECT model, dimension FROM observation_embeddings LIMIT 1`).Scan(&model, &dimension)
+	if model != "test:v1" || dimension != 4 {
+		t.Errorf("stored model=%q dimension=%d, want test:v1 and 4", model, dimension)
+	}
+}
+
+func TestVectorStoreReindexesOnModelChange(t *testing.T) {
+	database := testDB(t)
+	if _, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "t", Text: "auth"}); err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+
+	if _, err := NewVectorStore(database); err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+
+	// Same model: the index is reused
+	if _, err := NewVectorStoreWithEmbedder(database, NewTFIDFEmbedder()); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+
+	// New model: everything is re-embedded
+	emb := &keywordEmbedder{model: "test:v2", keywords: []string{"auth"}}
+	store, err := NewVectorStoreWithEmbedder(database, emb)
+	if err != nil {
+		t.Fatalf("NewVectorStoreWithEmbedder: %v", err)
+	}
+	waitRebuilt(t, store)
+	if emb.calls == 0 {
+		t.Error("expected observations to be re-embedded after a model change")
+	}
+	if got, _ := store.meta("model"); got != "test:v2" {
+		t.Errorf("stored model = %q, want test:v2", got)
+	}
+
+	// Switching back rebuilds the TF-IDF index
+	store, err = NewVectorStore(database)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	results, err := store.Search(context.Background(), "auth", 5)
+	if err != nil || len(results) != 1 {
+		t.Errorf("TF-IDF search after switching back = %+v, %v", results, err)
+	}
+}
+
+// gatedEmbedder is a keywordEmbedder that blocks until released, like a
+// slow endpoint, and fails while failing is set, like an unreachable one.
+type gatedEmbedder struct {
+	keywordEmbedder
+	release chan struct{}
+	failing atomic.Bool
+}
+
+func (e *gatedEmbedder) Embed(ctx context.Context, texts []string) ([]Vector, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	select {
+	case <-e.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if e.failing.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return e.keywordEmbedder.Embed(ctx, texts)
+}
+
+func TestVectorStoreRebuildsInBackground(t *testing.T) {
+	database := testDB(t)
+	seedObservations(t, database)
+	if _, err := NewVectorStore(database); err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+
+	emb := &gatedEmbedder{
+		keywordEmbedder: keywordEmbedder{model: "test:v2", keywords: []string{"auth", "css"}},
+		release:         make(chan struct{}),
+	}
+	opened := make(chan *VectorStore)
+	go func() {
+		store, err := NewVectorStoreWithEmbedder(database, emb)
+		if err != nil {
+			t.Errorf("NewVectorStoreWithEmbedder: %v", err)
+		}
+		opened <- store
+	}()
+	var store *VectorStore
+	select {
+	case store = <-opened:
+	case <-time.After(5 * time.Second):
+		t.Fatal("NewVectorStoreWithEmbedder waited for the embedder")
+	}
+
+	// Until the rebuild is done, hybrid search serves FTS5 results
+	orch := &Orchestrator{db: database, vector: store, ranking: DefaultRanking(), now: time.Now}
+	if _, err := store.Search(context.Background(), "auth", 5); !errors.Is(err, ErrIndexing) {
+		t.Errorf("Search during rebuild error = %v, want ErrIndexing", err)
+	}
+	results, err := orch.Search(context.Background(), SearchQuery{Text: "authentication", Limit: 5, Explain: true})
+	if err != nil || len(results) == 0 {
+		t.Fatalf("hybrid Search during rebuild = %+v, %v", results, err)
+	}
+	if e := results[0].Explanation; e.FTSRank != 1 || e.VectorRank != 0 {
+		t.Errorf("hybrid result during rebuild = %+v, want FTS5 only", e)
+	}
+	if _, err := orch.Search(context.Background(), SearchQuery{Text: "authentication", Mode: ModeSemantic}); !errors.Is(err, ErrIndexing) {
+		t.Errorf("semantic Search during rebuild error = %v, want ErrIndexing", err)
+	}
+
+	close(emb.release)
+	waitRebuilt(t, store)
+	results, err = orch.Search(context.Background(), SearchQuery{Text: "auth", Limit: 5, Mode: ModeSemantic})
+	if err != nil || len(results) != 2 {
+		t.Errorf("semantic Search after rebuild = %+v, %v", results, err)
+	}
+
+	// The query is embedded with the caller's context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.Search(ctx, "auth", 5); !errors.Is(err, context.Canceled) {
+		t.Errorf("Search with cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestVectorStoreRetriesFailedRebuild(t *testing.T) {
+	database := testDB(t)
+	seedObservations(t, database)
+
+	emb := &gatedEmbedder{
+		keywordEmbedder: keywordEmbedder{model: "test:v1", keywords: []string{"auth", "css"}},
+		release:         make(chan struct{}),
+	}
+	close(emb.release)
+	emb.failing.Store(true)
+	store, err := NewVectorStoreWithEmbedder(database, emb)
+	if err != nil {
+		t.Fatalf("NewVectorStoreWithEmbedder: %v", err)
+	}
+	waitRebuilt(t, store)
+	if stale, err := store.Stale(); err != nil || !stale {
+		t.Fatalf("Stale() after failed rebuild = %v, %v, want true", stale, err)
+	}
+
+	emb.failing.Store(false)
+	if err := store.Reweight(); err != nil {
+		t.Fatalf("Reweight: %v", err)
+	}
+	if stale, _ := store.Stale(); stale {
+		t.Error("Stale() after retried rebuild = true")
+	}
+	results, err := store.Search(context.Background(), "auth", 5)
+	if err != nil || len(results) != 2 {
+		t.Errorf("Search after retried rebuild = %+v, %v", results, err)
+	}
+}
+
+func TestVectorStoreIndexesInBackground(t *testing.T) {
+	database := testDB(t)
+	seedObservations(t, database)
+	emb := &gatedEmbedder{
+		keywordEmbedder: keywordEmbedder{model: "test:v1", keywords: []string{"auth", "css", "cache"}},
+		release:         make(chan struct{}),
+	}
+	close(emb.release)
+	store, err := NewVectorStoreWithEmbedder(database, emb)
+	if err != nil {
+		t.Fatalf("NewVectorStoreWithEmbedder: %v", err)
+	}
+	waitRebuilt(t, store)
+
+	// Observations written while the server was down, and while it runs,
+	// don't wait for a slow endpoint
+	insert := func(text string) int64 {
+		id, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "t", Text: text})
+		if err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+		return id
+	}
+	insert("cache invalidation")
+	emb.release = make(chan struct{})
+	done := make(chan error)
+	go func() {
+		var err error
+		if store, err = NewVectorStoreWithEmbedder(database, emb); err == nil {
+			err = store.QueueObservation(insert("cache warming"))
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("open and queue: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("indexing waited for the embedder")
+	}
+
+	close(emb.release)
+	waitQueue(t, store)
+	results, err := store.Search(context.Background(), "cache", 5)
+	if err != nil || len(results) != 2 {
+		t.Errorf("Search after background indexing = %+v, %v", results, err)
+	}
+}
+
+func TestVectorStoreRetriesFailedEmbedding(t *testing.T) {
+	database := testDB(t)
+	seedObservations(t, database)
+	emb := &gatedEmbedder{
+		keywordEmbedder: keywordEmbedder{model: "test:v1", keywords: []string{"auth", "css", "cache"}},
+		release:         make(chan struct{}),
+	}
+	close(emb.release)
+	store, err := NewVectorStoreWithEmbedder(database, emb)
+	if err != nil {
+		t.Fatalf("NewVectorStoreWithEmbedder: %v", err)
+	}
+	waitRebuilt(t, store)
+
+	emb.failing.Store(true)
+	id, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "t", Text: "cache invalidation"})
+	if err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+	if err := store.QueueObservation(id); err != nil {
+		t.Fatalf("QueueObservation: %v", err)
+	}
+	waitQueue(t, store)
+	if missing, err := store.missingIDs(); err != nil || len(missing) != 1 || missing[0] != id {
+		t.Errorf("unindexed after failure = %v, %v, want [%d]", missing, err, id)
+	}
+
+	emb.failing.Store(false)
+	if err := store.Reweight(); err != nil {
+		t.Fatalf("Reweight: %v", err)
+	}
+	waitQueue(t, store)
+	results, err := store.Search(context.Background(), "cache", 5)
+	if err != nil || len(results) != 1 || results[0].ID != id {
+		t.Errorf("Search after Reweight = %+v, %v", results, err)
+	}
+}
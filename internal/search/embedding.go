@@ -5,6 +5,7 @@ package search
 import (
 	"encoding/binary"
 	"math"
+	"sort"
 	"strings"
 	"unicode"
 )
@@ -49,87 +50,141 @@ func tokenize(text string) []string {
 	return tokens
 }
 
-// Vocabulary maps tokens to vector indices and stores IDF weights.
+// Vocabulary maps tokens to vector indices and tracks document frequencies.
+// IDF weights are derived from the frequencies on demand, so documents can be
+// added and removed incrementally.
 type Vocabulary struct {
-	index map[string]int // token → vector index
-	idf   []float64      // IDF weight per index
-	size  int
+	index  map[string]int // token → vector index
+	tokens []string       // vector index → token
+	df     []int          // document frequency per index
+	docs   int            // number of documents counted in df
 }
 
 // NewVocabulary builds a vocabulary from a corpus of documents.
-// Each document is a raw text string. Computes IDF weights.
+// Each document is a raw text string.
 func NewVocabulary(docs []string) *Vocabulary {
-	// Count document frequency for each token
-	df := make(map[string]int)
+	v := newEmptyVocabulary()
 	for _, doc := range docs {
-		seen := make(map[string]bool)
-		for _, tok := range tokenize(doc) {
-			if !seen[tok] {
-				df[tok]++
-				seen[tok] = true
-			}
-		}
-	}
-
-	// Build index and compute IDF
-	v := &Vocabulary{
-		index: make(map[string]int, len(df)),
-		idf:   make([]float64, len(df)),
-		size:  len(df),
-	}
-
-	n := float64(len(docs))
-	i := 0
-	for tok, count := range df {
-		v.index[tok] = i
-		// IDF = log(N / df) + 1 (smoothed)
-		v.idf[i] = math.Log(n/float64(count)) + 1.0
-		i++
+		v.addDocument(tokenize(doc))
 	}
-
 	return v
 }
 
+func newEmptyVocabulary() *Vocabulary {
+	return &Vocabulary{index: make(map[string]int)}
+}
+
 // Size returns the dimensionality of the embedding vectors.
 func (v *Vocabulary) Size() int {
-	return v.size
+	return len(v.tokens)
+}
+
+// Docs returns the number of documents counted in the vocabulary.
+func (v *Vocabulary) Docs() int {
+	return v.docs
 }
 
 // IDF returns the IDF weight for a token. Returns 0 for unknown tokens.
 func (v *Vocabulary) IDF(token string) float64 {
 	token = strings.ToLower(token)
 	if idx, ok := v.index[token]; ok {
-		return v.idf[idx]
+		return v.idf(idx)
 	}
 	return 0
 }
 
-// Embed converts text into a TF-IDF vector using this vocabulary.
+// idf computes the smoothed IDF weight, log(N / df) + 1, for an index.
+func (v *Vocabulary) idf(idx int) float64 {
+	if v.df[idx] <= 0 || v.docs <= 0 {
+		return 0
+	}
+	return math.Log(float64(v.docs)/float64(v.df[idx])) + 1.0
+}
+
+// addDocument counts a document's tokens, assigning indices to tokens not
+// seen before. Returns the indices whose frequency changed.
+func (v *Vocabulary) addDocument(tokens []string) []int {
+	v.docs++
+	var touched []int
+	seen := make(map[string]bool)
+	for _, tok := range tokens {
+		if seen[tok] {
+			continue
+		}
+		seen[tok] = true
+		idx, ok := v.index[tok]
+		if !ok {
+			idx = len(v.tokens)
+			v.index[tok] = idx
+			v.tokens = append(v.tokens, tok)
+			v.df = append(v.df, 0)
+		}
+		v.df[idx]++
+		touched = append(touched, idx)
+	}
+	return touched
+}
+
+// removeDocument reverses addDocument for a document whose embedding used the
+// given indices.
+func (v *Vocabulary) removeDocument(indices []int) {
+	if v.docs > 0 {
+		v.docs--
+	}
+	for _, idx := range indices {
+		if idx >= 0 && idx < len(v.df) && v.df[idx] > 0 {
+			v.df[idx]--
+		}
+	}
+}
+
+// Embed converts text into a dense TF-IDF vector using this vocabulary.
 // The vector is L2-normalized.
 func (v *Vocabulary) Embed(text string) []float64 {
-	vec := make([]float64, v.size)
+	vec := make([]float64, v.Size())
+	sparse := v.EmbedSparse(text)
+	for i, idx := range sparse.Indices {
+		vec[idx] = float64(sparse.Values[i])
+	}
+	return vec
+}
+
+// EmbedSparse converts text into a TF-IDF vector holding only the non-zero
+// weights. The vector is L2-normalized; tokens outside the vocabulary are
+// ignored.
+func (v *Vocabulary) EmbedSparse(text string) Vector {
 	tokens := tokenize(text)
 	if len(tokens) == 0 {
-		return vec
+		return Vector{Indices: []int{}}
 	}
 
 	// Count term frequency
-	tf := make(map[string]int)
+	tf := make(map[int]int)
 	for _, tok := range tokens {
-		tf[tok]++
-	}
-
-	// Compute TF-IDF
-	for tok, count := range tf {
 		if idx, ok := v.index[tok]; ok {
-			// TF = count / total tokens (normalized)
-			tfNorm := float64(count) / float64(len(tokens))
-			vec[idx] = tfNorm * v.idf[idx]
+			tf[idx]++
 		}
 	}
 
-	// L2 normalize
-	normalize(vec)
+	// Compute TF-IDF with TF = count / total tokens
+	indices := make([]int, 0, len(tf))
+	for idx := range tf {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	weights := make([]float64, 0, len(indices))
+	vec := Vector{Indices: make([]int, 0, len(indices))}
+	for _, idx := range indices {
+		w := float64(tf[idx]) / float64(len(tokens)) * v.idf(idx)
+		if w == 0 {
+			continue
+		}
+		vec.Indices = append(vec.Indices, idx)
+		weights = append(weights, w)
+	}
+	normalize(weights)
+	vec.Values = toFloat32(weights)
 	return vec
 }
 
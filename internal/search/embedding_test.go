@@ -157,3 +157,34 @@ func TestDecodeVectorEmpty(t *testing.T) {
 		t.Errorf("decoded empty = %v, want empty", decoded)
 	}
 }
+
+func TestEmbedSparseMatchesDense(t *testing.T) {
+	vocab := NewVocabulary([]string{"authentication flow broken", "database migration failed"})
+
+	dense := vocab.Embed("authentication flow failed")
+	sparse := vocab.EmbedSparse("authentication flow failed")
+
+	for i, idx := range sparse.Indices {
+		if math.Abs(dense[idx]-float64(sparse.Values[i])) > 1e-9 {
+			t.Errorf("index %d: sparse %f, dense %f", idx, sparse.Values[i], dense[idx])
+		}
+	}
+	if got := sparse.Dot(sparse); math.Abs(got-1.0) > 0.001 {
+		t.Errorf("sparse self dot = %f, want ~1.0", got)
+	}
+}
+
+func TestEncodeDecodeSparseEmbedding(t *testing.T) {
+	original := Vector{Indices: []int{0, 7, 4096}, Values: []float32{0.5, -0.25, 1e-6}}
+	decoded := DecodeEmbedding(EncodeEmbedding(original))
+
+	if len(decoded.Indices) != len(original.Indices) {
+		t.Fatalf("decoded length = %d, want %d", len(decoded.Indices), len(original.Indices))
+	}
+	for i := range original.Indices {
+		if decoded.Indices[i] != original.Indices[i] || decoded.Values[i] != original.Values[i] {
+			t.Errorf("entry %d = (%d, %f), want (%d, %f)", i,
+				decoded.Indices[i], decoded.Values[i], original.Indices[i], original.Values[i])
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package search
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// HNSW parameters. hnswM bounds the neighbours kept per node on upper layers
+// (twice that on layer 0); the ef values size the candidate lists used while
+// building the graph and while searching it.
+const (
+	hnswM              = 16
+	hnswEfConstruction = 100
+	hnswEfSearch       = 64
+)
+
+// hnswIndex is an in-memory Hierarchical Navigable Small World graph for
+// approximate nearest-neighbour search by dot-product similarity
+// (Malkov & Yashunin, 2016). Works with dense and sparse vectors alike.
+//
+// Replacing or removing a vector only marks its node deleted; deleted nodes
+// still route searches but are never returned. Callers rebuild the index once
+// too many nodes are deleted.
+type hnswIndex struct {
+	mu        sync.RWMutex
+	m         int
+	m0        int
+	efBuild   int
+	levelMult float64
+	rng       *rand.Rand
+
+	nodes    []hnswNode
+	byID     map[int64]int32 // observation ID → live node
+	entry    int32           // entry point, -1 when empty
+	maxLevel int
+	deleted  int
+
+	ready atomic.Bool   // set once the initial build has finished
+	stop  chan struct{} // closed to abandon an in-progress build
+}
+
+type hnswNode struct {
+	id      int64
+	vec     Vector
+	friends [][]int32 // neighbour lists per layer
+	deleted bool
+}
+
+// newHNSWIndex creates an empty index. The seed makes level assignment, and
+// therefore the graph shape, reproducible.
+func newHNSWIndex(seed int64) *hnswIndex {
+	return &hnswIndex{
+		m:         hnswM,
+		m0:        2 * hnswM,
+		efBuild:   hnswEfConstruction,
+		levelMult: 1 / math.Log(hnswM),
+		rng:       rand.New(rand.NewSource(seed)),
+		byID:      make(map[int64]int32),
+		entry:     -1,
+		stop:      make(chan struct{}),
+	}
+}
+
+// hnswEntry is an (observation ID, vector) pair to index.
+type hnswEntry struct {
+	id  int64
+	vec Vector
+}
+
+// build inserts entries that aren't already present, then marks the index
+// ready. Entries added concurrently via Add take precedence over the
+// snapshot. Returns early if Close is called.
+func (h *hnswIndex) build(entries []hnswEntry) {
+	for _, e := range entries {
+		select {
+		case <-h.stop:
+			return
+		default:
+		}
+		h.mu.Lock()
+		if _, ok := h.byID[e.id]; !ok {
+			h.insertLocked(e.id, e.vec)
+		}
+		h.mu.Unlock()
+	}
+	h.ready.Store(true)
+}
+
+// Close abandons an in-progress build.
+func (h *hnswIndex) Close() {
+	select {
+	case <-h.stop:
+	default:
+		close(h.stop)
+	}
+}
+
+// Ready reports whether the initial build has finished.
+func (h *hnswIndex) Ready() bool {
+	return h.ready.Load()
+}
+
+// Add inserts or replaces the vector for an observation.
+func (h *hnswIndex) Add(id int64, vec Vector) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(id)
+	h.insertLocked(id, vec)
+}
+
+// Remove marks an observation's node deleted.
+func (h *hnswIndex) Remove(id int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(id)
+}
+
+func (h *hnswIndex) removeLocked(id int64) {
+	if n, ok := h.byID[id]; ok {
+		h.nodes[n].deleted = true
+		delete(h.byID, id)
+		h.deleted++
+	}
+}
+
+// Len returns the number of live vectors.
+func (h *hnswIndex) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.byID)
+}
+
+// Fragmented reports whether deleted nodes outnumber live ones, at which point
+// the index should be rebuilt.
+func (h *hnswIndex) Fragmented() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.deleted > len(h.byID)
+}
+
+func (h *hnswIndex) randomLevel() int {
+	return int(-math.Log(1-h.rng.Float64()) * h.levelMult)
+}
+
+func (h *hnswIndex) insertLocked(id int64, vec Vector) {
+	level := h.randomLevel()
+	n := int32(len(h.nodes))
+	h.nodes = append(h.nodes, hnswNode{id: id, vec: vec, friends: make([][]int32, level+1)})
+	h.byID[id] = n
+
+	if h.entry < 0 {
+		h.entry = n
+		h.maxLevel = level
+		return
+	}
+
+	// Descend greedily through the layers above the new node's level
+	ep := h.entry
+	for l := h.maxLevel; l > level; l-- {
+		ep = h.greedy(vec, ep, l)
+	}
+
+	// Connect the node on each of its layers
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(vec, ep, h.efBuild, l)
+		neighbours := h.selectNeighbours(candidates, h.m)
+		h.nodes[n].friends[l] = neighbours
+
+		maxFriends := h.m
+		if l == 0 {
+			maxFriends = h.m0
+		}
+		for _, nb := range neighbours {
+			friends := append(h.nodes[nb].friends[l], n)
+			if len(friends) > maxFriends {
+				friends = h.pruneFriends(nb, friends, maxFriends)
+			}
+			h.nodes[nb].friends[l] = friends
+		}
+		ep = candidates[0].node
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entry = n
+	}
+}
+
+// pruneFriends reduces a node's neighbour list to limit entries using the
+// neighbour-selection heuristic.
+func (h *hnswIndex) pruneFriends(node int32, friends []int32, limit int) []int32 {
+	vec := h.nodes[node].vec
+	candidates := make([]hnswCandidate, len(friends))
+	for i, f := range friends {
+		candidates[i] = hnswCandidate{node: f, sim: vec.Dot(h.nodes[f].vec)}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].sim > candidates[j].sim })
+	return h.selectNeighbours(candidates, limit)
+}
+
+// selectNeighbours picks up to limit neighbours from candidates sorted by
+// descending similarity. A candidate is preferred when it is closer to the
+// query than to any neighbour already chosen, which keeps links spread across
+// clusters; remaining slots are filled with the closest skipped candidates.
+func (h *hnswIndex) selectNeighbours(candidates []hnswCandidate, limit int) []int32 {
+	selected := make([]int32, 0, limit)
+	var skipped []int32
+	for _, c := range candidates {
+		if len(selected) >= limit {
+			break
+		}
+		keep := true
+		for _, s := range selected {
+			if h.nodes[c.node].vec.Dot(h.nodes[s].vec) > c.sim {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, c.node)
+		} else {
+			skipped = append(skipped, c.node)
+		}
+	}
+	for _, s := range skipped {
+		if len(selected) >= limit {
+			break
+		}
+		selected = append(selected, s)
+	}
+	return selected
+}
+
+// greedy walks layer l from ep towards the node most similar to q.
+func (h *hnswIndex) greedy(q Vector, ep int32, l int) int32 {
+	best := q.Dot(h.nodes[ep].vec)
+	for changed := true; changed; {
+		changed = false
+		for _, f := range h.nodes[ep].friends[l] {
+			if sim := q.Dot(h.nodes[f].vec); sim > best {
+				best, ep, changed = sim, f, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer returns up to ef nodes on layer l most similar to q, sorted by
+// descending similarity.
+func (h *hnswIndex) searchLayer(q Vector, ep int32, ef int, l int) []hnswCandidate {
+	visited := make([]uint64, (len(h.nodes)+63)/64)
+	visit := func(n int32) bool {
+		word, bit := n/64, uint64(1)<<(n%64)
+		if visited[word]&bit != 0 {
+			return false
+		}
+		visited[word] |= bit
+		return true
+	}
+
+	start := hnswCandidate{node: ep, sim: q.Dot(h.nodes[ep].vec)}
+	visit(ep)
+	candidates := &maxSimHeap{start}
+	results := &minSimHeap{start}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && c.sim < (*results)[0].sim {
+			break
+		}
+		for _, f := range h.nodes[c.node].friends[l] {
+			if !visit(f) {
+				continue
+			}
+			sim := q.Dot(h.nodes[f].vec)
+			if results.Len() < ef || sim > (*results)[0].sim {
+				heap.Push(candidates, hnswCandidate{node: f, sim: sim})
+				heap.Push(results, hnswCandidate{node: f, sim: sim})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	out := []hnswCandidate(*results)
+	sort.Slice(out, func(i, j int) bool { return out[i].sim > out[j].sim })
+	return out
+}
+
+// vectorHit is an observation ID with its similarity to a query.
+type vectorHit struct {
+	ID    int64
+	Score float64
+}
+
+// Search returns up to k live vectors most similar to q, best first.
+func (h *hnswIndex) Search(q Vector, k int) []vectorHit {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.entry < 0 || k <= 0 {
+		return nil
+	}
+
+	ep := h.entry
+	for l := h.maxLevel; l > 0; l-- {
+		ep = h.greedy(q, ep, l)
+	}
+
+	// Widen the beam to make up for deleted nodes that will be dropped
+	ef := max(hnswEfSearch, k) + min(h.deleted, k)
+	var hits []vectorHit
+	for _, c := range h.searchLayer(q, ep, ef, 0) {
+		node := h.nodes[c.node]
+		if node.deleted {
+			continue
+		}
+		hits = append(hits, vectorHit{ID: node.id, Score: c.sim})
+		if len(hits) == k {
+			break
+		}
+	}
+	return hits
+}
+
+type hnswCandidate struct {
+	node int32
+	sim  float64
+}
+
+// maxSimHeap pops the most similar candidate first.
+type maxSimHeap []hnswCandidate
+
+func (h maxSimHeap) Len() int           { return len(h) }
+func (h maxSimHeap) Less(i, j int) bool { return h[i].sim > h[j].sim }
+func (h maxSimHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxSimHeap) Push(x any)        { *h = append(*h, x.(hnswCandidate)) }
+func (h *maxSimHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// minSimHeap pops the least similar candidate first.
+type minSimHeap []hnswCandidate
+
+func (h minSimHeap) Len() int           { return len(h) }
+func (h minSimHeap) Less(i, j int) bool { return h[i].sim < h[j].sim }
+func (h minSimHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minSimHeap) Push(x any)        { *h = append(*h, x.(hnswCandidate)) }
+func (h *minSimHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
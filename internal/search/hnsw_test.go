@@ -0,0 +1,233 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/itk-dev/itkdev-claude-code/internal/db"
+)
+
+// randomVectors returns n normalized dense vectors grouped around a number of
+// cluster centres, which resembles real embedding distributions more closely
+// than uniform noise.
+func randomVectors(rng *rand.Rand, n, dim int) []Vector {
+	centres := make([][]float64, 1+n/50)
+	for i := range centres {
+		centres[i] = make([]float64, dim)
+		for j := range centres[i] {
+			centres[i][j] = rng.NormFloat64()
+		}
+	}
+	vecs := make([]Vector, n)
+	for i := range vecs {
+		c := centres[rng.Intn(len(centres))]
+		values := make([]float64, dim)
+		for j := range values {
+			values[j] = c[j] + 0.5*rng.NormFloat64()
+		}
+		vecs[i] = newDenseVector(values)
+	}
+	return vecs
+}
+
+func buildHNSW(vecs []Vector) *hnswIndex {
+	idx := newHNSWIndex(1)
+	entries := make([]hnswEntry, len(vecs))
+	for i, v := range vecs {
+		entries[i] = hnswEntry{id: int64(i), vec: v}
+	}
+	idx.build(entries)
+	return idx
+}
+
+// exactTopK returns the IDs of the k vectors most similar to q.
+func exactTopK(vecs []Vector, q Vector, k int) []int64 {
+	vs := &VectorStore{vectors: make(map[int64]Vector, len(vecs))}
+	for i, v := range vecs {
+		vs.vectors[int64(i)] = v
+	}
+	var ids []int64
+	for _, h := range vs.linearSearch(q, k) {
+		ids = append(ids, h.ID)
+	}
+	return ids
+}
+
+// recallAt measures the fraction of exact top-k neighbours the index returns,
+// averaged over the queries.
+func recallAt(idx *hnswIndex, vecs, queries []Vector, k int) float64 {
+	var found, total int
+	for _, q := range queries {
+		want := make(map[int64]bool)
+		for _, id := range exactTopK(vecs, q, k) {
+			want[id] = true
+		}
+		for _, h := range idx.Search(q, k) {
+			if want[h.ID] {
+				found++
+			}
+		}
+		total += len(want)
+	}
+	return float64(found) / float64(total)
+}
+
+func TestHNSWRecall(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	vecs := randomVectors(rng, 2000, 32)
+	queries := randomVectors(rng, 50, 32)
+
+	idx := buildHNSW(vecs)
+	if !idx.Ready() {
+		t.Fatal("index should be ready after build")
+	}
+
+	if recall := recallAt(idx, vecs, queries, 10); recall < 0.9 {
+		t.Errorf("recall@10 = %.3f, want >= 0.9", recall)
+	}
+}
+
+func TestHNSWReplaceAndRemove(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	vecs := randomVectors(rng, 200, 16)
+	idx := buildHNSW(vecs)
+
+	// Point observation 0 at observation 1's vector
+	idx.Add(0, vecs[1])
+	hits := idx.Search(vecs[1], 2)
+	if len(hits) != 2 || (hits[0].ID != 0 && hits[0].ID != 1) || (hits[1].ID != 0 && hits[1].ID != 1) {
+		t.Errorf("expected observations 0 and 1 as best matches, got %+v", hits)
+	}
+	if idx.Len() != 200 {
+		t.Errorf("Len() = %d, want 200", idx.Len())
+	}
+
+	idx.Remove(1)
+	for _, h := range idx.Search(vecs[1], 10) {
+		if h.ID == 1 {
+			t.Error("removed observation returned by search")
+		}
+	}
+
+	for i := int64(2); i < 150; i++ {
+		idx.Remove(i)
+	}
+	if !idx.Fragmented() {
+		t.Error("index should report fragmentation once most nodes are deleted")
+	}
+}
+
+func TestVectorStoreUsesANN(t *testing.T) {
+	database := testDB(t)
+	emb := &keywordEmbedder{model: "test:v1", keywords: []string{"alpha", "beta", "gamma", "delta"}}
+	words := []string{"alpha", "beta", "gamma", "delta"}
+	for i := 0; i < 40; i++ {
+		text := words[i%4] + " " + words[(i/4)%4]
+		if _, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "t", Text: text}); err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+	}
+
+	store, err := NewVectorStoreWithEmbedder(database, emb)
+	if err != nil {
+		t.Fatalf("NewVectorStoreWithEmbedder: %v", err)
+	}
+	waitRebuilt(t, store)
+	if store.ann != nil {
+		t.Fatal("small store should not build an ANN index")
+	}
+
+	store.mu.Lock()
+	store.annThreshold = 10
+	store.rebuildANNLocked()
+	ann := store.ann
+	store.mu.Unlock()
+	if ann == nil {
+		t.Fatal("expected ANN index above threshold")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !ann.Ready() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	results, err := store.Search(context.Background(), "gamma", 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Score <= 0 {
+			t.Errorf("result %d has non-positive score %f", r.ID, r.Score)
+		}
+	}
+
+	// New observations go straight into the ANN index
+	id, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "t", Text: "gamma gamma"})
+	if err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+	if err := store.IndexObservation(id); err != nil {
+		t.Fatalf("IndexObservation: %v", err)
+	}
+	if ann.Len() != 41 {
+		t.Errorf("ANN index holds %d vectors, want 41", ann.Len())
+	}
+}
+
+func TestEncodeDecodeDenseEmbedding(t *testing.T) {
+	original := Vector{Values: []float32{1.5, -2.25, 0, 42}}
+	blob := EncodeEmbedding(original)
+	if len(blob) != 1+4*len(original.Values) {
+		t.Errorf("blob length = %d, want %d (float32 storage)", len(blob), 1+4*len(original.Values))
+	}
+	decoded := DecodeEmbedding(blob)
+	if decoded.Sparse() || fmt.Sprint(decoded.Values) != fmt.Sprint(original.Values) {
+		t.Errorf("decoded = %+v, want %+v", decoded, original)
+	}
+}
+
+var benchCorpus struct {
+	vecs    []Vector
+	queries []Vector
+	store   *VectorStore
+	ann     *hnswIndex
+}
+
+// benchSetup builds a 10k-vector corpus once and shares it across benchmarks.
+func benchSetup(b *testing.B) {
+	b.Helper()
+	if benchCorpus.ann != nil {
+		return
+	}
+	rng := rand.New(rand.NewSource(1))
+	benchCorpus.vecs = randomVectors(rng, 10000, 128)
+	benchCorpus.queries = randomVectors(rng, 100, 128)
+	benchCorpus.store = &VectorStore{vectors: make(map[int64]Vector, len(benchCorpus.vecs))}
+	for i, v := range benchCorpus.vecs {
+		benchCorpus.store.vectors[int64(i)] = v
+	}
+	benchCorpus.ann = buildHNSW(benchCorpus.vecs)
+}
+
+func BenchmarkVectorSearchLinear(b *testing.B) {
+	benchSetup(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchCorpus.store.linearSearch(benchCorpus.queries[i%len(benchCorpus.queries)], 10)
+	}
+}
+
+func BenchmarkVectorSearchHNSW(b *testing.B) {
+	benchSetup(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchCorpus.ann.Search(benchCorpus.queries[i%len(benchCorpus.queries)], 10)
+	}
+	b.StopTimer()
+	b.ReportMetric(recallAt(benchCorpus.ann, benchCorpus.vecs, benchCorpus.queries, 10), "recall@10")
+}
@@ -1,8 +1,13 @@
 package search
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"sort"
+	"time"
 
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
 	"github.com/itk-dev/itkdev-claude-code/internal/db"
 )
 
@@ -14,51 +19,170 @@ type Weights struct {
 
 // DefaultWeights returns the default search weights.
 func DefaultWeights() Weights {
-	return Weights{FTS: 0.4, Vector: 0.6}
+	return DefaultRanking().Weights
 }
 
-// SearchQuery describes a hybrid search request.
+// Fusion selects how FTS5 and vector result lists are combined.
+type Fusion string
+
+const (
+	// FusionWeighted scores FTS5 results linearly by rank position and adds
+	// the weighted cosine similarity.
+	FusionWeighted Fusion = "weighted"
+	// FusionRRF uses reciprocal-rank fusion: each list contributes
+	// weight/(k+rank), so only the rank within each list matters.
+	FusionRRF Fusion = "rrf"
+)
+
+// ParseFusion validates a fusion strategy name. An empty name returns "",
+// meaning the orchestrator default.
+func ParseFusion(s string) (Fusion, error) {
+	switch f := Fusion(s); f {
+	case "", FusionWeighted, FusionRRF:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown fusion strategy %q (want %q or %q)", s, FusionWeighted, FusionRRF)
+	}
+}
+
+// Mode selects which retrievers a search uses.
+type Mode string
+
+const (
+	ModeHybrid   Mode = "hybrid"   // FTS5 and vector search, fused
+	ModeFTS      Mode = "fts"      // FTS5 keyword search only
+	ModeSemantic Mode = "semantic" // Vector search only
+)
+
+// ParseMode validates a search mode name. An empty name means ModeHybrid.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case "":
+		return ModeHybrid, nil
+	case ModeHybrid, ModeFTS, ModeSemantic:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown search mode %q (want %q, %q or %q)", s, ModeHybrid, ModeFTS, ModeSemantic)
+	}
+}
+
+// Ranking controls how hybrid results are fused and re-scored.
+type Ranking struct {
+	Fusion  Fusion
+	Weights Weights
+	// RRFK is the rank offset for reciprocal-rank fusion. Larger values
+	// flatten the difference between top and lower ranks.
+	RRFK float64
+	// RecencyHalfLife is the age at which the decaying part of a score is
+	// halved. Zero disables recency decay.
+	RecencyHalfLife time.Duration
+	// RecencyWeight is the share of the score (0-1) subject to decay, so
+	// old observations keep at least 1-RecencyWeight of their score.
+	RecencyWeight float64
+	// TypeBoosts multiplies scores by observation type. Missing types
+	// have a boost of 1.
+	TypeBoosts map[string]float64
+}
+
+// DefaultRanking returns the default ranking settings.
+func DefaultRanking() Ranking {
+	return RankingFromConfig(config.Defaults().Search)
+}
+
+// RankingFromConfig converts the search section of the config. The config
+// is expected to have been validated.
+func RankingFromConfig(c config.SearchConfig) Ranking {
+	return Ranking{
+		Fusion:          Fusion(c.Fusion),
+		Weights:         Weights{FTS: c.Weights.FTS, Vector: c.Weights.Vector},
+		RRFK:            c.RRFK,
+		RecencyHalfLife: c.RecencyHalfLife,
+		RecencyWeight:   c.RecencyWeight,
+		TypeBoosts:      c.TypeBoosts,
+	}
+}
+
+// SearchQuery describes a hybrid search request. Text is natural language;
+// FTS5 operators in it are matched literally.
 type SearchQuery struct {
-	Text    string
-	Type    string
-	Project string
-	Limit   int
+	Text      string
+	Type      string
+	Project   string
+	DateStart string // YYYY-MM-DD, inclusive
+	DateEnd   string // YYYY-MM-DD
+	Limit     int
+	Mode      Mode   // Defaults to ModeHybrid
+	Fusion    Fusion // Overrides the orchestrator's fusion strategy when set
+	Explain   bool   // Attach an Explanation to each result
 }
 
 // HybridResult is a merged search result with a combined score.
 type HybridResult struct {
-	ID        int64   `json:"id"`
-	Score     float64 `json:"score"`
-	Title     string  `json:"title"`
-	Text      string  `json:"text"`
-	ObsType   string  `json:"type"`
-	Project   string  `json:"project"`
-	SessionID string  `json:"session_id"`
+	ID          int64        `json:"id"`
+	Score       float64      `json:"score"`
+	Title       string       `json:"title"`
+	Text        string       `json:"text"`
+	ObsType     string       `json:"type"`
+	Project     string       `json:"project"`
+	SessionID   string       `json:"session_id"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+// Explanation breaks down how a result's score was computed:
+// Score = FusedScore × RecencyFactor × Boost.
+type Explanation struct {
+	Fusion        Fusion  `json:"fusion"`
+	FTSRank       int     `json:"fts_rank,omitempty"`    // 1-based; 0 when not an FTS5 match
+	VectorRank    int     `json:"vector_rank,omitempty"` // 1-based; 0 when not a vector match
+	VectorScore   float64 `json:"vector_score"`          // Cosine similarity
+	FusedScore    float64 `json:"fused_score"`
+	RecencyFactor float64 `json:"recency_factor"`
+	Boost         float64 `json:"boost"`
 }
 
 // Orchestrator coordinates FTS5 and vector search.
 type Orchestrator struct {
 	db      *db.DB
 	vector  *VectorStore
-	weights Weights
+	ranking Ranking
+	now     func() time.Time
 }
 
-// NewOrchestrator creates a hybrid search orchestrator.
+// NewOrchestrator creates a hybrid search orchestrator using the default
+// TF-IDF embedder.
 func NewOrchestrator(database *db.DB) (*Orchestrator, error) {
-	vs, err := NewVectorStore(database)
+	return NewOrchestratorWithEmbedder(database, NewTFIDFEmbedder())
+}
+
+// NewOrchestratorWithEmbedder creates a hybrid search orchestrator whose
+// vector search uses the given embedder.
+func NewOrchestratorWithEmbedder(database *db.DB, embedder Embedder) (*Orchestrator, error) {
+	vs, err := NewVectorStoreWithEmbedder(database, embedder)
 	if err != nil {
 		return nil, err
 	}
 	return &Orchestrator{
 		db:      database,
 		vector:  vs,
-		weights: DefaultWeights(),
+		ranking: DefaultRanking(),
+		now:     time.Now,
 	}, nil
 }
 
 // SetWeights configures the FTS/vector weight blend.
 func (o *Orchestrator) SetWeights(w Weights) {
-	o.weights = w
+	o.ranking.Weights = w
+}
+
+// SetRanking replaces the fusion, recency and boost settings.
+func (o *Orchestrator) SetRanking(r Ranking) {
+	o.ranking = r
+}
+
+// Model returns the model name of the embedder used for vector search.
+func (o *Orchestrator) Model() string {
+	return o.vector.Model()
 }
 
 // RebuildIndex rebuilds the vector search index from all observations.
@@ -66,86 +190,194 @@ func (o *Orchestrator) RebuildIndex() error {
 	return o.vector.IndexAll()
 }
 
+// IndexObservation adds or refreshes a single observation in the vector index.
+func (o *Orchestrator) IndexObservation(id int64) error {
+	return o.vector.IndexObservation(id)
+}
+
+// QueueObservation adds or refreshes a single observation in the vector
+// index. With external embedders it's embedded in the background.
+func (o *Orchestrator) QueueObservation(id int64) error {
+	return o.vector.QueueObservation(id)
+}
+
+// IndexMissing indexes observations that have no embedding yet, such as
+// those added by an import.
+func (o *Orchestrator) IndexMissing() error {
+	return o.vector.IndexMissing()
+}
+
+// RemoveObservation drops a deleted observation from the vector index.
+func (o *Orchestrator) RemoveObservation(id int64) error {
+	return o.vector.RemoveObservation(id)
+}
+
+// StartReweighter starts periodic background re-weighting of the vector
+// index. Returns a stop function.
+func (o *Orchestrator) StartReweighter(interval time.Duration) func() {
+	return o.vector.StartReweighter(interval)
+}
+
 // Search performs a hybrid search combining FTS5 and vector similarity.
-func (o *Orchestrator) Search(q SearchQuery) ([]HybridResult, error) {
+func (o *Orchestrator) Search(ctx context.Context, q SearchQuery) ([]HybridResult, error) {
 	if q.Limit <= 0 {
 		q.Limit = 20
 	}
+	fusion := q.Fusion
+	if fusion == "" {
+		fusion = o.ranking.Fusion
+	}
 
 	// Collect results by observation ID for merging
 	merged := make(map[int64]*HybridResult)
+	explain := make(map[int64]*Explanation)
+	entry := func(id int64) (*HybridResult, *Explanation) {
+		r, ok := merged[id]
+		if !ok {
+			r = &HybridResult{ID: id}
+			merged[id] = r
+			explain[id] = &Explanation{Fusion: fusion}
+		}
+		return r, explain[id]
+	}
 
-	// 1. FTS5 keyword search
-	ftsResults, err := o.db.FilteredSearch(db.SearchFilter{
-		Query:   q.Text,
-		Type:    q.Type,
-		Project: q.Project,
-		Limit:   q.Limit * 2, // Fetch extra for merging
-	})
-	if err == nil && len(ftsResults) > 0 {
-		// Normalize FTS scores: best rank = 1.0, worst = 0.0
-		maxRank := 1.0
-		for i, r := range ftsResults {
-			// FTS5 rank is negative (more negative = better match)
-			// Position-based scoring: first result = best
-			score := 1.0 - float64(i)/float64(len(ftsResults))
-			if score > maxRank {
-				maxRank = score
-			}
-			merged[r.ID] = &HybridResult{
-				ID:        r.ID,
-				Score:     score * o.weights.FTS,
-				Title:     r.Title,
-				Text:      r.Text,
-				ObsType:   r.Type,
-				Project:   r.Project,
-				SessionID: r.SessionID,
-			}
+	// 1. FTS5 keyword search, best match first
+	var ftsResults []*db.Observation
+	if ftsQuery := db.EscapeFTSQuery(q.Text); ftsQuery != "" && q.Mode != ModeSemantic {
+		var err error
+		ftsResults, err = o.db.FilteredSearch(db.SearchFilter{
+			Query:     ftsQuery,
+			Type:      q.Type,
+			Project:   q.Project,
+			DateStart: q.DateStart,
+			DateEnd:   q.DateEnd,
+			Limit:     q.Limit * 2, // Fetch extra for merging
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, obs := range ftsResults {
+			r, e := entry(obs.ID)
+			r.Title, r.Text, r.ObsType = obs.Title, obs.Text, obs.Type
+			r.Project, r.SessionID, r.CreatedAt = obs.Project, obs.SessionID, obs.CreatedAt
+			e.FTSRank = i + 1
 		}
 	}
 
-	// 2. Vector similarity search
-	vecResults, err := o.vector.Search(q.Text, q.Limit*2)
-	if err == nil && len(vecResults) > 0 {
-		for _, r := range vecResults {
-			// Apply type/project filters
-			if q.Type != "" && r.ObsType != q.Type {
-				continue
-			}
-			if q.Project != "" && r.Project != q.Project {
+	// 2. Vector similarity search. Failures (e.g. an unreachable embeddings
+	// endpoint, or a rebuild for a new model) leave the FTS5 results, unless
+	// they were all that was asked for.
+	if q.Mode != ModeFTS {
+		vecResults, err := o.vector.Search(ctx, q.Text, q.Limit*2)
+		if err != nil && q.Mode == ModeSemantic {
+			return nil, err
+		}
+		rank := 0
+		for _, v := range vecResults {
+			if !q.matches(v) {
 				continue
 			}
-
-			vectorScore := r.Score * o.weights.Vector
-			if existing, ok := merged[r.ID]; ok {
-				existing.Score += vectorScore
-			} else {
-				merged[r.ID] = &HybridResult{
-					ID:        r.ID,
-					Score:     vectorScore,
-					Title:     r.Title,
-					Text:      r.Text,
-					ObsType:   r.ObsType,
-					Project:   r.Project,
-					SessionID: r.SessionID,
-				}
-			}
+			rank++
+			r, e := entry(v.ID)
+			r.Title, r.Text, r.ObsType = v.Title, v.Text, v.ObsType
+			r.Project, r.SessionID, r.CreatedAt = v.Project, v.SessionID, v.CreatedAt
+			e.VectorRank = rank
+			e.VectorScore = v.Score
 		}
 	}
 
-	// 3. Sort by combined score descending
+	// 3. Fuse, decay and boost
+	now := o.now()
 	results := make([]HybridResult, 0, len(merged))
-	for _, r := range merged {
+	for id, r := range merged {
+		e := explain[id]
+		e.FusedScore = o.fuse(fusion, e, len(ftsResults))
+		e.RecencyFactor = o.recencyFactor(now.Sub(r.CreatedAt), r.CreatedAt.IsZero())
+		e.Boost = o.typeBoost(r.ObsType)
+		r.Score = e.FusedScore * e.RecencyFactor * e.Boost
+		if q.Explain {
+			r.Explanation = e
+		}
 		results = append(results, *r)
 	}
+
+	// 4. Sort by score descending, newest first on ties
 	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID > results[j].ID
 	})
 
-	// 4. Truncate to limit
+	// 5. Truncate to limit
 	if len(results) > q.Limit {
 		results = results[:q.Limit]
 	}
 
 	return results, nil
 }
+
+// matches applies the query's filters to a vector result. Dates compare like
+// the SQL filters in db.FilteredSearch.
+func (q SearchQuery) matches(v VectorResult) bool {
+	if q.Type != "" && v.ObsType != q.Type {
+		return false
+	}
+	if q.Project != "" && v.Project != q.Project {
+		return false
+	}
+	created := v.CreatedAt.Format("2006-01-02 15:04:05")
+	if q.DateStart != "" && created < q.DateStart {
+		return false
+	}
+	if q.DateEnd != "" && created > q.DateEnd {
+		return false
+	}
+	return true
+}
+
+// fuse combines a result's FTS5 rank and vector score into a single score.
+// ftsCount is the length of the FTS5 result list.
+func (o *Orchestrator) fuse(fusion Fusion, e *Explanation, ftsCount int) float64 {
+	w := o.ranking.Weights
+	var score float64
+	if fusion == FusionRRF {
+		k := o.ranking.RRFK
+		if e.FTSRank > 0 {
+			score += w.FTS / (k + float64(e.FTSRank))
+		}
+		if e.VectorRank > 0 {
+			score += w.Vector / (k + float64(e.VectorRank))
+		}
+		return score
+	}
+
+	// Position-based FTS5 score: first result = 1.0
+	if e.FTSRank > 0 {
+		score += w.FTS * (1 - float64(e.FTSRank-1)/float64(ftsCount))
+	}
+	return score + w.Vector*e.VectorScore
+}
+
+// recencyFactor returns the multiplier for an observation of the given age.
+// It is 1 for new observations and approaches 1-RecencyWeight as the age
+// grows. Unknown ages are not penalized.
+func (o *Orchestrator) recencyFactor(age time.Duration, unknown bool) float64 {
+	halfLife, weight := o.ranking.RecencyHalfLife, o.ranking.RecencyWeight
+	if halfLife <= 0 || weight <= 0 || unknown {
+		return 1
+	}
+	if age < 0 {
+		age = 0
+	}
+	decay := math.Pow(0.5, float64(age)/float64(halfLife))
+	return 1 - weight + weight*decay
+}
+
+// typeBoost returns the configured boost for an observation type.
+func (o *Orchestrator) typeBoost(obsType string) float64 {
+	if b, ok := o.ranking.TypeBoosts[obsType]; ok {
+		return b
+	}
+	return 1
+}
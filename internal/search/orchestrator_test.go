@@ -1,7 +1,10 @@
 package search
 
 import (
+	"context"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/itk-dev/itkdev-claude-code/internal/db"
 )
@@ -50,7 +53,7 @@ func TestOrchestratorSearch(t *testing.T) {
 		t.Fatalf("RebuildIndex: %v", err)
 	}
 
-	results, err := orch.Search(SearchQuery{
+	results, err := orch.Search(context.Background(), SearchQuery{
 		Text:  "authentication login session",
 		Limit: 5,
 	})
@@ -82,7 +85,7 @@ func TestOrchestratorSearchWithTypeFilter(t *testing.T) {
 		t.Fatalf("RebuildIndex: %v", err)
 	}
 
-	results, err := orch.Search(SearchQuery{
+	results, err := orch.Search(context.Background(), SearchQuery{
 		Text:  "authentication",
 		Type:  "feature",
 		Limit: 10,
@@ -107,7 +110,7 @@ func TestOrchestratorSearchEmpty(t *testing.T) {
 		t.Fatalf("NewOrchestrator: %v", err)
 	}
 
-	results, err := orch.Search(SearchQuery{Text: "anything", Limit: 5})
+	results, err := orch.Search(context.Background(), SearchQuery{Text: "anything", Limit: 5})
 	if err != nil {
 		t.Fatalf("Search: %v", err)
 	}
@@ -127,6 +130,124 @@ func TestOrchestratorWeightConfig(t *testing.T) {
 	}
 }
 
+func TestOrchestratorFusionStrategies(t *testing.T) {
+	database := testDB(t)
+	ids := seedObservations(t, database)
+
+	orch, err := NewOrchestrator(database)
+	if err != nil {
+		t.Fatalf("NewOrchestrator: %v", err)
+	}
+
+	for _, fusion := range []Fusion{FusionWeighted, FusionRRF} {
+		results, err := orch.Search(context.Background(), SearchQuery{Text: "authentication", Limit: 5, Fusion: fusion, Explain: true})
+		if err != nil {
+			t.Fatalf("Search(%s): %v", fusion, err)
+		}
+		if len(results) == 0 {
+			t.Fatalf("Search(%s) returned no results", fusion)
+		}
+		for _, r := range results {
+			e := r.Explanation
+			if e == nil {
+				t.Fatalf("Search(%s): result %d has no explanation", fusion, r.ID)
+			}
+			if e.Fusion != fusion {
+				t.Errorf("explanation fusion = %q, want %q", e.Fusion, fusion)
+			}
+			if want := e.FusedScore * e.RecencyFactor * e.Boost; math.Abs(r.Score-want) > 1e-12 {
+				t.Errorf("Search(%s): score %f != fused × recency × boost %f", fusion, r.Score, want)
+			}
+		}
+		if id := results[0].ID; id != ids[0] && id != ids[2] {
+			t.Errorf("Search(%s): first result ID=%d, expected an auth observation", fusion, id)
+		}
+	}
+
+	// RRF: a single first-place FTS5 match scores weight/(k+1)
+	orch.SetRanking(Ranking{Fusion: FusionRRF, Weights: Weights{FTS: 1}, RRFK: 60})
+	results, err := orch.Search(context.Background(), SearchQuery{Text: "flexbox", Limit: 5, Explain: true})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != ids[3] {
+		t.Fatalf("expected only the css observation, got %+v", results)
+	}
+	if e := results[0].Explanation; e.FTSRank != 1 || math.Abs(e.FusedScore-1.0/61) > 1e-12 {
+		t.Errorf("explanation = %+v, want fts_rank 1 and fused score 1/61", e)
+	}
+
+	results, err = orch.Search(context.Background(), SearchQuery{Text: "flexbox", Limit: 5})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if results[0].Explanation != nil {
+		t.Error("explanation should be omitted unless requested")
+	}
+}
+
+func TestOrchestratorRecencyAndBoost(t *testing.T) {
+	database := testDB(t)
+	ids := seedObservations(t, database)
+
+	// Age the first auth observation by 60 days
+	if _, err := database.Conn().Exec(
+		`UPDATE observations SET created_at = datetime('now', '-60 days') WHERE id = ?`, ids[0],
+	); err != nil {
+		t.Fatalf("age observation: %v", err)
+	}
+
+	orch, err := NewOrchestrator(database)
+	if err != nil {
+		t.Fatalf("NewOrchestrator: %v", err)
+	}
+	orch.SetRanking(Ranking{
+		Fusion:          FusionRRF,
+		Weights:         DefaultWeights(),
+		RRFK:            60,
+		RecencyHalfLife: 30 * 24 * time.Hour,
+		RecencyWeight:   0.5,
+		TypeBoosts:      map[string]float64{"feature": 3},
+	})
+
+	results, err := orch.Search(context.Background(), SearchQuery{Text: "authentication session", Limit: 10, Explain: true})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	byID := make(map[int64]*Explanation)
+	for _, r := range results {
+		byID[r.ID] = r.Explanation
+	}
+
+	// Two half-lives: 0.5 + 0.5×0.25
+	if e := byID[ids[0]]; e == nil || math.Abs(e.RecencyFactor-0.625) > 0.01 {
+		t.Errorf("aged observation explanation = %+v, want recency factor ~0.625", e)
+	}
+	if e := byID[ids[2]]; e == nil || e.RecencyFactor < 0.99 {
+		t.Errorf("fresh observation explanation = %+v, want recency factor ~1", e)
+	}
+	for _, r := range results {
+		want := 1.0
+		if r.ObsType == "feature" {
+			want = 3
+		}
+		if r.Explanation.Boost != want {
+			t.Errorf("result %d (%s) boost = %f, want %f", r.ID, r.ObsType, r.Explanation.Boost, want)
+		}
+	}
+}
+
+func TestParseFusion(t *testing.T) {
+	for _, s := range []string{"", "weighted", "rrf"} {
+		if _, err := ParseFusion(s); err != nil {
+			t.Errorf("ParseFusion(%q): %v", s, err)
+		}
+	}
+	if _, err := ParseFusion("bm25"); err == nil {
+		t.Error("ParseFusion should reject unknown strategies")
+	}
+}
+
 func TestFormatResult(t *testing.T) {
 	r := &HybridResult{
 		ID:      1,
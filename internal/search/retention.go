@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/itk-dev/itkdev-claude-code/internal/config"
 	"github.com/itk-dev/itkdev-claude-code/internal/db"
 )
 
@@ -16,10 +17,15 @@ type RetentionConfig struct {
 
 // DefaultRetentionConfig returns the default retention settings.
 func DefaultRetentionConfig() RetentionConfig {
+	return RetentionFromConfig(config.Defaults().Retention)
+}
+
+// RetentionFromConfig converts the retention section of the config.
+func RetentionFromConfig(c config.RetentionConfig) RetentionConfig {
 	return RetentionConfig{
-		MaxAgeDays:        90,
-		StaleSessionHours: 24,
-		Interval:          6 * time.Hour,
+		MaxAgeDays:        c.MaxAgeDays,
+		StaleSessionHours: c.StaleSessionHours,
+		Interval:          c.Interval,
 	}
 }
 
@@ -0,0 +1,132 @@
+package search
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Vector is an embedding produced by an Embedder. Dense vectors hold one
+// value per dimension and leave Indices nil. Sparse vectors (TF-IDF) set
+// Indices, sorted ascending, with Values holding the matching weights;
+// missing indices are zero. Values are float32 to halve memory and storage.
+type Vector struct {
+	Indices []int
+	Values  []float32
+}
+
+// newDenseVector L2-normalizes values and converts them to a dense Vector.
+func newDenseVector(values []float64) Vector {
+	normalize(values)
+	return Vector{Values: toFloat32(values)}
+}
+
+func toFloat32(values []float64) []float32 {
+	out := make([]float32, len(values))
+	for i, v := range values {
+		out[i] = float32(v)
+	}
+	return out
+}
+
+// Sparse reports whether the vector uses the sparse representation.
+func (v Vector) Sparse() bool {
+	return v.Indices != nil
+}
+
+// Dimension returns the length of a dense vector, or 0 for sparse vectors.
+func (v Vector) Dimension() int {
+	if v.Sparse() {
+		return 0
+	}
+	return len(v.Values)
+}
+
+// Dot returns the dot product of two vectors. For L2-normalized vectors this
+// is their cosine similarity. Dense vectors of different dimensions come from
+// different models and are never similar.
+func (v Vector) Dot(o Vector) float64 {
+	var dot float32
+	switch {
+	case v.Sparse() && o.Sparse():
+		i, j := 0, 0
+		for i < len(v.Indices) && j < len(o.Indices) {
+			switch {
+			case v.Indices[i] == o.Indices[j]:
+				dot += v.Values[i] * o.Values[j]
+				i++
+				j++
+			case v.Indices[i] < o.Indices[j]:
+				i++
+			default:
+				j++
+			}
+		}
+	case v.Sparse():
+		return o.Dot(v)
+	case o.Sparse():
+		for i, idx := range o.Indices {
+			if idx < len(v.Values) {
+				dot += v.Values[idx] * o.Values[i]
+			}
+		}
+	default:
+		if len(v.Values) != len(o.Values) {
+			return 0
+		}
+		a, b := v.Values, o.Values[:len(v.Values)]
+		for i := range a {
+			dot += a[i] * b[i]
+		}
+	}
+	return float64(dot)
+}
+
+// Blob layout markers for EncodeEmbedding.
+const (
+	blobDense  byte = 'd'
+	blobSparse byte = 's'
+)
+
+// EncodeEmbedding serializes a vector for SQLite BLOB storage. Dense vectors
+// are stored as float32 values; sparse vectors as (uint32 index, float32
+// value) pairs. A leading byte records which layout was used.
+func EncodeEmbedding(v Vector) []byte {
+	if !v.Sparse() {
+		buf := make([]byte, 1+len(v.Values)*4)
+		buf[0] = blobDense
+		for i, f := range v.Values {
+			binary.LittleEndian.PutUint32(buf[1+i*4:], math.Float32bits(f))
+		}
+		return buf
+	}
+	buf := make([]byte, 1+len(v.Indices)*8)
+	buf[0] = blobSparse
+	for i, idx := range v.Indices {
+		binary.LittleEndian.PutUint32(buf[1+i*8:], uint32(idx))
+		binary.LittleEndian.PutUint32(buf[1+i*8+4:], math.Float32bits(v.Values[i]))
+	}
+	return buf
+}
+
+// DecodeEmbedding deserializes bytes produced by EncodeEmbedding.
+func DecodeEmbedding(data []byte) Vector {
+	if len(data) == 0 {
+		return Vector{}
+	}
+	kind, data := data[0], data[1:]
+	if kind == blobDense {
+		n := len(data) / 4
+		v := Vector{Values: make([]float32, n)}
+		for i := 0; i < n; i++ {
+			v.Values[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+		return v
+	}
+	n := len(data) / 8
+	v := Vector{Indices: make([]int, n), Values: make([]float32, n)}
+	for i := 0; i < n; i++ {
+		v.Indices[i] = int(binary.LittleEndian.Uint32(data[i*8:]))
+		v.Values[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*8+4:]))
+	}
+	return v
+}
@@ -1,120 +1,525 @@
 package search
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/itk-dev/itkdev-claude-code/internal/db"
 )
 
+// indexFormat identifies the layout of the index tables. Stores written in
+// another format are dropped and rebuilt on open.
+const indexFormat = "v3"
+
+// defaultANNThreshold is the number of vectors above which searches use the
+// approximate HNSW index instead of an exact linear scan.
+const defaultANNThreshold = 2000
+
+// embedBatchSize is the number of texts sent to an embedder per call when
+// rebuilding the index.
+const embedBatchSize = 32
+
+// ErrIndexing is returned by Search while the index is rebuilt for a new
+// embedding model. Hybrid searches use the FTS5 results meanwhile.
+var ErrIndexing = errors.New("vector index is being rebuilt")
+
 // VectorResult holds a search result with its similarity score.
 type VectorResult struct {
-	ID         int64
-	Score      float64
-	Title      string
-	Text       string
-	ObsType    string
-	Project    string
-	SessionID  string
+	ID        int64
+	Score     float64
+	Title     string
+	Text      string
+	ObsType   string
+	Project   string
+	SessionID string
+	CreatedAt time.Time
 }
 
-// VectorStore manages TF-IDF embeddings stored in SQLite alongside observations.
+// VectorStore manages embeddings stored in SQLite alongside observations.
+// Each embedding records the model that produced it; when the configured
+// Embedder's model differs from the stored one, the index is rebuilt.
+//
+// With the default TF-IDF embedder, the vocabulary and document frequencies
+// are persisted too, so the store is searchable immediately after opening and
+// new observations are indexed without touching the rest of the corpus.
+// Stored TF-IDF vectors drift as IDF weights change; Reweight recomputes them
+// in bulk.
+//
+// Vectors are kept in memory. Small stores are searched exactly by a linear
+// scan; once a store reaches annThreshold vectors, an HNSW index is built in
+// the background and used for approximate search when ready.
+//
+// Other embedders are usually remote, so re-embedding the corpus after a
+// model change runs in the background, and Search returns ErrIndexing until
+// it's done. Single observations are queued and embedded in the background
+// too; those whose embedding fails are retried by Reweight.
 type VectorStore struct {
-	db    *db.DB
-	vocab *Vocabulary
+	mu           sync.RWMutex
+	rebuilding   atomic.Bool // a rebuild for a new model is running
+	db           *db.DB
+	embedder     Embedder
+	pending      int              // observations indexed incrementally since the last full rebuild
+	vectors      map[int64]Vector // current model's embeddings by observation ID
+	ann          *hnswIndex       // approximate index; nil below annThreshold
+	annThreshold int
+
+	queueMu  sync.Mutex
+	queue    []int64        // observations waiting to be embedded in the background
+	queued   map[int64]bool // observations in queue
+	draining bool           // a goroutine is working through the queue
 }
 
-// NewVectorStore creates a vector store backed by the given database.
-// It creates the embeddings table if it doesn't exist and rebuilds the
-// vocabulary from existing observations.
+// NewVectorStore creates a vector store using the default TF-IDF embedder.
 func NewVectorStore(database *db.DB) (*VectorStore, error) {
-	if err := createEmbeddingsTable(database); err != nil {
+	return NewVectorStoreWithEmbedder(database, NewTFIDFEmbedder())
+}
+
+// NewVectorStoreWithEmbedder creates a vector store backed by the given
+// database and embedder. It creates the index tables if they don't exist,
+// loads the persisted state, and indexes any observations that have no
+// embedding yet. Stores written in an older format or by a different model
+// are rebuilt from scratch. Both happen right away for TF-IDF, and in the
+// background for other embedders, whose endpoint may be slow or unreachable.
+// The reweighter retries what failed in the background.
+func NewVectorStoreWithEmbedder(database *db.DB, embedder Embedder) (*VectorStore, error) {
+	vs := &VectorStore{db: database, embedder: embedder, annThreshold: defaultANNThreshold}
+
+	if err := vs.createTables(); err != nil {
+		return nil, err
+	}
+
+	model, err := vs.meta("model")
+	if err != nil {
+		return nil, err
+	}
+	if model != embedder.Model() {
+		if vs.tfidf() != nil {
+			if err := vs.IndexAll(); err != nil {
+				return nil, err
+			}
+			return vs, nil
+		}
+		vs.vectors = make(map[int64]Vector)
+		vs.rebuilding.Store(true)
+		go func() {
+			defer vs.rebuilding.Store(false)
+			vs.IndexAll() //nolint:errcheck // Retried by Reweight
+		}()
+		return vs, nil
+	}
+
+	if err := vs.load(); err != nil {
+		return nil, err
+	}
+	if err := vs.loadVectors(); err != nil {
+		return nil, err
+	}
+	if vs.tfidf() == nil {
+		if err := vs.queueMissing(); err != nil {
+			return nil, err
+		}
+		return vs, nil
+	}
+	if err := vs.IndexMissing(); err != nil {
 		return nil, err
 	}
-	return &VectorStore{db: database}, nil
+	return vs, nil
 }
 
-func createEmbeddingsTable(database *db.DB) error {
-	_, err := database.Conn().Exec(`
-		CREATE TABLE IF NOT EXISTS observation_embeddings (
+// createTables creates the index tables, dropping tables left by an older
+// index format first.
+func (vs *VectorStore) createTables() error {
+	conn := vs.db.Conn()
+	if _, err := conn.Exec(`CREATE TABLE IF NOT EXISTS search_meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create search meta table: %w", err)
+	}
+
+	format, err := vs.meta("index_format")
+	if err != nil {
+		return err
+	}
+	if format != indexFormat {
+		for _, stmt := range []string{
+			`DROP TABLE IF EXISTS observation_embeddings`,
+			`DROP TABLE IF EXISTS search_vocabulary`,
+			`DELETE FROM search_meta`,
+		} {
+			if _, err := conn.Exec(stmt); err != nil {
+				return fmt.Errorf("drop old index: %w", err)
+			}
+		}
+	}
+
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS observation_embeddings (
 			observation_id INTEGER PRIMARY KEY,
 			embedding BLOB NOT NULL,
+			model TEXT NOT NULL DEFAULT '',
+			dimension INTEGER NOT NULL DEFAULT 0,
 			FOREIGN KEY (observation_id) REFERENCES observations(id) ON DELETE CASCADE
-		)
-	`)
+		)`,
+		`CREATE TABLE IF NOT EXISTS search_vocabulary (
+			token TEXT PRIMARY KEY,
+			idx INTEGER NOT NULL UNIQUE,
+			df INTEGER NOT NULL
+		)`,
+		`INSERT OR IGNORE INTO search_meta (key, value) VALUES ('index_format', '` + indexFormat + `')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := conn.Exec(stmt); err != nil {
+			return fmt.Errorf("create index tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// meta reads a value from search_meta. Returns "" if the key is unset.
+func (vs *VectorStore) meta(key string) (string, error) {
+	var value string
+	err := vs.db.Conn().QueryRow(`SELECT value FROM search_meta WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read search meta %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func setMeta(tx *sql.Tx, key, value string) error {
+	_, err := tx.Exec(
+		`INSERT INTO search_meta (key, value) VALUES (?, ?)
+		 ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
+		key, value,
+	)
+	if err != nil {
+		return fmt.Errorf("write search meta %s: %w", key, err)
+	}
+	return nil
+}
+
+// tfidf returns the store's embedder if it is the corpus-dependent TF-IDF
+// embedder, or nil otherwise.
+func (vs *VectorStore) tfidf() *TFIDFEmbedder {
+	e, _ := vs.embedder.(*TFIDFEmbedder)
+	return e
+}
+
+// Model returns the model name of the store's embedder.
+func (vs *VectorStore) Model() string {
+	return vs.embedder.Model()
+}
+
+// load reads the persisted vocabulary and counters into memory.
+func (vs *VectorStore) load() error {
+	pending, err := vs.meta("pending")
+	if err != nil {
+		return err
+	}
+	vs.pending, _ = strconv.Atoi(pending)
+
+	tf := vs.tfidf()
+	if tf == nil {
+		return nil
+	}
+
+	rows, err := vs.db.Conn().Query(`SELECT token, idx, df FROM search_vocabulary ORDER BY idx`)
 	if err != nil {
-		return fmt.Errorf("create embeddings table: %w", err)
+		return fmt.Errorf("load vocabulary: %w", err)
 	}
+	defer rows.Close()
+
+	vocab := newEmptyVocabulary()
+	for rows.Next() {
+		var token string
+		var idx, df int
+		if err := rows.Scan(&token, &idx, &df); err != nil {
+			return fmt.Errorf("scan vocabulary: %w", err)
+		}
+		// Indices are dense, but tolerate gaps rather than mis-assigning.
+		for len(vocab.tokens) < idx {
+			vocab.tokens = append(vocab.tokens, "")
+			vocab.df = append(vocab.df, 0)
+		}
+		vocab.index[token] = idx
+		vocab.tokens = append(vocab.tokens, token)
+		vocab.df = append(vocab.df, df)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate vocabulary: %w", err)
+	}
+
+	docs, err := vs.meta("doc_count")
+	if err != nil {
+		return err
+	}
+	vocab.docs, _ = strconv.Atoi(docs)
+
+	tf.vocab = vocab
 	return nil
 }
 
-// IndexAll builds a vocabulary from all observations and indexes them.
+// IndexAll rebuilds the index from all observations. With the TF-IDF
+// embedder the vocabulary is rebuilt from the corpus first. Other embedders
+// don't depend on the corpus, so observations are embedded without holding
+// the lock, and those indexed meanwhile are kept.
 func (vs *VectorStore) IndexAll() error {
-	// Load all observation texts to build vocabulary
+	if vs.tfidf() == nil {
+		ids, docs, err := vs.loadDocuments()
+		if err != nil {
+			return err
+		}
+		vecs, err := embedBatches(vs.embedder, docs)
+		if err != nil {
+			return err
+		}
+		vs.mu.Lock()
+		defer vs.mu.Unlock()
+		return vs.writeIndexLocked(ids, vecs, nil)
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	ids, docs, err := vs.loadDocuments()
+	if err != nil {
+		return err
+	}
+	// Build vocabulary from corpus and embed every document
+	vocab := NewVocabulary(docs)
+	vecs, err := embedBatches(&TFIDFEmbedder{vocab: vocab}, docs)
+	if err != nil {
+		return err
+	}
+	return vs.writeIndexLocked(ids, vecs, vocab)
+}
+
+// loadDocuments returns the IDs and texts of all observations.
+func (vs *VectorStore) loadDocuments() ([]int64, []string, error) {
 	rows, err := vs.db.Conn().Query(
 		`SELECT id, title, text FROM observations ORDER BY id`,
 	)
 	if err != nil {
-		return fmt.Errorf("load observations: %w", err)
+		return nil, nil, fmt.Errorf("load observations: %w", err)
 	}
 	defer rows.Close()
 
-	type obsText struct {
-		id    int64
-		title string
-		text  string
-	}
-
-	var observations []obsText
+	var ids []int64
 	var docs []string
 	for rows.Next() {
-		var o obsText
-		if err := rows.Scan(&o.id, &o.title, &o.text); err != nil {
-			return fmt.Errorf("scan observation: %w", err)
+		var id int64
+		var title, text string
+		if err := rows.Scan(&id, &title, &text); err != nil {
+			return nil, nil, fmt.Errorf("scan observation: %w", err)
 		}
-		observations = append(observations, o)
-		docs = append(docs, o.title+" "+o.text)
+		ids = append(ids, id)
+		docs = append(docs, title+" "+text)
 	}
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("iterate observations: %w", err)
-	}
-
-	if len(docs) == 0 {
-		return nil
+		return nil, nil, fmt.Errorf("iterate observations: %w", err)
 	}
+	return ids, docs, nil
+}
 
-	// Build vocabulary from corpus
-	vs.vocab = NewVocabulary(docs)
-
-	// Index each observation
+// writeIndexLocked replaces the stored index with the given embeddings, and
+// the vocabulary if there is one. Without a vocabulary, embeddings of the
+// current model that aren't replaced are kept. Callers must hold vs.mu for
+// writing.
+func (vs *VectorStore) writeIndexLocked(ids []int64, vecs []Vector, vocab *Vocabulary) error {
 	tx, err := vs.db.Conn().Begin()
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
+	defer tx.Rollback()
+
+	model := vs.embedder.Model()
+	if vocab != nil {
+		if _, err := tx.Exec(`DELETE FROM observation_embeddings`); err != nil {
+			return fmt.Errorf("clear embeddings: %w", err)
+		}
+		if err := writeVocabulary(tx, vocab); err != nil {
+			return err
+		}
+	} else if _, err := tx.Exec(`DELETE FROM observation_embeddings WHERE model != ?`, model); err != nil {
+		return fmt.Errorf("clear embeddings: %w", err)
+	}
 
 	stmt, err := tx.Prepare(
-		`INSERT OR REPLACE INTO observation_embeddings (observation_id, embedding) VALUES (?, ?)`,
+		`INSERT OR REPLACE INTO observation_embeddings (observation_id, embedding, model, dimension) VALUES (?, ?, ?, ?)`,
 	)
 	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("prepare insert: %w", err)
+		return fmt.Errorf("prepare embedding insert: %w", err)
 	}
 	defer stmt.Close()
 
-	for i, o := range observations {
-		vec := vs.vocab.Embed(docs[i])
-		blob := EncodeVector(vec)
-		if _, err := stmt.Exec(o.id, blob); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("insert embedding for %d: %w", o.id, err)
+	dimension := 0
+	for i, id := range ids {
+		dimension = vecs[i].Dimension()
+		if _, err := stmt.Exec(id, EncodeEmbedding(vecs[i]), model, dimension); err != nil {
+			return fmt.Errorf("insert embedding for %d: %w", id, err)
+		}
+	}
+
+	meta := map[string]string{
+		"model":     model,
+		"dimension": strconv.Itoa(dimension),
+		"doc_count": strconv.Itoa(len(ids)),
+		"pending":   "0",
+	}
+	for key, value := range meta {
+		if err := setMeta(tx, key, value); err != nil {
+			return err
 		}
 	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit index: %w", err)
+	}
 
-	return tx.Commit()
+	if vocab != nil {
+		vs.tfidf().vocab = vocab
+		vs.vectors = nil
+	}
+	if vs.vectors == nil {
+		vs.vectors = make(map[int64]Vector, len(ids))
+	}
+	vs.pending = 0
+	for i, id := range ids {
+		vs.vectors[id] = vecs[i]
+	}
+	vs.rebuildANNLocked()
+	return nil
 }
 
-// IndexObservation indexes a single observation. If no vocabulary exists yet,
-// it rebuilds from all observations.
+// loadVectors reads the current model's embeddings into memory.
+func (vs *VectorStore) loadVectors() error {
+	rows, err := vs.db.Conn().Query(`
+		SELECT e.observation_id, e.embedding
+		FROM observation_embeddings e
+		JOIN observations o ON o.id = e.observation_id
+		WHERE e.model = ?
+	`, vs.embedder.Model())
+	if err != nil {
+		return fmt.Errorf("load embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	vectors := make(map[int64]Vector)
+	for rows.Next() {
+		var id int64
+		var blob []byte
+		if err := rows.Scan(&id, &blob); err != nil {
+			return fmt.Errorf("scan embedding: %w", err)
+		}
+		vectors[id] = DecodeEmbedding(blob)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate embeddings: %w", err)
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	vs.vectors = vectors
+	vs.rebuildANNLocked()
+	return nil
+}
+
+// rebuildANNLocked replaces the HNSW index with one built in the background
+// from the in-memory vectors, or drops it if the store is below the ANN
+// threshold. Searches fall back to a linear scan until the build finishes.
+// Callers must hold vs.mu for writing.
+func (vs *VectorStore) rebuildANNLocked() {
+	if vs.ann != nil {
+		vs.ann.Close()
+		vs.ann = nil
+	}
+	if len(vs.vectors) < vs.annThreshold {
+		return
+	}
+
+	entries := make([]hnswEntry, 0, len(vs.vectors))
+	for id, vec := range vs.vectors {
+		entries = append(entries, hnswEntry{id: id, vec: vec})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].id < entries[j].id })
+
+	vs.ann = newHNSWIndex(int64(len(entries)))
+	go vs.ann.build(entries)
+}
+
+// setVectorLocked records a new or updated vector in memory and in the ANN
+// index. Callers must hold vs.mu for writing.
+func (vs *VectorStore) setVectorLocked(id int64, vec Vector) {
+	vs.vectors[id] = vec
+	switch {
+	case vs.ann == nil && len(vs.vectors) >= vs.annThreshold:
+		vs.rebuildANNLocked()
+	case vs.ann != nil:
+		vs.ann.Add(id, vec)
+		if vs.ann.Fragmented() {
+			vs.rebuildANNLocked()
+		}
+	}
+}
+
+// removeVectorLocked drops a vector from memory and the ANN index. Callers
+// must hold vs.mu for writing.
+func (vs *VectorStore) removeVectorLocked(id int64) {
+	delete(vs.vectors, id)
+	if vs.ann != nil {
+		vs.ann.Remove(id)
+		if vs.ann.Fragmented() {
+			vs.rebuildANNLocked()
+		}
+	}
+}
+
+// embedBatches embeds docs in batches of embedBatchSize.
+func embedBatches(embedder Embedder, docs []string) ([]Vector, error) {
+	vecs := make([]Vector, 0, len(docs))
+	for start := 0; start < len(docs); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(docs))
+		batch, err := embedder.Embed(context.Background(), docs[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("embed observations: %w", err)
+		}
+		vecs = append(vecs, batch...)
+	}
+	return vecs, nil
+}
+
+// writeVocabulary replaces the persisted vocabulary.
+func writeVocabulary(tx *sql.Tx, vocab *Vocabulary) error {
+	if _, err := tx.Exec(`DELETE FROM search_vocabulary`); err != nil {
+		return fmt.Errorf("clear vocabulary: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO search_vocabulary (token, idx, df) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare vocabulary insert: %w", err)
+	}
+	defer stmt.Close()
+	for idx, tok := range vocab.tokens {
+		if _, err := stmt.Exec(tok, idx, vocab.df[idx]); err != nil {
+			return fmt.Errorf("insert vocabulary %q: %w", tok, err)
+		}
+	}
+	return nil
+}
+
+// IndexObservation indexes a single observation. With the TF-IDF embedder the
+// persisted vocabulary is updated incrementally. Re-indexing an observation
+// replaces its previous contribution, so it is safe to call after an
+// observation changes.
 func (vs *VectorStore) IndexObservation(id int64) error {
 	obs, err := vs.db.GetObservation(id)
 	if err != nil {
@@ -123,83 +528,491 @@ func (vs *VectorStore) IndexObservation(id int64) error {
 	if obs == nil {
 		return fmt.Errorf("observation %d not found", id)
 	}
+	doc := obs.Title + " " + obs.Text
 
-	// Rebuild vocabulary if needed (ensures new terms are included)
-	if err := vs.IndexAll(); err != nil {
+	if vs.tfidf() == nil {
+		// External embedders are independent of the corpus; embed without
+		// holding the lock so searches aren't blocked on the provider.
+		vecs, err := vs.embedder.Embed(context.Background(), []string{doc})
+		if err != nil {
+			return fmt.Errorf("embed observation %d: %w", id, err)
+		}
+		vs.mu.Lock()
+		defer vs.mu.Unlock()
+		if err := vs.storeEmbedding(id, vecs[0]); err != nil {
+			return err
+		}
+		vs.setVectorLocked(id, vecs[0])
+		return nil
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if err := vs.indexTFIDFLocked(id, doc); err != nil {
+		// The in-memory vocabulary may have diverged from the rolled-back
+		// tables; reload the persisted state.
+		if loadErr := vs.load(); loadErr != nil {
+			return fmt.Errorf("%w (reload: %v)", err, loadErr)
+		}
 		return err
 	}
+	return nil
+}
 
+// QueueObservation indexes a single observation like IndexObservation. With
+// external embedders the observation is embedded in the background, so the
+// caller doesn't wait for the provider; if that fails, the observation is
+// left unindexed and Reweight retries it. Only TF-IDF indexing errors are
+// returned.
+func (vs *VectorStore) QueueObservation(id int64) error {
+	if vs.tfidf() != nil {
+		return vs.IndexObservation(id)
+	}
+	vs.enqueue([]int64{id})
 	return nil
 }
 
-// Search finds the top-K most similar observations to the query text.
-func (vs *VectorStore) Search(query string, limit int) ([]VectorResult, error) {
-	if limit <= 0 {
-		limit = 10
+// enqueue adds observations to the background queue, and starts working
+// through it if nothing is.
+func (vs *VectorStore) enqueue(ids []int64) {
+	vs.queueMu.Lock()
+	defer vs.queueMu.Unlock()
+	if vs.queued == nil {
+		vs.queued = make(map[int64]bool)
+	}
+	for _, id := range ids {
+		if !vs.queued[id] {
+			vs.queued[id] = true
+			vs.queue = append(vs.queue, id)
+		}
+	}
+	if len(vs.queue) > 0 && !vs.draining {
+		vs.draining = true
+		go vs.drainQueue()
+	}
+}
+
+// drainQueue embeds queued observations one at a time until the queue is
+// empty. An observation that changed is re-read when its turn comes, so
+// it's embedded with its latest text.
+func (vs *VectorStore) drainQueue() {
+	for {
+		vs.queueMu.Lock()
+		if len(vs.queue) == 0 {
+			vs.draining = false
+			vs.queueMu.Unlock()
+			return
+		}
+		id := vs.queue[0]
+		vs.queue = vs.queue[1:]
+		delete(vs.queued, id)
+		vs.queueMu.Unlock()
+
+		if err := vs.IndexObservation(id); err != nil {
+			// Drop the embedding of the observation's old text, so it
+			// counts as missing and Reweight retries it
+			vs.RemoveObservation(id) //nolint:errcheck // Retried by Reweight
+		}
+	}
+}
+
+// RemoveObservation drops an observation's embedding, e.g. after it has been
+// deleted. For TF-IDF its contribution to the document frequencies is
+// subtracted; the remaining vectors are re-weighted by the reweighter.
+func (vs *VectorStore) RemoveObservation(id int64) error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if err := vs.removeEmbeddingLocked(id); err != nil {
+		if vs.tfidf() != nil {
+			// The in-memory vocabulary may have diverged from the
+			// rolled-back tables; reload the persisted state.
+			if loadErr := vs.load(); loadErr != nil {
+				return fmt.Errorf("%w (reload: %v)", err, loadErr)
+			}
+		}
+		return err
+	}
+	vs.removeVectorLocked(id)
+	return nil
+}
+
+func (vs *VectorStore) removeEmbeddingLocked(id int64) error {
+	tx, err := vs.db.Conn().Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM observation_embeddings WHERE observation_id = ?`, id); err != nil {
+		return fmt.Errorf("delete embedding for %d: %w", id, err)
+	}
+
+	vec, indexed := vs.vectors[id]
+	tf := vs.tfidf()
+	if tf == nil || !indexed {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit embedding removal for %d: %w", id, err)
+		}
+		return nil
+	}
+
+	vocab := tf.vocab
+	vocab.removeDocument(vec.Indices)
+	for _, idx := range vec.Indices {
+		if _, err := tx.Exec(`UPDATE search_vocabulary SET df = ? WHERE idx = ?`, vocab.df[idx], idx); err != nil {
+			return fmt.Errorf("update vocabulary: %w", err)
+		}
+	}
+	if err := setMeta(tx, "doc_count", strconv.Itoa(vocab.docs)); err != nil {
+		return err
+	}
+	if err := setMeta(tx, "pending", strconv.Itoa(vs.pending+1)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit embedding removal for %d: %w", id, err)
+	}
+	vs.pending++
+	return nil
+}
+
+// storeEmbedding writes a single embedding produced by the store's embedder.
+func (vs *VectorStore) storeEmbedding(id int64, vec Vector) error {
+	if _, err := vs.db.Conn().Exec(
+		`INSERT OR REPLACE INTO observation_embeddings (observation_id, embedding, model, dimension) VALUES (?, ?, ?, ?)`,
+		id, EncodeEmbedding(vec), vs.embedder.Model(), vec.Dimension(),
+	); err != nil {
+		return fmt.Errorf("insert embedding for %d: %w", id, err)
+	}
+	return nil
+}
+
+func (vs *VectorStore) indexTFIDFLocked(id int64, doc string) error {
+	vocab := vs.tfidf().vocab
+
+	tx, err := vs.db.Conn().Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	touched := make(map[int]bool)
+
+	// Undo the previous contribution of this observation, if any
+	var old []byte
+	err = tx.QueryRow(
+		`SELECT embedding FROM observation_embeddings WHERE observation_id = ? AND model = ?`,
+		id, TFIDFModel,
+	).Scan(&old)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return fmt.Errorf("load embedding for %d: %w", id, err)
+	default:
+		prev := DecodeEmbedding(old)
+		vocab.removeDocument(prev.Indices)
+		for _, idx := range prev.Indices {
+			touched[idx] = true
+		}
+	}
+
+	for _, idx := range vocab.addDocument(tokenize(doc)) {
+		touched[idx] = true
+	}
+
+	for idx := range touched {
+		if _, err := tx.Exec(
+			`INSERT INTO search_vocabulary (token, idx, df) VALUES (?, ?, ?)
+			 ON CONFLICT(token) DO UPDATE SET df = excluded.df`,
+			vocab.tokens[idx], idx, vocab.df[idx],
+		); err != nil {
+			return fmt.Errorf("update vocabulary: %w", err)
+		}
+	}
+
+	vec := vocab.EmbedSparse(doc)
+	if _, err := tx.Exec(
+		`INSERT OR REPLACE INTO observation_embeddings (observation_id, embedding, model, dimension) VALUES (?, ?, ?, 0)`,
+		id, EncodeEmbedding(vec), TFIDFModel,
+	); err != nil {
+		return fmt.Errorf("insert embedding for %d: %w", id, err)
+	}
+
+	if err := setMeta(tx, "doc_count", strconv.Itoa(vocab.docs)); err != nil {
+		return err
+	}
+	if err := setMeta(tx, "pending", strconv.Itoa(vs.pending+1)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit embedding for %d: %w", id, err)
+	}
+
+	vs.pending++
+	vs.setVectorLocked(id, vec)
+	return nil
+}
+
+// IndexMissing indexes observations that have no embedding yet, such as those
+// written while the console server was not running.
+func (vs *VectorStore) IndexMissing() error {
+	ids, err := vs.missingIDs()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := vs.IndexObservation(id); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// queueMissing queues the observations that have no embedding yet for
+// embedding in the background.
+func (vs *VectorStore) queueMissing() error {
+	ids, err := vs.missingIDs()
+	if err != nil {
+		return err
+	}
+	vs.enqueue(ids)
+	return nil
+}
 
-	// Load all embeddings
+// missingIDs returns the IDs of observations that have no embedding.
+func (vs *VectorStore) missingIDs() ([]int64, error) {
 	rows, err := vs.db.Conn().Query(`
-		SELECT e.observation_id, e.embedding, o.title, o.text, o.type, o.project, o.session_id
-		FROM observation_embeddings e
-		JOIN observations o ON o.id = e.observation_id
+		SELECT o.id FROM observations o
+		LEFT JOIN observation_embeddings e ON e.observation_id = o.id
+		WHERE e.observation_id IS NULL
+		ORDER BY o.id
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("load embeddings: %w", err)
+		return nil, fmt.Errorf("find unindexed observations: %w", err)
 	}
 	defer rows.Close()
 
-	type stored struct {
-		result VectorResult
-		vec    []float64
-	}
-
-	var entries []stored
+	var ids []int64
 	for rows.Next() {
-		var s stored
-		var blob []byte
-		if err := rows.Scan(
-			&s.result.ID, &blob,
-			&s.result.Title, &s.result.Text, &s.result.ObsType,
-			&s.result.Project, &s.result.SessionID,
-		); err != nil {
-			return nil, fmt.Errorf("scan embedding: %w", err)
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan observation id: %w", err)
 		}
-		s.vec = DecodeVector(blob)
-		entries = append(entries, s)
+		ids = append(ids, id)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate embeddings: %w", err)
+		return nil, fmt.Errorf("iterate unindexed observations: %w", err)
+	}
+	return ids, nil
+}
+
+// Stale reports whether stored TF-IDF vectors were computed with out-of-date
+// IDF weights: observations were indexed incrementally, or removed without
+// updating the vocabulary (e.g. by retention cleanup). Vectors from other
+// embedders don't depend on the corpus, and are only stale when the rebuild
+// for a new model failed.
+func (vs *VectorStore) Stale() (bool, error) {
+	tf := vs.tfidf()
+	if tf == nil {
+		if vs.rebuilding.Load() {
+			return false, nil
+		}
+		model, err := vs.meta("model")
+		return err == nil && model != vs.embedder.Model(), err
+	}
+
+	vs.mu.RLock()
+	pending, docs := vs.pending, tf.vocab.docs
+	vs.mu.RUnlock()
+
+	if pending > 0 {
+		return true, nil
+	}
+	var count int
+	if err := vs.db.Conn().QueryRow(`
+		SELECT COUNT(*) FROM observation_embeddings e
+		JOIN observations o ON o.id = e.observation_id
+	`).Scan(&count); err != nil {
+		return false, fmt.Errorf("count embeddings: %w", err)
+	}
+	return count != docs, nil
+}
+
+// Reweight rebuilds the index if it is stale, recomputing document
+// frequencies and re-embedding every observation with current IDF weights.
+// With other embedders it retries a failed rebuild for a new model, or
+// queues the observations whose embedding failed.
+func (vs *VectorStore) Reweight() error {
+	stale, err := vs.Stale()
+	if err != nil {
+		return err
+	}
+	if vs.tfidf() != nil {
+		if !stale {
+			return nil
+		}
+		return vs.IndexAll()
 	}
 
-	if len(entries) == 0 || vs.vocab == nil {
+	switch {
+	case vs.rebuilding.Load():
+		return nil
+	case !stale:
+		return vs.queueMissing()
+	case !vs.rebuilding.CompareAndSwap(false, true):
+		return nil
+	}
+	defer vs.rebuilding.Store(false)
+	return vs.IndexAll()
+}
+
+// StartReweighter starts a background goroutine that periodically calls
+// Reweight. Returns a stop function.
+func (vs *VectorStore) StartReweighter(interval time.Duration) func() {
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				vs.Reweight()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// Search finds the top-K most similar observations to the query text. It
+// returns ErrIndexing while the index is rebuilt for a new model.
+func (vs *VectorStore) Search(ctx context.Context, query string, limit int) ([]VectorResult, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if vs.rebuilding.Load() {
+		return nil, ErrIndexing
+	}
+
+	// External embedders are independent of the corpus; embed without
+	// holding the lock so indexing isn't blocked on the provider. The TF-IDF
+	// vocabulary is guarded by the lock.
+	var queryVec Vector
+	var err error
+	tf := vs.tfidf()
+	if tf == nil {
+		if queryVec, err = vs.embedQuery(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
+	if tf != nil {
+		if queryVec, err = vs.embedQuery(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+	if len(queryVec.Values) == 0 {
 		return nil, nil
 	}
 
-	// Embed the query
-	queryVec := vs.vocab.Embed(query)
+	var hits []vectorHit
+	if vs.ann != nil && vs.ann.Ready() {
+		hits = vs.ann.Search(queryVec, limit)
+	} else {
+		hits = vs.linearSearch(queryVec, limit)
+	}
 
-	// Compute similarities
-	for i := range entries {
-		entries[i].result.Score = CosineSimilarity(queryVec, entries[i].vec)
+	// Keep only positive scores
+	n := 0
+	for _, h := range hits {
+		if h.Score > 0 {
+			hits[n] = h
+			n++
+		}
 	}
+	return vs.resolveHits(hits[:n])
+}
 
-	// Sort by score descending
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].result.Score > entries[j].result.Score
-	})
+// embedQuery embeds a search query.
+func (vs *VectorStore) embedQuery(ctx context.Context, query string) (Vector, error) {
+	vecs, err := vs.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return Vector{}, fmt.Errorf("embed query: %w", err)
+	}
+	return vecs[0], nil
+}
 
-	// Return top-K with positive scores
-	var results []VectorResult
-	for _, e := range entries {
-		if e.result.Score <= 0 {
-			break
+// linearSearch scores every in-memory vector against q and returns the top k.
+func (vs *VectorStore) linearSearch(q Vector, k int) []vectorHit {
+	hits := make([]vectorHit, 0, len(vs.vectors))
+	for id, vec := range vs.vectors {
+		hits = append(hits, vectorHit{ID: id, Score: q.Dot(vec)})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
 		}
-		results = append(results, e.result)
-		if len(results) >= limit {
-			break
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+// resolveHits loads observation details for hits, preserving their order.
+// Hits whose observation no longer exists are dropped.
+func (vs *VectorStore) resolveHits(hits []vectorHit) ([]VectorResult, error) {
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(hits))
+	args := make([]any, len(hits))
+	for i, h := range hits {
+		placeholders[i] = "?"
+		args[i] = h.ID
+	}
+	rows, err := vs.db.Conn().Query(`
+		SELECT id, title, text, type, project, session_id, created_at
+		FROM observations WHERE id IN (`+strings.Join(placeholders, ",")+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("load observations: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]VectorResult, len(hits))
+	for rows.Next() {
+		var r VectorResult
+		var createdAt string
+		if err := rows.Scan(&r.ID, &r.Title, &r.Text, &r.ObsType, &r.Project, &r.SessionID, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan observation: %w", err)
 		}
+		r.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
+		byID[r.ID] = r
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate observations: %w", err)
 	}
 
+	results := make([]VectorResult, 0, len(hits))
+	for _, h := range hits {
+		if r, ok := byID[h.ID]; ok {
+			r.Score = h.Score
+			results = append(results, r)
+		}
+	}
 	return results, nil
 }
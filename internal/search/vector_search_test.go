@@ -1,8 +1,10 @@
 package search
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/itk-dev/itkdev-claude-code/internal/db"
@@ -59,7 +61,7 @@ func TestVectorStoreAndSearch(t *testing.T) {
 	}
 
 	// Search for authentication-related observations
-	results, err := store.Search("authentication login session", 3)
+	results, err := store.Search(context.Background(), "authentication login session", 3)
 	if err != nil {
 		t.Fatalf("Search: %v", err)
 	}
@@ -96,7 +98,7 @@ func TestVectorStoreIndexSingle(t *testing.T) {
 		t.Fatalf("IndexObservation: %v", err)
 	}
 
-	results, err := store.Search("sample observation", 5)
+	results, err := store.Search(context.Background(), "sample observation", 5)
 	if err != nil {
 		t.Fatalf("Search: %v", err)
 	}
@@ -117,7 +119,7 @@ func TestVectorStoreEmptySearch(t *testing.T) {
 		t.Fatalf("NewVectorStore: %v", err)
 	}
 
-	results, err := store.Search("anything", 5)
+	results, err := store.Search(context.Background(), "anything", 5)
 	if err != nil {
 		t.Fatalf("Search: %v", err)
 	}
@@ -126,3 +128,192 @@ func TestVectorStoreEmptySearch(t *testing.T) {
 		t.Errorf("expected 0 results from empty store, got %d", len(results))
 	}
 }
+
+func TestVectorStorePersistsAcrossReopen(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	path := filepath.Join(t.TempDir(), "icc.db")
+
+	database, err := db.Open(path, logger)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	store, err := NewVectorStore(database)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	id, err := database.InsertObservation(&db.Observation{
+		SessionID: "s1",
+		Title:     "auth bug",
+		Text:      "Fixed authentication login flow",
+	})
+	if err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+	if err := store.IndexObservation(id); err != nil {
+		t.Fatalf("IndexObservation: %v", err)
+	}
+	database.Close()
+
+	database, err = db.Open(path, logger)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer database.Close()
+	store, err = NewVectorStore(database)
+	if err != nil {
+		t.Fatalf("NewVectorStore after reopen: %v", err)
+	}
+
+	results, err := store.Search(context.Background(), "authentication", 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != id {
+		t.Errorf("expected observation %d right after reopen, got %+v", id, results)
+	}
+}
+
+func TestVectorStoreIndexesMissingOnOpen(t *testing.T) {
+	database := testDB(t)
+
+	if _, err := NewVectorStore(database); err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	id, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "cache", Text: "redis cache eviction"})
+	if err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+
+	store, err := NewVectorStore(database)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	results, err := store.Search(context.Background(), "redis eviction", 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != id {
+		t.Errorf("expected unindexed observation to be indexed on open, got %+v", results)
+	}
+}
+
+func TestVectorStoreReindexReplacesContribution(t *testing.T) {
+	database := testDB(t)
+	store, err := NewVectorStore(database)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+
+	id, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "x", Text: "webhook retry"})
+	if err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+	for range 3 {
+		if err := store.IndexObservation(id); err != nil {
+			t.Fatalf("IndexObservation: %v", err)
+		}
+	}
+
+	if store.tfidf().vocab.Docs() != 1 {
+		t.Errorf("doc count = %d, want 1", store.tfidf().vocab.Docs())
+	}
+	if df := store.tfidf().vocab.df[store.tfidf().vocab.index["webhook"]]; df != 1 {
+		t.Errorf("df(webhook) = %d, want 1", df)
+	}
+}
+
+func TestVectorStoreRemoveObservation(t *testing.T) {
+	database := testDB(t)
+	store, err := NewVectorStore(database)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+
+	var ids []int64
+	for _, text := range []string{"webhook retry", "webhook timeout"} {
+		id, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "x", Text: text})
+		if err != nil {
+			t.Fatalf("InsertObservation: %v", err)
+		}
+		if err := store.IndexObservation(id); err != nil {
+			t.Fatalf("IndexObservation: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if _, err := database.DeleteObservation(ids[0]); err != nil {
+		t.Fatalf("DeleteObservation: %v", err)
+	}
+	if err := store.RemoveObservation(ids[0]); err != nil {
+		t.Fatalf("RemoveObservation: %v", err)
+	}
+
+	vocab := store.tfidf().vocab
+	if vocab.Docs() != 1 {
+		t.Errorf("doc count = %d, want 1", vocab.Docs())
+	}
+	if df := vocab.df[vocab.index["retry"]]; df != 0 {
+		t.Errorf("df(retry) = %d, want 0", df)
+	}
+	if df := vocab.df[vocab.index["webhook"]]; df != 1 {
+		t.Errorf("df(webhook) = %d, want 1", df)
+	}
+
+	results, err := store.Search(context.Background(), "webhook retry", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != ids[1] {
+		t.Errorf("expected only observation %d, got %+v", ids[1], results)
+	}
+
+	// The removal is persisted
+	reopened, err := NewVectorStore(database)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if reopened.tfidf().vocab.Docs() != 1 {
+		t.Errorf("doc count after reopen = %d, want 1", reopened.tfidf().vocab.Docs())
+	}
+}
+
+func TestVectorStoreReweight(t *testing.T) {
+	database := testDB(t)
+	store, err := NewVectorStore(database)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+
+	id, err := database.InsertObservation(&db.Observation{SessionID: "s1", Title: "x", Text: "deploy pipeline"})
+	if err != nil {
+		t.Fatalf("InsertObservation: %v", err)
+	}
+	if err := store.IndexObservation(id); err != nil {
+		t.Fatalf("IndexObservation: %v", err)
+	}
+
+	stale, err := store.Stale()
+	if err != nil || !stale {
+		t.Fatalf("Stale() = %v, %v; want true after incremental index", stale, err)
+	}
+	if err := store.Reweight(); err != nil {
+		t.Fatalf("Reweight: %v", err)
+	}
+	if stale, _ := store.Stale(); stale {
+		t.Error("index should not be stale after Reweight")
+	}
+
+	// Deleting outside the store leaves the vocabulary out of date
+	if _, err := database.Conn().Exec(`DELETE FROM observations WHERE id = ?`, id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if stale, _ := store.Stale(); !stale {
+		t.Error("index should be stale after an observation is deleted")
+	}
+	if err := store.Reweight(); err != nil {
+		t.Fatalf("Reweight: %v", err)
+	}
+	if store.tfidf().vocab.Docs() != 0 {
+		t.Errorf("doc count after reweight = %d, want 0", store.tfidf().vocab.Docs())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/itk-dev/itkdev-claude-code/internal/config"
 )
@@ -14,6 +15,9 @@ import (
 type ClearSignal struct {
 	PlanPath string `json:"plan_path"`
 	General  bool   `json:"general"`
+	// Ready is set once send-clear is done, so Claude Code can be stopped
+	// without cutting the session end hooks short.
+	Ready bool `json:"ready"`
 }
 
 const clearSignalFile = "clear-signal.json"
@@ -21,16 +25,46 @@ const clearSignalFile = "clear-signal.json"
 // WriteClearSignal writes a clear signal to the session directory.
 // If planPath is empty, it's a general (no-plan) continuation.
 func WriteClearSignal(sessionDir string, planPath string) error {
-	signal := ClearSignal{
+	return writeClearSignal(sessionDir, &ClearSignal{
 		PlanPath: planPath,
 		General:  planPath == "",
+	})
+}
+
+// MarkClearSignalReady marks the session's clear signal as ready, letting
+// icc run stop Claude Code.
+func MarkClearSignalReady(sessionDir string) error {
+	signal, err := ReadClearSignal(sessionDir)
+	if err != nil {
+		return err
 	}
+	signal.Ready = true
+	return writeClearSignal(sessionDir, signal)
+}
+
+// writeClearSignal writes the signal file through a temporary file, so the
+// watcher never reads a partial one.
+func writeClearSignal(sessionDir string, signal *ClearSignal) error {
 	data, err := json.Marshal(signal)
 	if err != nil {
 		return fmt.Errorf("marshal clear signal: %w", err)
 	}
-	path := filepath.Join(sessionDir, clearSignalFile)
-	return os.WriteFile(path, data, 0o644)
+	tmp, err := os.CreateTemp(sessionDir, ".clear-signal-*")
+	if err != nil {
+		return fmt.Errorf("write clear signal: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(sessionDir, clearSignalFile))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write clear signal: %w", err)
+	}
+	return nil
 }
 
 // ReadClearSignal reads the clear signal from the session directory.
@@ -52,6 +86,63 @@ func RemoveClearSignal(sessionDir string) {
 	os.Remove(filepath.Join(sessionDir, clearSignalFile))
 }
 
+// ConsumeClearSignal reads and removes the clear signal in one step.
+// Returns nil if no (valid) signal is present.
+func ConsumeClearSignal(sessionDir string) *ClearSignal {
+	signal, err := ReadClearSignal(sessionDir)
+	if err != nil {
+		return nil
+	}
+	RemoveClearSignal(sessionDir)
+	return signal
+}
+
+// WatchClearSignal polls the session directory for a clear signal and closes
+// the returned channel once it is ready. Polling stops when stop is closed.
+// The signal file is left in place for ConsumeClearSignal.
+func WatchClearSignal(sessionDir string, interval time.Duration, stop <-chan struct{}) <-chan struct{} {
+	found := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if signal, err := ReadClearSignal(sessionDir); err == nil && signal.Ready {
+					close(found)
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return found
+}
+
+const continuationFile = "continuation.md"
+
+// CarryOverContinuation copies continuation.md from one session directory to
+// another so the continuation prompt, which resolves the file through
+// $ICC_SESSION_ID, finds it in the new session. A missing source file is not
+// an error.
+func CarryOverContinuation(fromDir, toDir string) error {
+	data, err := os.ReadFile(filepath.Join(fromDir, continuationFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read continuation: %w", err)
+	}
+	if err := os.MkdirAll(toDir, 0o755); err != nil {
+		return fmt.Errorf("create session dir: %w", err)
+	}
+	return os.WriteFile(filepath.Join(toDir, continuationFile), data, 0o644)
+}
+
 // BuildContinuationPrompt generates the prompt sent after /clear to resume
 // the session. If planPath is set, the prompt references the plan.
 func BuildContinuationPrompt(planPath string) string {
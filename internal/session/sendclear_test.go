@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestWriteClearSignal(t *testing.T) {
@@ -76,6 +77,84 @@ func TestRemoveClearSignal(t *testing.T) {
 	}
 }
 
+func TestConsumeClearSignal(t *testing.T) {
+	dir := t.TempDir()
+
+	if got := ConsumeClearSignal(dir); got != nil {
+		t.Fatalf("expected nil without signal, got %+v", got)
+	}
+
+	WriteClearSignal(dir, "docs/plans/test.md")
+
+	signal := ConsumeClearSignal(dir)
+	if signal == nil {
+		t.Fatal("expected signal")
+	}
+	if signal.PlanPath != "docs/plans/test.md" {
+		t.Errorf("PlanPath = %q, want docs/plans/test.md", signal.PlanPath)
+	}
+	if _, err := ReadClearSignal(dir); err == nil {
+		t.Error("expected signal file to be removed after consume")
+	}
+}
+
+func TestWatchClearSignal(t *testing.T) {
+	dir := t.TempDir()
+	stop := make(chan struct{})
+	defer close(stop)
+
+	found := WatchClearSignal(dir, 10*time.Millisecond, stop)
+
+	select {
+	case <-found:
+		t.Fatal("watch fired before signal was written")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// send-clear is still waiting for the session end hooks
+	WriteClearSignal(dir, "")
+	select {
+	case <-found:
+		t.Fatal("watch fired before signal was ready")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := MarkClearSignalReady(dir); err != nil {
+		t.Fatalf("MarkClearSignalReady: %v", err)
+	}
+	select {
+	case <-found:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watch did not fire after signal was ready")
+	}
+	if signal, err := ReadClearSignal(dir); err != nil || !signal.General {
+		t.Errorf("ReadClearSignal after marking ready = %+v, %v", signal, err)
+	}
+}
+
+func TestCarryOverContinuation(t *testing.T) {
+	from := t.TempDir()
+	to := filepath.Join(t.TempDir(), "child")
+
+	// Missing source is not an error
+	if err := CarryOverContinuation(from, to); err != nil {
+		t.Fatalf("CarryOverContinuation without file: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(from, "continuation.md"), []byte("next: task 3"), 0o644)
+	if err := CarryOverContinuation(from, to); err != nil {
+		t.Fatalf("CarryOverContinuation: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(to, "continuation.md"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "next: task 3" {
+		t.Errorf("continuation = %q, want %q", data, "next: task 3")
+	}
+}
+
 func TestBuildContinuationPrompt(t *testing.T) {
 	prompt := BuildContinuationPrompt("docs/plans/test.md")
 	if prompt == "" {
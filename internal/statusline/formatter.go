@@ -23,6 +23,7 @@ type Input struct {
 	ContextPct float64 `json:"context_pct"`
 	Plan       *Plan   `json:"plan"`
 	Tasks      *Tasks  `json:"tasks"`
+	Cache      *Cache  `json:"checker_cache"`
 	Worktree   *Wt     `json:"worktree"`
 	Duration   int     `json:"duration_secs"`
 	Messages   int     `json:"messages"`
@@ -35,6 +36,12 @@ type Tasks struct {
 	Total     int `json:"total"`
 }
 
+// Cache represents the file-checker cache's hit and miss counts.
+type Cache struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
 // Plan represents plan metadata in the status input.
 type Plan struct {
 	Name   string `json:"name"`
@@ -51,7 +58,7 @@ type Wt struct {
 }
 
 // Format renders the status bar string with ANSI colors.
-// Layout: branch │ P:name done/total │ CTX ▰▰▱▱ pct%
+// Layout: branch │ P:name done/total │ T:done/total │ C:hit% │ CTX ▰▰▱▱ pct%
 // Empty parts are omitted. Color only for context >= 80%.
 func Format(input *Input) string {
 	var parts []string
@@ -68,6 +75,10 @@ func Format(input *Input) string {
 		parts = append(parts, formatTasks(input.Tasks))
 	}
 
+	if input.Cache != nil && input.Cache.Hits+input.Cache.Misses > 0 {
+		parts = append(parts, formatCache(input.Cache))
+	}
+
 	if ctx := formatContext(input.ContextPct); ctx != "" {
 		parts = append(parts, ctx)
 	}
@@ -110,6 +121,11 @@ func formatTasks(t *Tasks) string {
 	return fmt.Sprintf("T:%d/%d", t.Completed, t.Total)
 }
 
+// formatCache shows the share of file-checker runs answered from the cache.
+func formatCache(c *Cache) string {
+	return fmt.Sprintf("C:%.0f%%", 100*float64(c.Hits)/float64(c.Hits+c.Misses))
+}
+
 func formatPlan(p *Plan) string {
 	name := p.Name
 	if len(name) > 20 {
@@ -252,3 +252,25 @@ func TestParseAndFormatInvalidJSON(t *testing.T) {
 		t.Error("ParseAndFormat() expected error for invalid JSON")
 	}
 }
+
+func TestFormat_WithCache(t *testing.T) {
+	input := &Input{
+		Branch: "main",
+		Cache:  &Cache{Hits: 3, Misses: 1},
+	}
+	got := Format(input)
+	if !strings.Contains(got, "C:75%") {
+		t.Errorf("Format() should contain C:75%%, got %q", got)
+	}
+}
+
+func TestFormat_CacheNoLookups(t *testing.T) {
+	input := &Input{
+		Branch: "main",
+		Cache:  &Cache{},
+	}
+	got := Format(input)
+	if strings.Contains(got, "C:") {
+		t.Errorf("Format() should not show the cache without lookups, got %q", got)
+	}
+}
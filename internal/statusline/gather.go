@@ -26,6 +26,9 @@ func Gather(input *Input, workDir, sessionDir string) {
 	if input.Tasks == nil && sessionDir != "" {
 		input.Tasks = gatherTasks(sessionDir)
 	}
+	if input.Cache == nil && sessionDir != "" {
+		input.Cache = gatherCache(sessionDir)
+	}
 }
 
 // gatherBranch reads the current git branch from .git/HEAD.
@@ -80,6 +83,22 @@ func gatherTasks(sessionDir string) *Tasks {
 	return &t
 }
 
+// gatherCache reads the file-checker cache counts from the session directory.
+func gatherCache(sessionDir string) *Cache {
+	data, err := os.ReadFile(filepath.Join(sessionDir, "checker-cache", "stats.json"))
+	if err != nil {
+		return nil
+	}
+	var c Cache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil
+	}
+	if c.Hits+c.Misses == 0 {
+		return nil
+	}
+	return &c
+}
+
 var (
 	statusRe = regexp.MustCompile(`(?m)^Status:\s*(\S+)`)
 	taskDone = regexp.MustCompile(`- \[x\]`)
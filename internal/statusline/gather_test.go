@@ -179,3 +179,29 @@ Progress: Done 1 / Left 0 / Total 1
 		t.Errorf("gatherPlan() should skip VERIFIED plans, got %+v", got)
 	}
 }
+
+func TestGatherCache(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "checker-cache"), 0o755)
+	os.WriteFile(
+		filepath.Join(dir, "checker-cache", "stats.json"),
+		[]byte(`{"hits":3,"misses":1}`),
+		0o644,
+	)
+
+	got := gatherCache(dir)
+	if got == nil {
+		t.Fatal("gatherCache() should return counts")
+	}
+	if got.Hits != 3 || got.Misses != 1 {
+		t.Errorf("gatherCache() = %+v, want 3 hits and 1 miss", got)
+	}
+}
+
+func TestGatherCache_NoFile(t *testing.T) {
+	dir := t.TempDir()
+	got := gatherCache(dir)
+	if got != nil {
+		t.Errorf("gatherCache() should return nil when no file, got %+v", got)
+	}
+}